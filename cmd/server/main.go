@@ -2,16 +2,32 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"os"
+	"time"
+	_ "time/tzdata" // strefy IANA także w obrazach bez /usr/share/zoneinfo
 
 	"gopkg.in/yaml.v2"
 
+	"github.com/bartbaranski/eventhub/frontend"
 	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/blobstore"
+	"github.com/bartbaranski/eventhub/internal/cors"
 	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/bartbaranski/eventhub/internal/idempotency"
+	"github.com/bartbaranski/eventhub/internal/jobs"
+	"github.com/bartbaranski/eventhub/internal/mail"
+	"github.com/bartbaranski/eventhub/internal/ratelimit"
+	"github.com/bartbaranski/eventhub/internal/server"
 	"github.com/bartbaranski/eventhub/internal/storage"
+	"github.com/bartbaranski/eventhub/internal/web"
 	"github.com/gorilla/mux"
 )
 
@@ -20,6 +36,26 @@ type Config struct {
 	ServerAddress string `yaml:"serverAddress"`
 	DatabaseURL   string `yaml:"databaseURL"`
 	JWTSecret     string `yaml:"jwtSecret"`
+	// RateLimits configures token-bucket limits per route group
+	RateLimits ratelimit.Config `yaml:"rateLimits"`
+	// CORS holds the cross-origin policy for browser clients
+	CORS cors.Config `yaml:"cors"`
+	// Frontend optionally serves the built React app from this server
+	Frontend web.Config `yaml:"frontend"`
+	// TLS enables HTTPS (with HTTP/2) directly in this server
+	TLS server.TLSConfig `yaml:"tls"`
+	// EventRetention is how long soft-deleted events can be restored before purge
+	EventRetention time.Duration `yaml:"eventRetention"`
+	// PurgeInterval is how often expired soft-deleted events are removed
+	PurgeInterval time.Duration `yaml:"purgeInterval"`
+	// Storage is where uploaded files (event images) are kept
+	Storage blobstore.Config `yaml:"storage"`
+	// SiteURL is the public address of the frontend, used for links in feeds
+	SiteURL string `yaml:"siteURL"`
+	// Idempotency configures how long Idempotency-Key responses are replayed
+	Idempotency idempotency.Config `yaml:"idempotency"`
+	// Mail configures outgoing email (verification links)
+	Mail mail.Config `yaml:"mail"`
 }
 
 // loadConfig reads YAML config from the provided path
@@ -28,31 +64,46 @@ func loadConfig(path string) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
-	var cfg Config
+	cfg := Config{
+		EventRetention: 30 * 24 * time.Hour,
+		PurgeInterval:  time.Hour,
+		Idempotency:    idempotency.Config{TTL: 24 * time.Hour},
+	}
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
 	return &cfg, nil
 }
 
-// corsMiddleware dodaje nagłówki CORS i od razu odpowiada na preflight (OPTIONS)
-func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Dopuszczamy żądania z frontendu (localhost:3000)
-		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:3000")
-		// Dopuszczamy nagłówki, które może wysyłać frontend
-		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
-		// Dopuszczamy metody HTTP, których frontend będzie używać
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-
-		// Jeśli to żądanie preflight (OPTIONS), od razu zwracamy 204 No Content
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusNoContent)
-			return
+// routeExists reports whether the router has a route for the request's
+// path and method; used to answer CORS preflight only for real routes
+func routeExists(router *mux.Router) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		var match mux.RouteMatch
+		return router.Match(r, &match) && match.MatchErr == nil
+	}
+}
+
+// frontendFS picks the filesystem the React build is served from;
+// nil means the frontend is deployed separately
+func frontendFS(cfg web.Config) (fs.FS, error) {
+	switch cfg.Mode {
+	case "":
+		return nil, nil
+	case "embed":
+		fsys, ok := frontend.Build()
+		if !ok {
+			return nil, errors.New("binary was built without -tags embedfrontend")
+		}
+		return fsys, nil
+	case "dir":
+		if _, err := os.Stat(cfg.Dir + "/index.html"); err != nil {
+			return nil, err
 		}
-		// W przeciwnym wypadku – przekazujemy dalej do właściwego handlera
-		next.ServeHTTP(w, r)
-	})
+		return os.DirFS(cfg.Dir), nil
+	default:
+		return nil, fmt.Errorf("unknown frontend mode %q", cfg.Mode)
+	}
 }
 
 func main() {
@@ -75,6 +126,53 @@ func main() {
 	// use underlying *sql.DB
 	db := pg.DB
 
+	// magazyn przesyłanych plików (lokalny katalog albo S3)
+	store, err := blobstore.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to configure storage: %v", err)
+	}
+
+	// trwałe usuwanie wydarzeń (i ich obrazów) po okresie retencji
+	go jobs.RunPurge(context.Background(), db, store, cfg.EventRetention, cfg.PurgeInterval)
+
+	// wysyłka poczty (SMTP); bez sterownika linki weryfikacyjne są niedostępne
+	mailer, err := mail.New(cfg.Mail)
+	if err != nil {
+		log.Fatalf("Failed to configure mail: %v", err)
+	}
+
+	// rate limiting per grupa tras (auth / odczyty / zapisy)
+	ips, err := ratelimit.NewIPResolver(cfg.RateLimits.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Invalid rate limit config: %v", err)
+	}
+	limits := ratelimit.NewMemoryStore()
+	authLimit := ratelimit.New("auth", cfg.RateLimits.Auth, limits, ips).Middleware
+	reads := ratelimit.New("reads", cfg.RateLimits.Reads, limits, ips).Middleware
+	writeLimit := ratelimit.New("writes", cfg.RateLimits.Writes, limits, ips).Middleware
+
+	// ponowienia POST z tym samym Idempotency-Key dostają zapisaną odpowiedź
+	keys := idempotency.New(db, cfg.Idempotency, ips)
+	go keys.Run(context.Background(), time.Hour)
+
+	// zapisy: limit per IP przed uwierzytelnieniem (dławi też żądania bez
+	// tokenu lub z błędnym tokenem), potem per użytkownik i Idempotency-Key
+	writeIPLimit := ratelimit.New("writes-ip", cfg.RateLimits.WritesPerIP, limits, ips).Middleware
+	authWrites := func(next http.HandlerFunc) http.HandlerFunc {
+		return writeIPLimit(auth.JWTMiddleware(writeLimit(keys.Middleware(next))))
+	}
+	// zapisy, których odpowiedź zawiera sekret (token kalendarza, link
+	// przekazania) – bez Idempotency-Key, żeby sekret nie trafił do bazy
+	secretWrites := func(next http.HandlerFunc) http.HandlerFunc {
+		return writeIPLimit(auth.JWTMiddleware(writeLimit(next)))
+	}
+	// kasowanie biletów ma własny, szerszy limit – czytniki przy wejściu
+	// dzielą konto organizatora i zwykle adres IP sali
+	checkinLimit := ratelimit.New("checkin", cfg.RateLimits.Checkin, limits, ips).Middleware
+	checkinWrites := func(next http.HandlerFunc) http.HandlerFunc {
+		return checkinLimit(auth.JWTMiddleware(keys.Middleware(next)))
+	}
+
 	// Tworzymy router główny
 	r := mux.NewRouter()
 
@@ -82,23 +180,126 @@ func main() {
 	api := r.PathPrefix("/api/v1").Subrouter()
 
 	// Authentication endpoints
-	api.HandleFunc("/auth/register", handlers.Register(db)).Methods("POST")
-	api.HandleFunc("/auth/login", handlers.Login(db)).Methods("POST")
+	api.HandleFunc("/auth/register", authLimit(keys.Middleware(handlers.Register(db)))).Methods("POST")
+	// logowanie bez Idempotency-Key – odpowiedź z tokenem JWT nie może trafić do bazy
+	api.HandleFunc("/auth/login", authLimit(handlers.Login(db))).Methods("POST")
+	api.HandleFunc("/auth/verify-email", auth.JWTMiddleware(authLimit(keys.Middleware(handlers.RequestEmailVerification(db, mailer, cfg.SiteURL))))).Methods("POST")
+	api.HandleFunc("/auth/verify-email/{token:[0-9a-f]+}", authLimit(keys.Middleware(handlers.VerifyEmail(db)))).Methods("POST")
 
 	// Events endpoints
-	api.HandleFunc("/events", handlers.ListEvents(db)).Methods("GET")
-	api.HandleFunc("/events", auth.JWTMiddleware(handlers.CreateEvent(db))).Methods("POST")
-	api.HandleFunc("/events/{id}", handlers.GetEvent(db)).Methods("GET")
-	api.HandleFunc("/events/{id}", auth.JWTMiddleware(handlers.UpdateEvent(db))).Methods("PUT")
-	api.HandleFunc("/events/{id}", auth.JWTMiddleware(handlers.DeleteEvent(db))).Methods("DELETE")
+	api.HandleFunc("/events", auth.OptionalJWTMiddleware(reads(handlers.ListEvents(db)))).Methods("GET")
+	api.HandleFunc("/events", authWrites(handlers.CreateEvent(db))).Methods("POST")
+	api.HandleFunc("/events/import", authWrites(handlers.ImportEvents(db))).Methods("POST")
+	// .ics przed /events/{id} – inaczej {id} dopasowałoby "5.ics"
+	api.HandleFunc("/events/{id:[0-9]+}.ics", auth.OptionalJWTMiddleware(reads(handlers.EventICS(db)))).Methods("GET")
+	api.HandleFunc("/events/{id}", auth.OptionalJWTMiddleware(reads(handlers.GetEvent(db)))).Methods("GET")
+	api.HandleFunc("/events/{id}", authWrites(handlers.UpdateEvent(db))).Methods("PUT")
+	api.HandleFunc("/events/{id}", authWrites(handlers.PatchEvent(db))).Methods("PATCH")
+	api.HandleFunc("/events/{id}", authWrites(handlers.DeleteEvent(db))).Methods("DELETE")
+	api.HandleFunc("/events/{id}/image", authWrites(handlers.UploadEventImage(db, store))).Methods("POST")
+	api.HandleFunc("/events/{id}/attendees", auth.JWTMiddleware(reads(handlers.ListAttendees(db)))).Methods("GET")
+	api.HandleFunc("/events/{id}/attendees/export", auth.JWTMiddleware(reads(handlers.ExportAttendees(db)))).Methods("GET")
+	api.HandleFunc("/events/{id}/checkin", checkinWrites(handlers.CheckIn(db))).Methods("POST")
+	api.HandleFunc("/events/{id}/checkin", auth.JWTMiddleware(reads(handlers.CheckinStats(db)))).Methods("GET")
+	api.HandleFunc("/events/{id}/checkin/undo", checkinWrites(handlers.UndoCheckIn(db))).Methods("POST")
+	api.HandleFunc("/events/{id}/checkin/snapshot", auth.JWTMiddleware(reads(handlers.TicketSnapshot(db)))).Methods("GET")
+	api.HandleFunc("/events/{id}/checkin/sync", checkinWrites(handlers.SyncCheckins(db))).Methods("POST")
+	api.HandleFunc("/events/{id}/checkin/duplicates", auth.JWTMiddleware(reads(handlers.CheckinDuplicates(db)))).Methods("GET")
+	api.HandleFunc("/events/{id}/transfers", authWrites(handlers.SetEventTransfers(db))).Methods("PUT")
+	api.HandleFunc("/events/{id}/limits", authWrites(handlers.SetEventLimits(db))).Methods("PUT")
+	api.HandleFunc("/events/{id}/history", auth.JWTMiddleware(reads(handlers.EventHistory(db)))).Methods("GET")
+	api.HandleFunc("/events/{id}/history/{rev}/rollback", authWrites(handlers.RollbackEvent(db))).Methods("POST")
+	api.HandleFunc("/events/{id}/restore", authWrites(handlers.RestoreEvent(db, cfg.EventRetention))).Methods("POST")
+	api.HandleFunc("/events/{id}/publish", authWrites(handlers.PublishEvent(db))).Methods("POST")
+	api.HandleFunc("/events/{id}/cancel", authWrites(handlers.CancelEvent(db))).Methods("POST")
+	api.HandleFunc("/events/{id}/postpone", authWrites(handlers.PostponeEvent(db))).Methods("POST")
+	api.HandleFunc("/events/{id}/complete", authWrites(handlers.CompleteEvent(db))).Methods("POST")
+
+	// Conference agenda (sessions) endpoints
+	api.HandleFunc("/events/{id}/sessions", auth.OptionalJWTMiddleware(reads(handlers.ListSessions(db)))).Methods("GET")
+	api.HandleFunc("/events/{id}/sessions", authWrites(handlers.CreateSession(db))).Methods("POST")
+	api.HandleFunc("/events/{id}/sessions/{sid}", authWrites(handlers.UpdateSession(db))).Methods("PUT")
+	api.HandleFunc("/events/{id}/sessions/{sid}", authWrites(handlers.DeleteSession(db))).Methods("DELETE")
+	api.HandleFunc("/sessions/{id}/signup", authWrites(handlers.SignUpSession(db))).Methods("POST")
+	api.HandleFunc("/sessions/{id}/signup", authWrites(handlers.CancelSessionSignup(db))).Methods("DELETE")
+	api.HandleFunc("/agenda", auth.JWTMiddleware(reads(handlers.MyAgenda(db)))).Methods("GET")
+
+	// Ticket tier endpoints
+	api.HandleFunc("/events/{id}/tiers", auth.OptionalJWTMiddleware(reads(handlers.ListTiers(db)))).Methods("GET")
+	api.HandleFunc("/events/{id}/tiers", authWrites(handlers.CreateTier(db))).Methods("POST")
+	api.HandleFunc("/events/{id}/tiers/{tid}", authWrites(handlers.DeleteTier(db))).Methods("DELETE")
+
+	// Public feeds of upcoming events
+	api.HandleFunc("/feeds/events.atom", reads(handlers.AtomFeed(db, cfg.SiteURL))).Methods("GET")
+	api.HandleFunc("/feeds/events.rss", reads(handlers.RSSFeed(db, cfg.SiteURL))).Methods("GET")
+	api.HandleFunc("/feeds/events.json", reads(handlers.JSONFeed(db, cfg.SiteURL))).Methods("GET")
+
+	// Calendar subscription endpoints
+	api.HandleFunc("/calendar/token", auth.JWTMiddleware(reads(handlers.GetCalendarToken(db)))).Methods("GET")
+	api.HandleFunc("/calendar/token", secretWrites(handlers.RotateCalendarToken(db))).Methods("POST")
+	api.HandleFunc("/calendar/{token:[0-9a-f]+}.ics", reads(handlers.CalendarFeed(db))).Methods("GET")
+
+	// Recurring event series endpoints
+	api.HandleFunc("/series", authWrites(handlers.CreateSeries(db))).Methods("POST")
+	api.HandleFunc("/series/{id}", auth.OptionalJWTMiddleware(reads(handlers.GetSeries(db)))).Methods("GET")
+	api.HandleFunc("/series/{id}", authWrites(handlers.UpdateSeries(db))).Methods("PUT")
+
+	// Venues endpoints
+	api.HandleFunc("/venues", reads(handlers.ListVenues(db))).Methods("GET")
+	api.HandleFunc("/venues", authWrites(handlers.CreateVenue(db))).Methods("POST")
+	api.HandleFunc("/venues/{id}", reads(handlers.GetVenue(db))).Methods("GET")
+	api.HandleFunc("/venues/{id}", authWrites(handlers.UpdateVenue(db))).Methods("PUT")
 
 	// Reservations endpoints
-	api.HandleFunc("/reservations", auth.JWTMiddleware(handlers.ListReservations(db))).Methods("GET")
-	api.HandleFunc("/reservations", auth.JWTMiddleware(handlers.CreateReservation(db))).Methods("POST")
+	api.HandleFunc("/reservations", auth.JWTMiddleware(reads(handlers.ListReservations(db)))).Methods("GET")
+	api.HandleFunc("/reservations", authWrites(handlers.CreateReservation(db))).Methods("POST")
+	api.HandleFunc("/checkin/key", reads(handlers.CheckinKey())).Methods("GET")
+	api.HandleFunc("/reservations/{id}/ticket", auth.JWTMiddleware(reads(handlers.ReservationTicket(db)))).Methods("GET")
+	api.HandleFunc("/reservations/{id}/transfer", secretWrites(handlers.CreateTransfer(db))).Methods("POST")
+	api.HandleFunc("/reservations/{id}/transfer", authWrites(handlers.CancelTransfer(db))).Methods("DELETE")
+	api.HandleFunc("/transfers", auth.JWTMiddleware(reads(handlers.IncomingTransfers(db)))).Methods("GET")
+	api.HandleFunc("/transfers/{token:[0-9a-f]+}", reads(handlers.GetTransfer(db))).Methods("GET")
+	api.HandleFunc("/transfers/{token:[0-9a-f]+}/accept", authWrites(handlers.AcceptTransfer(db))).Methods("POST")
+	api.HandleFunc("/transfers/incoming/{id:[0-9]+}/accept", authWrites(handlers.AcceptIncomingTransfer(db))).Methods("POST")
 
-	// Owijamy cały router w middleware CORS
-	handlerWithCORS := corsMiddleware(r)
+	// Pliki magazynu lokalnego serwujemy sami (S3 ma własne adresy)
+	if local, ok := store.(*blobstore.Local); ok {
+		r.PathPrefix(local.Prefix()).Handler(local.Handler())
+	}
 
-	log.Printf("Starting server on %s", cfg.ServerAddress)
-	log.Fatal(http.ListenAndServe(cfg.ServerAddress, handlerWithCORS))
+	// Frontend (opcjonalnie) – rejestrowany po /api/v1, więc API ma pierwszeństwo
+	spa, err := frontendFS(cfg.Frontend)
+	if err != nil {
+		log.Fatalf("Failed to load frontend: %v", err)
+	}
+	if spa != nil {
+		r.PathPrefix("/").Handler(web.SPA(spa))
+	}
+
+	// Owijamy cały router w politykę CORS z konfiguracji
+	handlerWithCORS := cors.New(cfg.CORS, routeExists(r)).Handler(r)
+
+	srv := &http.Server{
+		Addr:    cfg.ServerAddress,
+		Handler: handlerWithCORS,
+	}
+	if !cfg.TLS.Enabled {
+		log.Printf("Starting server on %s", cfg.ServerAddress)
+		log.Fatal(srv.ListenAndServe())
+	}
+
+	// TLS: certyfikat przeładowywany po zmianie plików, h2 negocjowane przez ALPN
+	srv.TLSConfig, err = server.NewTLSConfig(cfg.TLS)
+	if err != nil {
+		log.Fatalf("Failed to configure TLS: %v", err)
+	}
+	srv.Handler = server.HSTS(cfg.TLS, handlerWithCORS)
+	if cfg.TLS.RedirectAddress != "" {
+		go func() {
+			log.Printf("Redirecting HTTP on %s to HTTPS", cfg.TLS.RedirectAddress)
+			log.Fatal(http.ListenAndServe(cfg.TLS.RedirectAddress, server.RedirectHandler(cfg.ServerAddress)))
+		}()
+	}
+	log.Printf("Starting TLS server on %s", cfg.ServerAddress)
+	log.Fatal(srv.ListenAndServeTLS("", ""))
 }
@@ -0,0 +1,22 @@
+//go:build embedfrontend
+
+// Package frontend udostępnia zbudowaną aplikację React (npm run build)
+// wbudowaną w binarkę serwera.
+package frontend
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:build
+var build embed.FS
+
+// Build zwraca zawartość katalogu build/.
+func Build() (fs.FS, bool) {
+	sub, err := fs.Sub(build, "build")
+	if err != nil {
+		return nil, false
+	}
+	return sub, true
+}
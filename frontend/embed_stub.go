@@ -0,0 +1,12 @@
+//go:build !embedfrontend
+
+// Package frontend udostępnia zbudowaną aplikację React (npm run build)
+// wbudowaną w binarkę serwera.
+package frontend
+
+import "io/fs"
+
+// Build zwraca false, bo binarka została zbudowana bez -tags embedfrontend.
+func Build() (fs.FS, bool) {
+	return nil, false
+}
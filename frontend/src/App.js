@@ -8,9 +8,13 @@ import HomePage from './pages/HomePage';
 import EventsListPage from './pages/EventsListPage';
 import EventDetailPage from './pages/EventDetailPage';
 import EventFormPage from './pages/EventFormPage';
+import ImportEventsPage from './pages/ImportEventsPage';
+import CheckInPage from './pages/CheckInPage';
 import ReservationsPage from './pages/ReservationsPage';
 import LoginPage from './pages/LoginPage';
 import RegisterPage from './pages/RegisterPage';
+import TransferPage from './pages/TransferPage';
+import VerifyEmailPage from './pages/VerifyEmailPage';
 
 // AppWrapper: pobiera fontSize i isHighContrast z kontekstów
 function AppWrapper() {
@@ -27,10 +31,14 @@ function AppWrapper() {
 
         <Route path="/events" element={<EventsListPage />} />
         <Route path="/events/new" element={<EventFormPage />} />
+        <Route path="/events/import" element={<ImportEventsPage />} />
         <Route path="/events/:id" element={<EventDetailPage />} />
         <Route path="/events/:id/edit" element={<EventFormPage />} />
+        <Route path="/events/:id/checkin" element={<CheckInPage />} />
 
         <Route path="/reservations" element={<ReservationsPage />} />
+        <Route path="/transfers/:token" element={<TransferPage />} />
+        <Route path="/verify-email/:token" element={<VerifyEmailPage />} />
         <Route path="/login" element={<LoginPage />} />
         <Route path="/register" element={<RegisterPage />} />
 
@@ -0,0 +1,105 @@
+// src/components/AttendeesCard.js
+import React, { useState, useEffect } from 'react';
+import { Card, Table, Form, Button, Pagination, Alert } from 'react-bootstrap';
+import http from '../api/httpClient';
+
+const PER_PAGE = 25;
+
+// Lista uczestników wydarzenia dla organizatora – z wyszukiwaniem i eksportem
+export default function AttendeesCard({ eventId }) {
+  const [query, setQuery] = useState('');
+  const [page, setPage] = useState(1);
+  const [data, setData] = useState({ attendees: [], total: 0, tickets: 0 });
+  const [error, setError] = useState('');
+
+  useEffect(() => {
+    (async () => {
+      try {
+        const res = await http.get(`/events/${eventId}/attendees`, {
+          params: { q: query || undefined, page, per_page: PER_PAGE },
+        });
+        setData(res.data);
+        setError('');
+      } catch (err) {
+        console.error(err);
+        setError('Unable to load attendees');
+      }
+    })();
+  }, [eventId, query, page]);
+
+  // Plik pobieramy przez axios (link nie przeniósłby nagłówka Authorization)
+  const handleExport = async (format) => {
+    try {
+      const res = await http.get(`/events/${eventId}/attendees/export`, {
+        params: { format, q: query || undefined },
+        responseType: 'blob',
+      });
+      const url = URL.createObjectURL(res.data);
+      const a = document.createElement('a');
+      a.href = url;
+      a.download = `event-${eventId}-attendees.${format}`;
+      a.click();
+      URL.revokeObjectURL(url);
+    } catch (err) {
+      console.error(err);
+      setError('Unable to export attendees');
+    }
+  };
+
+  const pages = Math.max(1, Math.ceil(data.total / PER_PAGE));
+
+  return (
+    <Card className="mt-3">
+      <Card.Body>
+        <Card.Title className="d-flex justify-content-between align-items-center">
+          <span>
+            Attendees ({data.total} reservations, {data.tickets} tickets)
+          </span>
+          <span>
+            <Button size="sm" variant="outline-secondary" className="me-2" onClick={() => handleExport('csv')}>
+              Export CSV
+            </Button>
+            <Button size="sm" variant="outline-secondary" onClick={() => handleExport('json')}>
+              Export JSON
+            </Button>
+          </span>
+        </Card.Title>
+        {error && <Alert variant="danger">{error}</Alert>}
+        <Form.Control
+          className="mb-2"
+          placeholder="Search by e-mail"
+          value={query}
+          onChange={(e) => {
+            setQuery(e.target.value);
+            setPage(1);
+          }}
+        />
+        <Table size="sm" striped>
+          <thead>
+            <tr>
+              <th>E-mail</th>
+              <th>Tickets</th>
+              <th>Reserved</th>
+            </tr>
+          </thead>
+          <tbody>
+            {data.attendees.map((a) => (
+              <tr key={a.reservation_id}>
+                <td>{a.email}</td>
+                <td>{a.tickets}</td>
+                <td>{new Date(a.reserved_at).toLocaleString()}</td>
+              </tr>
+            ))}
+          </tbody>
+        </Table>
+        {pages > 1 && (
+          <Pagination size="sm">
+            <Pagination.Prev disabled={page === 1} onClick={() => setPage(page - 1)} />
+            <Pagination.Item active>{page} / {pages}</Pagination.Item>
+            <Pagination.Next disabled={page === pages} onClick={() => setPage(page + 1)} />
+          </Pagination>
+        )}
+      </Card.Body>
+    </Card>
+  );
+}
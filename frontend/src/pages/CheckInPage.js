@@ -0,0 +1,162 @@
+// src/pages/CheckInPage.js
+import React, { useState, useEffect, useContext, useRef, useCallback } from 'react';
+import { Container, Row, Col, Card, Form, Button, Alert, ProgressBar, Table } from 'react-bootstrap';
+import { useParams } from 'react-router-dom';
+import { useTranslation } from 'react-i18next';
+import NavBar from '../components/NavBar';
+import http from '../api/httpClient';
+import { AuthContext } from '../contexts/AuthContext';
+
+// Co ile odświeżamy licznik (inne czytniki przy wejściu kasują bilety równolegle)
+const STATS_INTERVAL = 5000;
+
+// Kasowanie biletów przy wejściu – czytnik kodów QR działa jak klawiatura
+// (wpisuje kod i Enter), więc wystarczy pole tekstowe z fokusem.
+export default function CheckInPage() {
+  const { t } = useTranslation();
+  const { id } = useParams();
+  const { user } = useContext(AuthContext);
+  const inputRef = useRef(null);
+
+  const [code, setCode] = useState('');
+  const [result, setResult] = useState(null); // { variant, message, code }
+  const [stats, setStats] = useState(null);
+  const [duplicates, setDuplicates] = useState([]); // próby ponownego wejścia (także z czytników offline)
+
+  const loadStats = useCallback(async () => {
+    try {
+      const [res, dup] = await Promise.all([
+        http.get(`/events/${id}/checkin`),
+        http.get(`/events/${id}/checkin/duplicates`),
+      ]);
+      setStats(res.data);
+      setDuplicates(dup.data);
+    } catch (err) {
+      console.error('Błąd licznika wejść:', err);
+    }
+  }, [id]);
+
+  useEffect(() => {
+    if (!user || user.role !== 'organizer') return undefined;
+    loadStats();
+    const timer = setInterval(loadStats, STATS_INTERVAL);
+    return () => clearInterval(timer);
+  }, [user, loadStats]);
+
+  if (!user || user.role !== 'organizer') {
+    return (
+      <>
+        <NavBar />
+        <Container className="mt-4">
+          <Alert variant="danger">{t('checkin.forbidden')}</Alert>
+        </Container>
+      </>
+    );
+  }
+
+  const describe = (c) => t('checkin.attendee', { email: c.email, seat: c.seat, count: c.tickets });
+
+  const handleScan = async (e) => {
+    e.preventDefault();
+    const scanned = code.trim();
+    setCode('');
+    if (!scanned) return;
+    try {
+      const res = await http.post(`/events/${id}/checkin`, { code: scanned });
+      setResult({ variant: 'success', message: `${t('checkin.ok')} ${describe(res.data)}`, code: scanned });
+    } catch (err) {
+      const data = err.response?.data;
+      if (err.response?.status === 409 && data?.checkin) {
+        const at = new Date(data.checkin.checked_in_at).toLocaleTimeString();
+        setResult({ variant: 'warning', message: `${t('checkin.already', { at })} ${describe(data.checkin)}` });
+      } else if (err.response?.status === 400 || err.response?.status === 404) {
+        setResult({ variant: 'danger', message: t('checkin.invalid') });
+      } else {
+        setResult({ variant: 'danger', message: t('checkin.error') });
+      }
+    }
+    loadStats();
+    inputRef.current?.focus();
+  };
+
+  // Cofnięcie ostatniego skasowania (pomyłka przy wejściu)
+  const handleUndo = async () => {
+    try {
+      await http.post(`/events/${id}/checkin/undo`, { code: result.code });
+      setResult({ variant: 'secondary', message: t('checkin.undone') });
+    } catch (err) {
+      console.error('Błąd cofania:', err.response?.data || err.message);
+      setResult({ variant: 'danger', message: t('checkin.error') });
+    }
+    loadStats();
+    inputRef.current?.focus();
+  };
+
+  return (
+    <>
+      <NavBar />
+      <Container className="mt-4">
+        <Row>
+          <Col md={6} className="mx-auto">
+            <Card>
+              <Card.Body>
+                <Card.Title>{t('checkin.title')}</Card.Title>
+                {stats && (
+                  <div className="mb-3">
+                    <div>{t('checkin.counter', { checkedIn: stats.checked_in, tickets: stats.tickets })}</div>
+                    <ProgressBar now={stats.tickets ? (100 * stats.checked_in) / stats.tickets : 0} />
+                  </div>
+                )}
+                <Form onSubmit={handleScan}>
+                  <Form.Control
+                    ref={inputRef}
+                    autoFocus
+                    value={code}
+                    placeholder={t('checkin.placeholder')}
+                    onChange={(e) => setCode(e.target.value)}
+                  />
+                </Form>
+                {result && (
+                  <Alert variant={result.variant} className="mt-3">
+                    {result.message}
+                    {result.code && (
+                      <Button size="sm" variant="outline-dark" className="ms-2" onClick={handleUndo}>
+                        {t('checkin.undo')}
+                      </Button>
+                    )}
+                  </Alert>
+                )}
+              </Card.Body>
+            </Card>
+
+            {duplicates.length > 0 && (
+              <Card className="mt-3">
+                <Card.Body>
+                  <Card.Title>{t('checkin.duplicates')}</Card.Title>
+                  <Table size="sm" striped>
+                    <thead>
+                      <tr>
+                        <th>{t('checkin.ticket')}</th>
+                        <th>{t('checkin.attempt')}</th>
+                        <th>{t('checkin.firstEntry')}</th>
+                      </tr>
+                    </thead>
+                    <tbody>
+                      {duplicates.map((d, i) => (
+                        <tr key={i}>
+                          <td>{d.email} #{d.seat}</td>
+                          <td>{new Date(d.scanned_at).toLocaleTimeString()} {d.device_id}</td>
+                          <td>{new Date(d.first_checked_in_at).toLocaleTimeString()} {d.first_device_id}</td>
+                        </tr>
+                      ))}
+                    </tbody>
+                  </Table>
+                </Card.Body>
+              </Card>
+            )}
+          </Col>
+        </Row>
+      </Container>
+    </>
+  );
+}
@@ -3,6 +3,7 @@ import React, { useState, useEffect, useContext } from 'react';
 import { useParams, useNavigate } from 'react-router-dom';
 import http from '../api/httpClient';
 import NavBar from '../components/NavBar';
+import AttendeesCard from '../components/AttendeesCard';
 import {
   Container,
   Row,
@@ -13,6 +14,8 @@ import {
   Alert,
   Modal,
   Form,
+  ListGroup,
+  Badge,
 } from 'react-bootstrap';
 import { AuthContext } from '../contexts/AuthContext';
 import { format } from 'date-fns';
@@ -31,6 +34,37 @@ export default function EventDetailPage() {
   const [tickets, setTickets] = useState(1);
   const [reserveError, setReserveError] = useState('');
   const [reserveSuccess, setReserveSuccess] = useState(false);
+  const [needsVerification, setNeedsVerification] = useState(false);
+  // Klucz idempotencji – ponowne „Confirm” po błędzie sieci nie utworzy drugiej rezerwacji
+  const [reserveKey, setReserveKey] = useState('');
+  const [verificationSent, setVerificationSent] = useState(false);
+
+  // Reguły rezerwacji ustawiane przez organizatora
+  const [limitInput, setLimitInput] = useState('');
+  const [limitsSaved, setLimitsSaved] = useState(false);
+
+  // Agenda (sesje) – np. wykłady na konferencji
+  const [sessions, setSessions] = useState([]);
+  const [sessionError, setSessionError] = useState('');
+
+  // Pule biletów – gdy są, rezerwacja musi wskazać jedną z nich
+  const [tiers, setTiers] = useState([]);
+  const [tierId, setTierId] = useState('');
+
+  // Organizator może wyłączyć przekazywanie rezerwacji innym osobom
+  const toggleTransfers = async () => {
+    try {
+      const response = await http.put(`/events/${id}/transfers`, { enabled: !event.transfers_enabled });
+      setEvent({ ...event, transfers_enabled: response.data.transfers_enabled });
+    } catch (err) {
+      console.error('Błąd zmiany przekazań:', err.response?.data || err.message);
+    }
+  };
+
+  const loadSessions = async () => {
+    const response = await http.get(`/events/${id}/sessions`);
+    setSessions(response.data);
+  };
 
   // Pobranie szczegółów eventu – useEffect z wewnętrzną async funkcją
   useEffect(() => {
@@ -38,6 +72,11 @@ export default function EventDetailPage() {
       try {
         const response = await http.get(`/events/${id}`);
         setEvent(response.data);
+        setLimitInput(response.data.max_tickets_per_user ?? '');
+        const agenda = await http.get(`/events/${id}/sessions`);
+        setSessions(agenda.data);
+        const tierList = await http.get(`/events/${id}/tiers`);
+        setTiers(tierList.data);
       } catch (err) {
         console.error(err);
         setError('Unable to load event details');
@@ -51,11 +90,13 @@ export default function EventDetailPage() {
   const handleDelete = async () => {
     if (window.confirm('Are you sure you want to delete this event?')) {
       try {
-        await http.delete(`/events/${id}`);
+        await http.delete(`/events/${id}`, { headers: { 'If-Match': `"${event.version}"` } });
         navigate('/events');
       } catch (err) {
         console.error(err);
-        alert('Error deleting event');
+        alert(err.response?.status === 412
+          ? 'This event has been changed in the meantime – reload the page and try again'
+          : 'Error deleting event');
       }
     }
   };
@@ -63,6 +104,8 @@ export default function EventDetailPage() {
   // Obsługa otwarcia modalu rezerwacji
   const openReserveModal = () => {
     setTickets(1);
+    setTierId(tiers.find((tier) => tier.tickets_left > 0)?.id ?? '');
+    setReserveKey(crypto.randomUUID());
     setReserveError('');
     setReserveSuccess(false);
     setShowReserveModal(true);
@@ -74,7 +117,11 @@ export default function EventDetailPage() {
     e.preventDefault();
     setReserveError('');
     try {
-      await http.post('/reservations', { event_id: parseInt(id, 10), tickets });
+      await http.post(
+        '/reservations',
+        { event_id: parseInt(id, 10), tickets, ...(tierId !== '' && { tier_id: tierId }) },
+        { headers: { 'Idempotency-Key': reserveKey } }
+      );
       setReserveSuccess(true);
       setTimeout(() => {
         setShowReserveModal(false);
@@ -82,7 +129,74 @@ export default function EventDetailPage() {
       }, 1500);
     } catch (err) {
       console.error(err);
-      setReserveError('Unable to make reservation');
+      const data = err.response?.data;
+      if (data?.code === 'email_not_verified') {
+        setNeedsVerification(true);
+        setReserveError('This event requires a verified email address.');
+      } else if (data?.code === 'ticket_limit') {
+        setReserveError(
+          `You can book at most ${data.limit} tickets ${data.tier_id ? 'in this tier' : 'for this event'} (you already have ${data.booked}).`
+        );
+      } else if (data?.tickets_left !== undefined) {
+        setReserveError(`Only ${data.tickets_left} tickets left${data.tier_id ? ' in this tier' : ''}.`);
+      } else {
+        setReserveError('Unable to make reservation');
+      }
+    }
+  };
+
+  // Link potwierdzający adres e-mail (wymagany przez niektóre wydarzenia)
+  const requestVerification = async () => {
+    try {
+      await http.post('/auth/verify-email');
+      setVerificationSent(true);
+    } catch (err) {
+      console.error('Błąd wysyłki linku:', err.response?.data || err.message);
+    }
+  };
+
+  const saveLimits = async (e) => {
+    e.preventDefault();
+    setLimitsSaved(false);
+    try {
+      const max = limitInput === '' ? null : parseInt(limitInput, 10);
+      const response = await http.put(
+        `/events/${id}/limits`,
+        { max_tickets_per_user: max, require_verified_email: event.require_verified_email },
+        { headers: { 'If-Match': `"${event.version}"` } }
+      );
+      setEvent({
+        ...event,
+        version: parseInt((response.headers.etag || '').replace(/"/g, ''), 10) || event.version,
+        max_tickets_per_user: response.data.max_tickets_per_user ?? undefined,
+        require_verified_email: response.data.require_verified_email,
+      });
+      setLimitsSaved(true);
+    } catch (err) {
+      console.error('Błąd zapisu limitów:', err.response?.data || err.message);
+    }
+  };
+
+  // Zapis na sesję / wypisanie (wymaga rezerwacji na wydarzenie)
+  const toggleSession = async (session) => {
+    setSessionError('');
+    try {
+      if (session.joined) {
+        await http.delete(`/sessions/${session.id}/signup`);
+      } else {
+        await http.post(`/sessions/${session.id}/signup`);
+      }
+      await loadSessions();
+    } catch (err) {
+      console.error(err);
+      const data = err.response?.data;
+      if (data?.clashes) {
+        setSessionError(`Clashes with: ${data.clashes.map((c) => c.title).join(', ')}`);
+      } else if (err.response?.status === 403) {
+        setSessionError('Reserve tickets for the event first');
+      } else {
+        setSessionError(typeof data === 'string' ? data : 'Unable to update session sign-up');
+      }
     }
   };
 
@@ -129,10 +243,20 @@ export default function EventDetailPage() {
                 <Card.Title>{event.title}</Card.Title>
                 <Card.Subtitle className="mb-2 text-muted">
                   {format(new Date(event.date), 'PPP p')}
+                  {event.timezone !== Intl.DateTimeFormat().resolvedOptions().timeZone && (
+                    // czas na miejscu wydarzenia, gdy jesteśmy w innej strefie
+                    <> ({event.local_date.slice(11, 16)} {event.timezone})</>
+                  )}
                 </Card.Subtitle>
                 <Card.Text>{event.description}</Card.Text>
                 <Card.Text>
                   <strong>Capacity:</strong> {event.capacity}
+                  {event.max_tickets_per_user && (
+                    <>
+                      {' '}
+                      (max {event.max_tickets_per_user} per account)
+                    </>
+                  )}
                 </Card.Text>
                 <Card.Text>
                   <strong>Organizer ID:</strong> {event.organizer_id}
@@ -148,12 +272,57 @@ export default function EventDetailPage() {
                     >
                       Edit
                     </Button>
-                    <Button variant="danger" onClick={handleDelete}>
+                    <Button variant="danger" className="me-2" onClick={handleDelete}>
                       Delete
                     </Button>
+                    <Button
+                      variant="success"
+                      className="me-2"
+                      onClick={() => navigate(`/events/${id}/checkin`)}
+                    >
+                      Check-in
+                    </Button>
+                    <Form.Check
+                      type="switch"
+                      id="transfers-enabled"
+                      className="d-inline-block me-2"
+                      label="Allow ticket transfers"
+                      checked={event.transfers_enabled}
+                      onChange={toggleTransfers}
+                    />
+                    <Form onSubmit={saveLimits} className="d-flex align-items-center gap-2 my-2">
+                      <Form.Control
+                        type="number"
+                        min={1}
+                        size="sm"
+                        style={{ maxWidth: 160 }}
+                        placeholder="Tickets per account"
+                        value={limitInput}
+                        onChange={(e) => setLimitInput(e.target.value)}
+                      />
+                      <Form.Check
+                        id="require-verified-email"
+                        label="Require verified email"
+                        checked={event.require_verified_email}
+                        onChange={(e) => setEvent({ ...event, require_verified_email: e.target.checked })}
+                      />
+                      <Button type="submit" size="sm" variant="outline-primary">
+                        Save limits
+                      </Button>
+                      {limitsSaved && <Badge bg="success">Saved</Badge>}
+                    </Form>
                   </>
                 )}
 
+                {/* Plik .ics do dodania wydarzenia do kalendarza */}
+                <Button
+                  variant="outline-secondary"
+                  className="me-2"
+                  href={`${http.defaults.baseURL}/events/${id}.ics`}
+                >
+                  Add to calendar
+                </Button>
+
                 {/* Przycisk Reserve dla uczestnika */}
                 {user.role === 'participant' && (
                   <Button variant="primary" onClick={openReserveModal}>
@@ -162,6 +331,49 @@ export default function EventDetailPage() {
                 )}
               </Card.Body>
             </Card>
+
+            {sessions.length > 0 && (
+              <Card className="mt-3">
+                <Card.Body>
+                  <Card.Title>Agenda</Card.Title>
+                  {sessionError && <Alert variant="danger">{sessionError}</Alert>}
+                  <ListGroup variant="flush">
+                    {sessions.map((s) => (
+                      <ListGroup.Item key={s.id} className="d-flex justify-content-between align-items-start">
+                        <div>
+                          <div className="fw-bold">
+                            {s.local_starts_at.slice(11, 16)}–{s.local_ends_at.slice(11, 16)} {s.title}
+                          </div>
+                          <small className="text-muted">
+                            {[s.speaker, s.room].filter(Boolean).join(' · ')}
+                          </small>
+                          {s.seats_left !== undefined && (
+                            <Badge bg={s.seats_left > 0 ? 'secondary' : 'danger'} className="ms-2">
+                              {s.seats_left} seats left
+                            </Badge>
+                          )}
+                        </div>
+                        {user.role === 'participant' && (
+                          <Button
+                            size="sm"
+                            variant={s.joined ? 'outline-danger' : 'outline-primary'}
+                            disabled={!s.joined && s.seats_left === 0}
+                            onClick={() => toggleSession(s)}
+                          >
+                            {s.joined ? 'Leave' : 'Join'}
+                          </Button>
+                        )}
+                      </ListGroup.Item>
+                    ))}
+                  </ListGroup>
+                </Card.Body>
+              </Card>
+            )}
+
+            {/* Lista uczestników dla organizatora */}
+            {user.role === 'organizer' && user.id === event.organizer_id && (
+              <AttendeesCard eventId={id} />
+            )}
           </Col>
         </Row>
       </Container>
@@ -173,16 +385,54 @@ export default function EventDetailPage() {
         </Modal.Header>
         <Modal.Body>
           {reserveSuccess && <Alert variant="success">Reservation successful!</Alert>}
-          {reserveError && <Alert variant="danger">{reserveError}</Alert>}
+          {reserveError && (
+            <Alert variant="danger">
+              {reserveError}
+              {needsVerification && (
+                <div className="mt-2">
+                  {verificationSent ? (
+                    'Verification link sent – check your inbox.'
+                  ) : (
+                    <Button size="sm" variant="outline-dark" onClick={requestVerification}>
+                      Send verification link
+                    </Button>
+                  )}
+                </div>
+              )}
+            </Alert>
+          )}
           <Form onSubmit={handleReserve}>
+            {tiers.length > 0 && (
+              <Form.Group className="mb-3" controlId="formTier">
+                <Form.Label>Ticket Tier</Form.Label>
+                <Form.Select
+                  value={tierId}
+                  onChange={(e) => {
+                    setTierId(parseInt(e.target.value, 10));
+                    setReserveKey(crypto.randomUUID()); // inne żądanie – nowy klucz
+                  }}
+                  required
+                >
+                  {tiers.map((tier) => (
+                    <option key={tier.id} value={tier.id} disabled={tier.tickets_left === 0}>
+                      {tier.name} ({tier.tickets_left} left
+                      {tier.max_tickets_per_user ? `, max ${tier.max_tickets_per_user} per account` : ''})
+                    </option>
+                  ))}
+                </Form.Select>
+              </Form.Group>
+            )}
             <Form.Group className="mb-3" controlId="formTickets">
               <Form.Label>Number of Tickets</Form.Label>
               <Form.Control
                 type="number"
                 min={1}
-                max={event.capacity}
+                max={event.max_tickets_per_user || event.capacity}
                 value={tickets}
-                onChange={(e) => setTickets(parseInt(e.target.value, 10))}
+                onChange={(e) => {
+                  setTickets(parseInt(e.target.value, 10));
+                  setReserveKey(crypto.randomUUID()); // inne żądanie – nowy klucz
+                }}
                 required
               />
             </Form.Group>
@@ -7,6 +7,11 @@ import http from '../api/httpClient';
 import { AuthContext } from '../contexts/AuthContext';
 import { useTranslation } from 'react-i18next';
 
+// kategorie zgodne z models.EventCategories w backendzie
+export const EVENT_CATEGORIES = [
+  'concert', 'workshop', 'conference', 'meetup', 'sport', 'theatre', 'exhibition', 'festival', 'other',
+];
+
 export default function EventFormPage() {
   const { t } = useTranslation();
   const { user } = useContext(AuthContext);
@@ -19,7 +24,14 @@ export default function EventFormPage() {
   const [time, setTime] = useState(''); // "HH:MM"
   const [capacity, setCapacity] = useState(0);
   const [imageURL, setImageURL] = useState(''); // pole na ścieżkę do plakatu
+  const [category, setCategory] = useState('');
+  const [tags, setTags] = useState(''); // etykiety rozdzielone przecinkami
+  const [venueId, setVenueId] = useState(null); // miejsce – PUT nadpisuje wszystkie pola, więc je odsyłamy
+  const [etag, setEtag] = useState(''); // wersja wczytanego eventu (If-Match przy zapisie)
+  // strefa wydarzenia – domyślnie strefa przeglądarki organizatora
+  const [timezone, setTimezone] = useState(Intl.DateTimeFormat().resolvedOptions().timeZone || 'UTC');
   const [loading, setLoading] = useState(false);
+  const [uploading, setUploading] = useState(false);
   const [error, setError] = useState('');
 
   useEffect(() => {
@@ -33,23 +45,18 @@ export default function EventFormPage() {
           setTitle(data.title);
           setDescription(data.description);
 
-          // data z backendu to np. "2025-06-18T15:30:00Z"
-          // rozbijamy na datę i godzinę:
-          const dt = new Date(data.date);
-          // format YYYY-MM-DD
-          setDate(
-            dt.getFullYear().toString().padStart(4, '0') + '-' +
-            (dt.getMonth() + 1).toString().padStart(2, '0') + '-' +
-            dt.getDate().toString().padStart(2, '0')
-          );
-          // format HH:MM
-          setTime(
-            dt.getHours().toString().padStart(2, '0') + ':' +
-            dt.getMinutes().toString().padStart(2, '0')
-          );
+          // local_date z backendu to czas w strefie wydarzenia, np. "2025-06-18T18:30:00+02:00";
+          // rozbijamy go na datę (YYYY-MM-DD) i godzinę (HH:MM)
+          setDate(data.local_date.slice(0, 10));
+          setTime(data.local_date.slice(11, 16));
+          setTimezone(data.timezone);
 
           setCapacity(data.capacity);
           setImageURL(data.image_url || '');
+          setCategory(data.category || '');
+          setTags((data.tags || []).join(', '));
+          setVenueId(data.venue_id || null);
+          setEtag(res.headers.etag || `"${data.version}"`);
         } catch (err) {
           console.error(err);
           setError(t('eventsList.errorLoading'));
@@ -58,35 +65,65 @@ export default function EventFormPage() {
     }
   }, [id, t]);
 
+  // Przesłanie plakatu (tylko edycja) – backend zapisuje warianty i od razu ustawia image_url
+  const handleUpload = async (e) => {
+    const file = e.target.files[0];
+    if (!file) return;
+    setUploading(true);
+    setError('');
+    const form = new FormData();
+    form.append('image', file);
+    try {
+      const res = await http.post(`/events/${id}/image`, form, { headers: { 'If-Match': etag } });
+      setImageURL(res.data.image_url);
+      setEtag(res.headers.etag || etag);
+    } catch (err) {
+      console.error('Błąd przesyłania:', err.response?.data || err.message);
+      const status = err.response?.status;
+      if (status === 412) setError(t('eventsList.errorConflict'));
+      else if (status === 413) setError(t('eventsList.errorPosterTooLarge'));
+      else if (status === 415) setError(t('eventsList.errorPosterType'));
+      else setError(t('eventsList.errorUploading'));
+    } finally {
+      setUploading(false);
+      e.target.value = '';
+    }
+  };
+
   const handleSubmit = async (e) => {
     e.preventDefault();
     setLoading(true);
     setError('');
 
-    // Połącz date + time w jeden ISO-owy ciąg: "YYYY-MM-DDTHH:MM"
+    // Połącz date + time w jeden ciąg "YYYY-MM-DDTHH:MM" – czas lokalny w strefie timezone
     const dateTimePayload = `${date}T${time}`;
 
     const payload = {
       title,
       description,
       date_time: dateTimePayload,
+      timezone,
       capacity,
       image_url: imageURL,
+      category,
+      tags: tags.split(',').map((tag) => tag.trim()).filter(Boolean),
+      ...(venueId ? { venue_id: venueId } : {}),
     };
     console.log('LEC ILE WYŚLĘ:', payload);
 
     try {
       if (id) {
         // edycja
-        await http.put(`/events/${id}`, payload);
+        await http.put(`/events/${id}`, payload, { headers: { 'If-Match': etag } });
       } else {
-        // tworzenie
-        await http.post('/events', payload);
+        // tworzenie – od razu publikujemy (domyślnie backend tworzy szkic)
+        await http.post('/events', { ...payload, status: 'published' });
       }
       navigate('/events');
     } catch (err) {
       console.error('Błąd zapisu:', err.response?.data || err.message);
-      setError(t('eventsList.errorSaving'));
+      // 412 – ktoś inny zmienił event w międzyczasie
+      setError(err.response?.status === 412 ? t('eventsList.errorConflict') : t('eventsList.errorSaving'));
     } finally {
       setLoading(false);
     }
@@ -157,6 +194,31 @@ export default function EventFormPage() {
                     />
                   </Form.Group>
 
+                  <Form.Group className="mb-3" controlId="category">
+                    <Form.Label>{t('eventsList.category')}</Form.Label>
+                    <Form.Select value={category} onChange={(e) => setCategory(e.target.value)}>
+                      <option value="">{t('eventsList.noCategory')}</option>
+                      {EVENT_CATEGORIES.map((c) => (
+                        <option key={c} value={c}>
+                          {t(`categories.${c}`)}
+                        </option>
+                      ))}
+                    </Form.Select>
+                  </Form.Group>
+
+                  <Form.Group className="mb-3" controlId="tags">
+                    <Form.Label>{t('eventsList.tags')}</Form.Label>
+                    <Form.Control
+                      type="text"
+                      value={tags}
+                      onChange={(e) => setTags(e.target.value)}
+                      placeholder="jazz, na żywo"
+                    />
+                    <Form.Text className="text-muted">
+                      {t('eventsList.tagsHint')}
+                    </Form.Text>
+                  </Form.Group>
+
                   {/* Pole na URL do plakatu */}
                   <Form.Group className="mb-3" controlId="imageURL">
                     <Form.Label>{t('eventsList.posterURL')}</Form.Label>
@@ -171,6 +233,22 @@ export default function EventFormPage() {
                     </Form.Text>
                   </Form.Group>
 
+                  {/* Przesłanie pliku plakatu – wymaga istniejącego wydarzenia */}
+                  {id && (
+                    <Form.Group className="mb-3" controlId="posterFile">
+                      <Form.Label>{t('eventsList.posterUpload')}</Form.Label>
+                      <Form.Control
+                        type="file"
+                        accept="image/jpeg,image/png,image/gif"
+                        onChange={handleUpload}
+                        disabled={uploading}
+                      />
+                      <Form.Text className="text-muted">
+                        {uploading ? t('eventsList.posterUploading') : t('eventsList.posterUploadHint')}
+                      </Form.Text>
+                    </Form.Group>
+                  )}
+
                   <Button
                     variant="primary"
                     type="submit"
@@ -1,6 +1,6 @@
 // src/pages/EventsListPage.js
 import React, { useEffect, useState, useContext } from 'react';
-import { Container, Row, Col, Card, Button, Spinner, Alert } from 'react-bootstrap';
+import { Container, Row, Col, Card, Button, Spinner, Alert, Badge } from 'react-bootstrap';
 import { useNavigate } from 'react-router-dom';
 import http from '../api/httpClient';
 import NavBar from '../components/NavBar';
@@ -20,12 +20,20 @@ export default function EventsListPage() {
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState('');
   const [eventDates, setEventDates] = useState([]);
+  // filtry: wybrana kategoria i etykiety oraz liczności (fasety) z backendu
+  const [category, setCategory] = useState('');
+  const [selectedTags, setSelectedTags] = useState([]);
+  const [facets, setFacets] = useState({ categories: [], tags: [] });
 
   useEffect(() => {
     async function loadEvents() {
       try {
-        const response = await http.get('/events');
-        const data = response.data;
+        const params = new URLSearchParams({ facets: '1' });
+        if (category) params.append('category', category);
+        selectedTags.forEach((tag) => params.append('tag', tag));
+        const response = await http.get(`/events?${params.toString()}`);
+        const data = response.data.events;
+        setFacets(response.data.facets || { categories: [], tags: [] });
         if (!Array.isArray(data)) {
           console.error('Expected an array from /events, got:', data);
           setEvents([]);
@@ -61,7 +69,11 @@ export default function EventsListPage() {
     }
 
     loadEvents();
-  }, [t]);
+  }, [t, category, selectedTags]);
+
+  const toggleTag = (tag) => {
+    setSelectedTags((prev) => (prev.includes(tag) ? prev.filter((x) => x !== tag) : [...prev, tag]));
+  };
 
   const tileClassName = ({ date, view }) => {
     if (view === 'month') {
@@ -100,6 +112,9 @@ export default function EventsListPage() {
         {/* Przycisk tworzenia eventu (tylko dla organizatora) */}
         {user && user.role === 'organizer' && (
           <div className="mb-3 text-end">
+            <Button variant="outline-success" className="me-2" onClick={() => navigate('/events/import')}>
+              {t('eventsList.importEvents')}
+            </Button>
             <Button variant="success" onClick={() => navigate('/events/new')}>
               {t('eventsList.createEvent')}
             </Button>
@@ -116,6 +131,49 @@ export default function EventsListPage() {
         {/* Tytuł strony wydarzeń */}
         <h2>{t('eventsList.title')}</h2>
 
+        {/* Filtry: kategorie i etykiety z licznością dla bieżącego filtra */}
+        {(facets.categories.length > 0 || facets.tags.length > 0 || category || selectedTags.length > 0) && (
+          <div className="event-filters mb-3" aria-label={t('eventsList.filters')}>
+            {facets.categories.map((f) => (
+              <Button
+                key={`c-${f.value}`}
+                size="sm"
+                className="me-2 mb-2"
+                variant={category === f.value ? 'primary' : 'outline-primary'}
+                aria-pressed={category === f.value}
+                onClick={() => setCategory(category === f.value ? '' : f.value)}
+              >
+                {t(`categories.${f.value}`)} ({f.count})
+              </Button>
+            ))}
+            {facets.tags.map((f) => (
+              <Button
+                key={`t-${f.value}`}
+                size="sm"
+                className="me-2 mb-2"
+                variant={selectedTags.includes(f.value) ? 'secondary' : 'outline-secondary'}
+                aria-pressed={selectedTags.includes(f.value)}
+                onClick={() => toggleTag(f.value)}
+              >
+                #{f.value} ({f.count})
+              </Button>
+            ))}
+            {(category || selectedTags.length > 0) && (
+              <Button
+                size="sm"
+                className="mb-2"
+                variant="link"
+                onClick={() => {
+                  setCategory('');
+                  setSelectedTags([]);
+                }}
+              >
+                {t('eventsList.clearFilters')}
+              </Button>
+            )}
+          </div>
+        )}
+
         {events.length === 0 ? (
           <Alert variant="info">{t('eventsList.noEvents')}</Alert>
         ) : (
@@ -145,7 +203,7 @@ export default function EventsListPage() {
                     {e.image_url && (
                       <Card.Img
                         variant="top"
-                        src={e.image_url}
+                        src={e.images?.card.url || e.image_url}
                         alt={title}
                         style={{ maxHeight: '200px', objectFit: 'cover' }}
                       />
@@ -165,6 +223,20 @@ export default function EventsListPage() {
                       <Card.Text>
                         {t('eventsList.capacity')}: {capacityText}
                       </Card.Text>
+                      {(e.category || (e.tags && e.tags.length > 0)) && (
+                        <Card.Text>
+                          {e.category && (
+                            <Badge bg="primary" className="me-1">
+                              {t(`categories.${e.category}`)}
+                            </Badge>
+                          )}
+                          {(e.tags || []).map((tag) => (
+                            <Badge key={tag} bg="secondary" className="me-1">
+                              #{tag}
+                            </Badge>
+                          ))}
+                        </Card.Text>
+                      )}
                       <Button variant="primary" onClick={() => navigate(`/events/${e.id}`)}>
                         {t('buttons.viewDetails')}
                       </Button>
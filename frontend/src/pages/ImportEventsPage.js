@@ -0,0 +1,166 @@
+// src/pages/ImportEventsPage.js
+import React, { useState, useContext } from 'react';
+import { Container, Row, Col, Card, Form, Button, Spinner, Alert, Table } from 'react-bootstrap';
+import { useNavigate } from 'react-router-dom';
+import { useTranslation } from 'react-i18next';
+import NavBar from '../components/NavBar';
+import http from '../api/httpClient';
+import { AuthContext } from '../contexts/AuthContext';
+
+// Import wydarzeń z arkusza (CSV/TSV) – najpierw sprawdzenie (dry run), potem zapis
+export default function ImportEventsPage() {
+  const { t } = useTranslation();
+  const { user } = useContext(AuthContext);
+  const navigate = useNavigate();
+
+  const [file, setFile] = useState(null);
+  const [mapping, setMapping] = useState(''); // opcjonalny JSON {"pole": "kolumna"}
+  const [result, setResult] = useState(null); // wynik sprawdzenia: { rows, valid, errors }
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState('');
+
+  if (!user || user.role !== 'organizer') {
+    return (
+      <>
+        <NavBar />
+        <Container className="mt-4">
+          <Alert variant="danger">{t('importEvents.forbidden')}</Alert>
+        </Container>
+      </>
+    );
+  }
+
+  const send = async (dryRun) => {
+    const form = new FormData();
+    form.append('file', file);
+    form.append('timezone', Intl.DateTimeFormat().resolvedOptions().timeZone || 'UTC');
+    if (mapping.trim()) form.append('mapping', mapping);
+    return http.post(`/events/import${dryRun ? '?dry_run=true' : ''}`, form);
+  };
+
+  const handleError = (err) => {
+    console.error('Błąd importu:', err.response?.data || err.message);
+    const data = err.response?.data;
+    if (data && Array.isArray(data.errors) && data.errors.length && data.errors[0].row) {
+      setResult({ rows: data.rows, valid: 0, errors: data.errors });
+    } else if (data && Array.isArray(data.errors)) {
+      setError(data.errors.map((e) => `${e.field}: ${e.message}`).join('; '));
+    } else if (err.response?.status === 413) {
+      setError(t('importEvents.errorTooLarge'));
+    } else {
+      setError(t('importEvents.errorImport'));
+    }
+  };
+
+  const handleCheck = async () => {
+    setLoading(true);
+    setError('');
+    setResult(null);
+    try {
+      const res = await send(true);
+      setResult(res.data);
+    } catch (err) {
+      handleError(err);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleImport = async () => {
+    setLoading(true);
+    setError('');
+    try {
+      await send(false);
+      navigate('/events');
+    } catch (err) {
+      handleError(err);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <>
+      <NavBar />
+      <Container className="mt-4">
+        <Row>
+          <Col md={8} className="mx-auto">
+            <Card>
+              <Card.Body>
+                <Card.Title>{t('importEvents.title')}</Card.Title>
+                {error && <Alert variant="danger">{error}</Alert>}
+
+                <Form.Group className="mb-3" controlId="importFile">
+                  <Form.Label>{t('importEvents.file')}</Form.Label>
+                  <Form.Control
+                    type="file"
+                    accept=".csv,.tsv,.tab,text/csv,text/tab-separated-values"
+                    onChange={(e) => {
+                      setFile(e.target.files[0] || null);
+                      setResult(null);
+                    }}
+                  />
+                  <Form.Text muted>{t('importEvents.fileHint')}</Form.Text>
+                </Form.Group>
+
+                <Form.Group className="mb-3" controlId="importMapping">
+                  <Form.Label>{t('importEvents.mapping')}</Form.Label>
+                  <Form.Control
+                    as="textarea"
+                    rows={3}
+                    value={mapping}
+                    placeholder='{"title": "Name", "date_time": "Start", "capacity": "Seats"}'
+                    onChange={(e) => {
+                      setMapping(e.target.value);
+                      setResult(null);
+                    }}
+                  />
+                  <Form.Text muted>{t('importEvents.mappingHint')}</Form.Text>
+                </Form.Group>
+
+                <Button variant="secondary" className="me-2" disabled={!file || loading} onClick={handleCheck}>
+                  {t('importEvents.check')}
+                </Button>
+                <Button
+                  variant="success"
+                  disabled={!file || loading || !result || result.errors.length > 0}
+                  onClick={handleImport}
+                >
+                  {loading ? <Spinner animation="border" size="sm" /> : t('importEvents.import')}
+                </Button>
+
+                {result && (
+                  <div className="mt-3">
+                    <Alert variant={result.errors.length ? 'warning' : 'success'}>
+                      {t('importEvents.summary', { valid: result.valid, rows: result.rows })}
+                    </Alert>
+                    {result.errors.length > 0 && (
+                      <Table size="sm" striped bordered>
+                        <thead>
+                          <tr>
+                            <th>{t('importEvents.row')}</th>
+                            <th>{t('importEvents.field')}</th>
+                            <th>{t('importEvents.message')}</th>
+                          </tr>
+                        </thead>
+                        <tbody>
+                          {result.errors.map((e, i) => (
+                            <tr key={i}>
+                              <td>{e.row}</td>
+                              <td>{e.field}</td>
+                              <td>{e.message}</td>
+                            </tr>
+                          ))}
+                        </tbody>
+                      </Table>
+                    )}
+                  </div>
+                )}
+              </Card.Body>
+            </Card>
+          </Col>
+        </Row>
+      </Container>
+    </>
+  );
+}
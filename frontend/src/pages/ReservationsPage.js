@@ -1,6 +1,6 @@
 // src/pages/ReservationsPage.js
 import React, { useEffect, useState, useContext } from 'react';
-import { Container, Spinner, Alert, Table } from 'react-bootstrap';
+import { Container, Spinner, Alert, Table, Button, Modal, Form } from 'react-bootstrap';
 import { useTranslation } from 'react-i18next';
 import http from '../api/httpClient';
 import NavBar from '../components/NavBar';
@@ -14,6 +14,23 @@ export default function ReservationsPage() {
   const [eventsMap, setEventsMap] = useState({}); // { [eventId]: { title, date } }
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState('');
+  const [tickets, setTickets] = useState(null); // { reservation, images: [adresy obrazów QR] }
+  const [ticketsError, setTicketsError] = useState('');
+  const [incoming, setIncoming] = useState([]); // przekazania rezerwacji na adres użytkownika
+  const [transfer, setTransfer] = useState(null); // { reservation, email, error }
+
+  const loadReservations = async () => {
+    // Przekazania widzi tylko właściciel potwierdzonego adresu (inaczej 403)
+    const [resRes, resIncoming] = await Promise.all([
+      http.get('/reservations'),
+      http.get('/transfers').catch((err) => {
+        if (err.response?.status === 403) return { data: [] };
+        throw err;
+      }),
+    ]);
+    setReservations(Array.isArray(resRes.data) ? resRes.data : []);
+    setIncoming(resIncoming.data);
+  };
 
   useEffect(() => {
     // Jeśli użytkownik nie jest jeszcze wczytany, czekamy
@@ -27,15 +44,8 @@ export default function ReservationsPage() {
 
     async function fetchData() {
       try {
-        // 1) Pobierz wszystkie rezerwacje użytkownika
-        const resRes = await http.get('/reservations');
-        const dataRes = resRes.data;
-        if (!Array.isArray(dataRes)) {
-          console.error('Expected an array from /reservations, got:', dataRes);
-          setReservations([]);
-        } else {
-          setReservations(dataRes);
-        }
+        // 1) Pobierz wszystkie rezerwacje użytkownika i przekazania do przyjęcia
+        await loadReservations();
 
         // 2) Pobierz wszystkie eventy (żeby mieć dostęp do tytułów)
         const resEvents = await http.get('/events');
@@ -62,6 +72,64 @@ export default function ReservationsPage() {
     fetchData();
   }, [user, authLoading, t]);
 
+  // Kody QR pobieramy przez axios (obrazek <img> nie wyśle nagłówka Authorization)
+  const showTickets = async (reservation) => {
+    setTicketsError('');
+    setTickets({ reservation, images: [] });
+    try {
+      const images = [];
+      for (let seat = 1; seat <= reservation.tickets; seat++) {
+        const res = await http.get(`/reservations/${reservation.id}/ticket`, {
+          params: { format: 'svg', seat },
+          responseType: 'blob',
+        });
+        images.push(URL.createObjectURL(res.data));
+      }
+      setTickets({ reservation, images });
+    } catch (err) {
+      console.error('Error fetching tickets:', err);
+      setTicketsError(t('reservations.errorTickets'));
+    }
+  };
+
+  // Przekazanie rezerwacji – adresat dostaje link /transfers/:token
+  const submitTransfer = async (e) => {
+    e.preventDefault();
+    try {
+      await http.post(`/reservations/${transfer.reservation.id}/transfer`, { email: transfer.email });
+      setTransfer(null);
+      await loadReservations();
+    } catch (err) {
+      const data = err.response?.data;
+      setTransfer({ ...transfer, error: data?.errors?.[0]?.message || (typeof data === 'string' && data) || t('reservations.errorTransfer') });
+    }
+  };
+
+  const cancelTransfer = async (reservation) => {
+    try {
+      await http.delete(`/reservations/${reservation.id}/transfer`);
+      await loadReservations();
+    } catch (err) {
+      console.error('Error cancelling transfer:', err);
+      setError(t('reservations.errorTransfer'));
+    }
+  };
+
+  const acceptTransfer = async (tr) => {
+    try {
+      await http.post(`/transfers/incoming/${tr.id}/accept`);
+      await loadReservations();
+    } catch (err) {
+      console.error('Error accepting transfer:', err);
+      setError(t('reservations.errorTransfer'));
+    }
+  };
+
+  const closeTickets = () => {
+    tickets?.images.forEach((url) => URL.revokeObjectURL(url));
+    setTickets(null);
+  };
+
   if (authLoading || loading) {
     return (
       <>
@@ -101,6 +169,22 @@ export default function ReservationsPage() {
       <Container className="mt-4">
         <h2>{t('reservations.title')}</h2>
 
+        {incoming.length > 0 && (
+          <Alert variant="info">
+            <strong>{t('reservations.incomingTitle')}</strong>
+            {incoming.map((tr) => (
+              <div key={tr.id} className="d-flex align-items-center mt-2">
+                <span className="me-auto">
+                  {t('reservations.incoming', { from: tr.from_email, title: tr.event_title, count: tr.tickets })}
+                </span>
+                <Button size="sm" variant="success" onClick={() => acceptTransfer(tr)}>
+                  {t('reservations.accept')}
+                </Button>
+              </div>
+            ))}
+          </Alert>
+        )}
+
         {reservations.length === 0 ? (
           <Alert variant="info">{t('reservations.noReservations')}</Alert>
         ) : (
@@ -110,6 +194,7 @@ export default function ReservationsPage() {
                 <th>{t('reservations.eventTitle')}</th>
                 <th>{t('reservations.date')}</th>
                 <th>{t('reservations.tickets')}</th>
+                <th></th>
               </tr>
             </thead>
             <tbody>
@@ -124,6 +209,30 @@ export default function ReservationsPage() {
                     <td>{title}</td>
                     <td>{dateText}</td>
                     <td>{r.tickets}</td>
+                    <td>
+                      <Button size="sm" variant="outline-primary" onClick={() => showTickets(r)}>
+                        {t('reservations.showTickets')}
+                      </Button>
+                      {r.transfer ? (
+                        <>
+                          <div className="small text-muted mt-1">
+                            {t('reservations.transferPending', { email: r.transfer.to_email })}
+                          </div>
+                          <Button size="sm" variant="outline-danger" className="mt-1" onClick={() => cancelTransfer(r)}>
+                            {t('reservations.cancelTransfer')}
+                          </Button>
+                        </>
+                      ) : (
+                        <Button
+                          size="sm"
+                          variant="outline-secondary"
+                          className="ms-2"
+                          onClick={() => setTransfer({ reservation: r, email: '', error: '' })}
+                        >
+                          {t('reservations.transfer')}
+                        </Button>
+                      )}
+                    </td>
                   </tr>
                 );
               })}
@@ -131,6 +240,49 @@ export default function ReservationsPage() {
           </Table>
         )}
       </Container>
+
+      {/* Przekazanie rezerwacji innej osobie – dotychczasowe kody przestaną działać */}
+      <Modal show={transfer !== null} onHide={() => setTransfer(null)} centered>
+        <Form onSubmit={submitTransfer}>
+          <Modal.Header closeButton>
+            <Modal.Title>{t('reservations.transferTitle')}</Modal.Title>
+          </Modal.Header>
+          <Modal.Body>
+            {transfer?.error && <Alert variant="danger">{transfer.error}</Alert>}
+            <p className="text-muted">{t('reservations.transferHint')}</p>
+            <Form.Control
+              type="email"
+              required
+              value={transfer?.email || ''}
+              placeholder={t('register.email')}
+              onChange={(e) => setTransfer({ ...transfer, email: e.target.value })}
+            />
+          </Modal.Body>
+          <Modal.Footer>
+            <Button type="submit">{t('reservations.transfer')}</Button>
+          </Modal.Footer>
+        </Form>
+      </Modal>
+
+      {/* Bilety rezerwacji – osobny kod QR dla każdego miejsca */}
+      <Modal show={tickets !== null} onHide={closeTickets} centered>
+        <Modal.Header closeButton>
+          <Modal.Title>{t('reservations.ticketsTitle')}</Modal.Title>
+        </Modal.Header>
+        <Modal.Body className="text-center">
+          {ticketsError && <Alert variant="danger">{ticketsError}</Alert>}
+          <p className="text-muted">{t('reservations.ticketsHint')}</p>
+          {tickets && !ticketsError && tickets.images.length === 0 && <Spinner animation="border" />}
+          {tickets?.images.map((url, i) => (
+            <figure key={url} className="mb-4">
+              <img src={url} alt={`QR ${i + 1}`} style={{ width: 240, height: 240 }} />
+              <figcaption>
+                {t('reservations.seat', { seat: i + 1, count: tickets.reservation.tickets })}
+              </figcaption>
+            </figure>
+          ))}
+        </Modal.Body>
+      </Modal>
     </>
   );
 }
@@ -0,0 +1,115 @@
+// src/pages/TransferPage.js
+import React, { useState, useEffect, useContext } from 'react';
+import { Container, Row, Col, Card, Button, Alert, Spinner } from 'react-bootstrap';
+import { useParams, useNavigate, Link } from 'react-router-dom';
+import { useTranslation } from 'react-i18next';
+import NavBar from '../components/NavBar';
+import http from '../api/httpClient';
+import { AuthContext } from '../contexts/AuthContext';
+
+// Strona z linku przekazania rezerwacji – adresat bez konta widzi, na jaki
+// adres ma się zarejestrować, a zalogowany może przekazanie przyjąć.
+export default function TransferPage() {
+  const { t } = useTranslation();
+  const { token } = useParams();
+  const navigate = useNavigate();
+  const { user, loading: authLoading } = useContext(AuthContext);
+
+  const [transfer, setTransfer] = useState(null);
+  const [error, setError] = useState('');
+  const [needsVerification, setNeedsVerification] = useState(false);
+  const [verificationSent, setVerificationSent] = useState(false);
+
+  useEffect(() => {
+    http
+      .get(`/transfers/${token}`)
+      .then((res) => setTransfer(res.data))
+      .catch(() => setError(t('transfer.notFound')));
+  }, [token, t]);
+
+  const handleAccept = async () => {
+    try {
+      await http.post(`/transfers/${token}/accept`);
+      navigate('/reservations');
+    } catch (err) {
+      const status = err.response?.status;
+      if (err.response?.data?.code === 'email_not_verified') {
+        setNeedsVerification(true);
+        setError(t('transfer.emailNotVerified'));
+      } else if (status === 403) setError(t('transfer.wrongAccount', { email: transfer.to_email }));
+      else if (status === 410) setError(t('transfer.expired'));
+      else setError(err.response?.data || t('transfer.error'));
+    }
+  };
+
+  // Przekazanie przyjmuje tylko właściciel potwierdzonego adresu
+  const requestVerification = async () => {
+    try {
+      await http.post('/auth/verify-email');
+      setVerificationSent(true);
+    } catch (err) {
+      console.error('Błąd wysyłki linku:', err.response?.data || err.message);
+    }
+  };
+
+  return (
+    <>
+      <NavBar />
+      <Container className="mt-5">
+        <Row className="justify-content-md-center">
+          <Col md={6}>
+            <Card>
+              <Card.Body>
+                <h2 className="mb-4">{t('transfer.title')}</h2>
+                {error && (
+                  <Alert variant="danger">
+                    {error}
+                    {needsVerification && (
+                      <div className="mt-2">
+                        {verificationSent ? (
+                          t('transfer.verificationSent')
+                        ) : (
+                          <Button size="sm" variant="outline-dark" onClick={requestVerification}>
+                            {t('transfer.sendVerification')}
+                          </Button>
+                        )}
+                      </div>
+                    )}
+                  </Alert>
+                )}
+                {!transfer && !error && <Spinner animation="border" />}
+                {transfer && (
+                  <>
+                    <p>
+                      {t('transfer.summary', {
+                        from: transfer.from_email,
+                        title: transfer.event_title,
+                        date: new Date(transfer.event_date).toLocaleDateString(),
+                        count: transfer.tickets,
+                      })}
+                    </p>
+                    {transfer.status !== 'pending' ? (
+                      <Alert variant="secondary">{t(`transfer.status.${transfer.status}`)}</Alert>
+                    ) : authLoading ? null : user ? (
+                      <Button onClick={handleAccept}>{t('transfer.accept')}</Button>
+                    ) : (
+                      <>
+                        <p className="text-muted">{t('transfer.loginHint', { email: transfer.to_email })}</p>
+                        <Button as={Link} to="/login" className="me-2">
+                          {t('navbar.login')}
+                        </Button>
+                        <Button as={Link} to="/register" variant="outline-primary">
+                          {t('navbar.register')}
+                        </Button>
+                      </>
+                    )}
+                  </>
+                )}
+              </Card.Body>
+            </Card>
+          </Col>
+        </Row>
+      </Container>
+    </>
+  );
+}
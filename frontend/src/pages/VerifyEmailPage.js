@@ -0,0 +1,33 @@
+// src/pages/VerifyEmailPage.js
+import React, { useState, useEffect } from 'react';
+import { Container, Alert, Spinner } from 'react-bootstrap';
+import { useParams } from 'react-router-dom';
+import { useTranslation } from 'react-i18next';
+import NavBar from '../components/NavBar';
+import http from '../api/httpClient';
+
+// Strona z linku potwierdzającego adres e-mail.
+export default function VerifyEmailPage() {
+  const { t } = useTranslation();
+  const { token } = useParams();
+  const [status, setStatus] = useState('pending'); // pending | verified | invalid | expired
+
+  useEffect(() => {
+    http
+      .post(`/auth/verify-email/${token}`)
+      .then(() => setStatus('verified'))
+      .catch((err) => setStatus(err.response?.status === 410 ? 'expired' : 'invalid'));
+  }, [token]);
+
+  return (
+    <>
+      <NavBar />
+      <Container className="mt-5">
+        {status === 'pending' && <Spinner animation="border" />}
+        {status === 'verified' && <Alert variant="success">{t('verifyEmail.verified')}</Alert>}
+        {status === 'invalid' && <Alert variant="danger">{t('verifyEmail.invalid')}</Alert>}
+        {status === 'expired' && <Alert variant="warning">{t('verifyEmail.expired')}</Alert>}
+      </Container>
+    </>
+  );
+}
@@ -31,4 +31,208 @@ CREATE TABLE logs (
 );
 
 ALTER TABLE events
-ADD COLUMN image_url VARCHAR;
\ No newline at end of file
+ADD COLUMN image_url VARCHAR;
+
+-- istniejące wydarzenia były już widoczne i rezerwowalne, więc dostają
+-- status published; dopiero nowe zaczynają jako szkic
+ALTER TABLE events
+ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'published'
+  CHECK (status IN ('draft', 'published', 'cancelled', 'postponed', 'completed'));
+ALTER TABLE events
+ALTER COLUMN status SET DEFAULT 'draft';
+
+ALTER TABLE events
+ADD COLUMN deleted_at TIMESTAMP;
+
+ALTER TABLE users DROP CONSTRAINT users_role_check;
+ALTER TABLE users
+ADD CONSTRAINT users_role_check CHECK (role IN ('organizer', 'participant', 'admin'));
+
+CREATE TABLE event_revisions (
+  id SERIAL PRIMARY KEY,
+  event_id INT NOT NULL REFERENCES events(id),
+  actor_id INT NOT NULL REFERENCES users(id),
+  changes TEXT NOT NULL,
+  rollback_of INT REFERENCES event_revisions(id),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX event_revisions_event_id_idx ON event_revisions(event_id);
+
+ALTER TABLE events
+ADD COLUMN version INT NOT NULL DEFAULT 1,
+ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE TABLE event_series (
+  id SERIAL PRIMARY KEY,
+  organizer_id INT NOT NULL REFERENCES users(id),
+  rrule TEXT NOT NULL,
+  dtstart TIMESTAMP NOT NULL,
+  exdates TEXT NOT NULL DEFAULT '',
+  title VARCHAR(255) NOT NULL,
+  description TEXT,
+  capacity INT NOT NULL,
+  image_url VARCHAR,
+  status VARCHAR(20) NOT NULL DEFAULT 'draft',
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE events
+ADD COLUMN series_id INT REFERENCES event_series(id),
+ADD COLUMN recurrence_id TIMESTAMP,
+ADD COLUMN detached BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX events_series_id_idx ON events(series_id, recurrence_id);
+
+ALTER TABLE events
+ADD COLUMN end_date TIMESTAMP,
+ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+ALTER TABLE event_series
+ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+ADD COLUMN duration INT;
+
+CREATE TABLE venues (
+  id SERIAL PRIMARY KEY,
+  organizer_id INT NOT NULL REFERENCES users(id),
+  name VARCHAR(255) NOT NULL,
+  address VARCHAR(500) NOT NULL,
+  latitude DOUBLE PRECISION NOT NULL,
+  longitude DOUBLE PRECISION NOT NULL,
+  capacity INT NOT NULL DEFAULT 0,
+  accessibility TEXT NOT NULL DEFAULT '{}'
+);
+CREATE INDEX venues_location_idx ON venues(latitude, longitude);
+
+ALTER TABLE events
+ADD COLUMN venue_id INT REFERENCES venues(id);
+CREATE INDEX events_venue_id_idx ON events(venue_id);
+ALTER TABLE event_series
+ADD COLUMN venue_id INT REFERENCES venues(id);
+
+ALTER TABLE events
+ADD COLUMN category VARCHAR(50);
+CREATE INDEX events_category_idx ON events(category);
+CREATE TABLE event_tags (
+  event_id INT NOT NULL REFERENCES events(id),
+  tag VARCHAR(30) NOT NULL,
+  PRIMARY KEY (event_id, tag)
+);
+CREATE INDEX event_tags_tag_idx ON event_tags(tag);
+ALTER TABLE event_series
+ADD COLUMN category VARCHAR(50),
+ADD COLUMN tags TEXT;
+
+CREATE TABLE event_images (
+  event_id INT NOT NULL REFERENCES events(id),
+  variant VARCHAR(20) NOT NULL,
+  storage_key VARCHAR(512) NOT NULL,
+  url VARCHAR(2048) NOT NULL,
+  width INT NOT NULL,
+  height INT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (event_id, variant)
+);
+
+CREATE TABLE event_sessions (
+  id SERIAL PRIMARY KEY,
+  event_id INT NOT NULL REFERENCES events(id),
+  title VARCHAR(255) NOT NULL,
+  speaker VARCHAR(255),
+  room VARCHAR(100),
+  starts_at TIMESTAMP NOT NULL,
+  ends_at TIMESTAMP NOT NULL,
+  capacity INT CHECK (capacity > 0),
+  signed_up INT NOT NULL DEFAULT 0,
+  CHECK (ends_at > starts_at)
+);
+CREATE INDEX event_sessions_event_idx ON event_sessions(event_id, starts_at);
+CREATE TABLE session_signups (
+  session_id INT NOT NULL REFERENCES event_sessions(id),
+  event_id INT NOT NULL REFERENCES events(id),
+  user_id INT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (session_id, user_id)
+);
+CREATE INDEX session_signups_user_idx ON session_signups(user_id);
+
+ALTER TABLE events
+ADD COLUMN sequence INT NOT NULL DEFAULT 0;
+ALTER TABLE users
+ADD COLUMN calendar_token VARCHAR(64) UNIQUE;
+
+CREATE TABLE checkins (
+  reservation_id INT NOT NULL REFERENCES reservations(id),
+  seat INT NOT NULL CHECK (seat > 0),
+  event_id INT NOT NULL REFERENCES events(id),
+  checked_in_by INT NOT NULL REFERENCES users(id),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (reservation_id, seat)
+);
+CREATE INDEX checkins_event_idx ON checkins(event_id);
+
+ALTER TABLE checkins
+ADD COLUMN device_id VARCHAR(100) NOT NULL DEFAULT '';
+CREATE TABLE checkin_duplicates (
+  id SERIAL PRIMARY KEY,
+  reservation_id INT NOT NULL REFERENCES reservations(id),
+  seat INT NOT NULL,
+  event_id INT NOT NULL REFERENCES events(id),
+  device_id VARCHAR(100) NOT NULL DEFAULT '',
+  scanned_at TIMESTAMP NOT NULL
+);
+CREATE INDEX checkin_duplicates_event_idx ON checkin_duplicates(event_id);
+
+ALTER TABLE events
+ADD COLUMN transfers_enabled BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE reservations
+ADD COLUMN ticket_generation INT NOT NULL DEFAULT 0;
+CREATE TABLE reservation_transfers (
+  id SERIAL PRIMARY KEY,
+  reservation_id INT NOT NULL REFERENCES reservations(id),
+  event_id INT NOT NULL REFERENCES events(id),
+  from_user_id INT NOT NULL REFERENCES users(id),
+  to_email VARCHAR(255) NOT NULL,
+  token VARCHAR(64) NOT NULL UNIQUE,
+  status VARCHAR(20) NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'accepted', 'cancelled')),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMP NOT NULL,
+  accepted_by INT REFERENCES users(id),
+  accepted_at TIMESTAMP
+);
+CREATE UNIQUE INDEX reservation_transfers_pending_idx
+  ON reservation_transfers(reservation_id) WHERE status = 'pending';
+CREATE INDEX reservation_transfers_to_email_idx ON reservation_transfers(to_email);
+
+ALTER TABLE events
+ADD COLUMN max_tickets_per_user INT CHECK (max_tickets_per_user > 0),
+ADD COLUMN require_verified_email BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users
+ADD COLUMN email_verified_at TIMESTAMP,
+ADD COLUMN email_verification_token VARCHAR(64) UNIQUE;
+
+CREATE TABLE event_tiers (
+  id SERIAL PRIMARY KEY,
+  event_id INT NOT NULL REFERENCES events(id),
+  name VARCHAR(100) NOT NULL,
+  capacity INT NOT NULL CHECK (capacity > 0),
+  max_tickets_per_user INT CHECK (max_tickets_per_user > 0),
+  UNIQUE (event_id, name)
+);
+ALTER TABLE reservations
+ADD COLUMN tier_id INT REFERENCES event_tiers(id);
+
+CREATE TABLE idempotency_keys (
+  -- 'user:<id>' albo 'ip:<adres>' dla żądań anonimowych
+  scope VARCHAR(64) NOT NULL,
+  idem_key VARCHAR(255) NOT NULL,
+  fingerprint CHAR(64) NOT NULL,
+  status INT NOT NULL DEFAULT 0,
+  headers TEXT NOT NULL DEFAULT '{}',
+  body BYTEA,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (scope, idem_key)
+);
+CREATE INDEX idempotency_keys_expires_idx ON idempotency_keys(expires_at);
+
+ALTER TABLE users
+ADD COLUMN email_verification_expires_at TIMESTAMP;
@@ -22,23 +22,40 @@ func GetSecret() []byte {
 
 func JWTMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		header := r.Header.Get("Authorization")
-		parts := strings.Split(header, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		claims, ok := parseToken(r)
+		if !ok {
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
+		next(w, r.WithContext(NewContext(r.Context(), claims)))
+	}
+}
 
-		token, err := jwt.Parse(parts[1], func(t *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
-		if err != nil || !token.Valid {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
+// OptionalJWTMiddleware dodaje claims do kontekstu, jeśli żądanie ma poprawny
+// token, ale nie odrzuca żądań anonimowych (trasy publiczne).
+func OptionalJWTMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if claims, ok := parseToken(r); ok {
+			r = r.WithContext(NewContext(r.Context(), claims))
 		}
+		next(w, r)
+	}
+}
+
+// parseToken weryfikuje nagłówek "Authorization: Bearer <jwt>".
+func parseToken(r *http.Request) (jwt.MapClaims, bool) {
+	header := r.Header.Get("Authorization")
+	parts := strings.Split(header, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, false
+	}
 
-		
-		ctx := NewContext(r.Context(), token.Claims.(jwt.MapClaims))
-		next(w, r.WithContext(ctx))
+	token, err := jwt.Parse(parts[1], func(t *jwt.Token) (interface{}, error) {
+		return jwtSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, false
 	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	return claims, ok
 }
@@ -0,0 +1,76 @@
+// File: internal/blobstore/blobstore.go
+//
+// Package blobstore przechowuje pliki przesyłane przez użytkowników (np.
+// plakaty wydarzeń) w wymiennym magazynie: lokalnym katalogu albo w usłudze
+// zgodnej z S3 (AWS, MinIO, Ceph...).
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Store to magazyn plików adresowanych kluczem (np. "events/5/ab12-card.jpg").
+type Store interface {
+	// Put zapisuje (lub nadpisuje) plik o danym kluczu.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Delete usuwa plik; brak pliku nie jest błędem.
+	Delete(ctx context.Context, key string) error
+	// URL zwraca adres, pod którym klienci pobierają plik.
+	URL(key string) string
+}
+
+// Config wybiera i konfiguruje magazyn.
+type Config struct {
+	// Driver: "local" (domyślnie) albo "s3".
+	Driver string      `yaml:"driver"`
+	Local  LocalConfig `yaml:"local"`
+	S3     S3Config    `yaml:"s3"`
+}
+
+// ErrInvalidKey oznacza klucz, który mógłby wyjść poza magazyn (np. "../x").
+var ErrInvalidKey = errors.New("blobstore: invalid key")
+
+// New tworzy magazyn według konfiguracji. Klucze S3 można podać także
+// w zmiennych AWS_ACCESS_KEY_ID i AWS_SECRET_ACCESS_KEY (zamiast w pliku).
+func New(cfg Config) (Store, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocal(cfg.Local)
+	case "s3":
+		if cfg.S3.AccessKey == "" {
+			cfg.S3.AccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+		}
+		if cfg.S3.SecretKey == "" {
+			cfg.S3.SecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		}
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("blobstore: unknown driver %q", cfg.Driver)
+	}
+}
+
+// validKey sprawdza, że klucz to względna ścieżka bez "..", pustych
+// segmentów i znaków spoza bezpiecznego zestawu.
+func validKey(key string) bool {
+	if key == "" || len(key) > 512 {
+		return false
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return false
+		}
+	}
+	for _, r := range key {
+		ok := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			r == '/' || r == '-' || r == '_' || r == '.'
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
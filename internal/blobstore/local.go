@@ -0,0 +1,118 @@
+// File: internal/blobstore/local.go
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalConfig to magazyn w katalogu lokalnym, serwowany przez ten serwer.
+type LocalConfig struct {
+	// Dir to katalog z plikami (domyślnie "uploads").
+	Dir string `yaml:"dir"`
+	// BaseURL to prefiks adresów plików (domyślnie "/uploads"); może być
+	// pełnym adresem (np. "https://api.example.com/uploads"), gdy frontend
+	// działa pod inną domeną niż API.
+	BaseURL string `yaml:"baseURL"`
+}
+
+// Local przechowuje pliki w katalogu na dysku.
+type Local struct {
+	dir     string
+	baseURL string
+	prefix  string // ścieżka z baseURL, pod którą Handler serwuje pliki
+}
+
+// NewLocal tworzy magazyn lokalny (i jego katalog, jeśli nie istnieje).
+func NewLocal(cfg LocalConfig) (*Local, error) {
+	if cfg.Dir == "" {
+		cfg.Dir = "uploads"
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "/uploads"
+	}
+	base, err := url.Parse(strings.TrimRight(cfg.BaseURL, "/"))
+	if err != nil || base.Path == "" {
+		return nil, fmt.Errorf("blobstore: invalid local baseURL %q", cfg.BaseURL)
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Local{dir: cfg.Dir, baseURL: base.String(), prefix: base.Path}, nil
+}
+
+// Put zapisuje plik atomowo: najpierw do pliku tymczasowego, potem rename,
+// więc czytelnik nigdy nie zobaczy połowy pliku.
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+	path := filepath.Join(l.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete usuwa plik; brak pliku nie jest błędem.
+func (l *Local) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+	err := os.Remove(filepath.Join(l.dir, filepath.FromSlash(key)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// URL zwraca adres pliku pod BaseURL.
+func (l *Local) URL(key string) string {
+	return l.baseURL + "/" + key
+}
+
+// Prefix zwraca ścieżkę, pod którą trzeba zamontować Handler (np. "/uploads/").
+func (l *Local) Prefix() string {
+	return l.prefix + "/"
+}
+
+// Handler serwuje pliki magazynu (bez listowania katalogów). Klucze są
+// unikalne, więc pliki można cache'ować bez końca.
+func (l *Local) Handler() http.Handler {
+	files := http.FileServer(http.Dir(l.dir))
+	return http.StripPrefix(l.prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		if !validKey(key) {
+			http.NotFound(w, r)
+			return
+		}
+		if info, err := os.Stat(filepath.Join(l.dir, filepath.FromSlash(key))); err != nil || info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		files.ServeHTTP(w, r)
+	}))
+}
@@ -0,0 +1,190 @@
+// File: internal/cors/cors.go
+package cors
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Config opisuje politykę CORS ładowaną z pliku konfiguracyjnego.
+type Config struct {
+	// AllowedOrigins to dozwolone originy, np. "https://app.example.com",
+	// "https://*.example.com" (dowolna subdomena) albo "*" (każdy origin).
+	AllowedOrigins   []string `yaml:"allowedOrigins"`
+	AllowedMethods   []string `yaml:"allowedMethods"`
+	AllowedHeaders   []string `yaml:"allowedHeaders"`
+	ExposedHeaders   []string `yaml:"exposedHeaders"`
+	AllowCredentials bool     `yaml:"allowCredentials"`
+	// MaxAge to czas (w sekundach), przez jaki przeglądarka może cache'ować preflight.
+	MaxAge int `yaml:"maxAge"`
+}
+
+// Domyślne wartości, gdy konfiguracja ich nie podaje – odpowiadają
+// dotychczasowemu zachowaniu (frontend na localhost:3000).
+var (
+	defaultOrigins = []string{"http://localhost:3000"}
+	defaultMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+	defaultHeaders = []string{"Authorization", "Content-Type", "If-Match", "Idempotency-Key"}
+)
+
+// originPattern to dozwolony origin; wildcard oznacza "*.domena".
+type originPattern struct {
+	scheme, host string
+	wildcard     bool
+}
+
+// Policy to middleware CORS zbudowane z Config.
+type Policy struct {
+	cfg         Config
+	anyOrigin   bool
+	origins     []originPattern
+	methods     map[string]bool
+	headers     map[string]bool
+	routeExists func(*http.Request) bool
+}
+
+// New buduje politykę. routeExists mówi, czy istnieje trasa dla żądania
+// (metoda podmieniona na Access-Control-Request-Method) – preflight
+// obsługujemy tylko dla takich tras, reszta trafia do routera (404/405).
+func New(cfg Config, routeExists func(*http.Request) bool) *Policy {
+	if len(cfg.AllowedOrigins) == 0 {
+		cfg.AllowedOrigins = defaultOrigins
+	}
+	if len(cfg.AllowedMethods) == 0 {
+		cfg.AllowedMethods = defaultMethods
+	}
+	if len(cfg.AllowedHeaders) == 0 {
+		cfg.AllowedHeaders = defaultHeaders
+	}
+
+	p := &Policy{
+		cfg:         cfg,
+		methods:     map[string]bool{},
+		headers:     map[string]bool{},
+		routeExists: routeExists,
+	}
+	for _, o := range cfg.AllowedOrigins {
+		if o == "*" {
+			p.anyOrigin = true
+			continue
+		}
+		u, err := url.Parse(strings.ToLower(o))
+		if err != nil || u.Host == "" {
+			continue
+		}
+		pat := originPattern{scheme: u.Scheme, host: u.Host}
+		if strings.HasPrefix(u.Host, "*.") {
+			pat.wildcard = true
+			pat.host = u.Host[1:] // ".example.com"
+		}
+		p.origins = append(p.origins, pat)
+	}
+	for _, m := range cfg.AllowedMethods {
+		p.methods[strings.ToUpper(m)] = true
+	}
+	for _, h := range cfg.AllowedHeaders {
+		p.headers[http.CanonicalHeaderKey(h)] = true
+	}
+	return p
+}
+
+// allowOrigin sprawdza, czy origin pasuje do któregoś z wzorców.
+func (p *Policy) allowOrigin(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, pat := range p.origins {
+		if pat.scheme != u.Scheme {
+			continue
+		}
+		if pat.wildcard && strings.HasSuffix(u.Host, pat.host) && len(u.Host) > len(pat.host) {
+			return true
+		}
+		if !pat.wildcard && pat.host == u.Host {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler owija cały router polityką CORS.
+func (p *Policy) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		// Odpowiedź zależy od nagłówka Origin, więc cache'e muszą to uwzględniać.
+		if !p.anyOrigin || p.cfg.AllowCredentials {
+			w.Header().Add("Vary", "Origin")
+		}
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if preflight {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			p.preflight(w, r, next)
+			return
+		}
+		if p.allowOrigin(origin) {
+			p.setOrigin(w, origin)
+			if len(p.cfg.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(p.cfg.ExposedHeaders, ", "))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (p *Policy) setOrigin(w http.ResponseWriter, origin string) {
+	if p.anyOrigin && !p.cfg.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		// Przy credentials przeglądarka nie akceptuje "*" – odsyłamy konkretny origin.
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+	if p.cfg.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (p *Policy) preflight(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+
+	// Preflight tylko dla istniejących tras – o resztę zapytamy router.
+	if p.routeExists != nil {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if !p.routeExists(probe) {
+			next.ServeHTTP(w, r)
+			return
+		}
+	}
+
+	if !p.allowOrigin(r.Header.Get("Origin")) || !p.methods[method] {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	for _, h := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		h = strings.TrimSpace(h)
+		if h != "" && !p.headers[http.CanonicalHeaderKey(h)] {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}
+
+	p.setOrigin(w, r.Header.Get("Origin"))
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(p.cfg.AllowedMethods, ", "))
+	w.Header().Set("Access-Control-Allow-Headers", strings.Join(p.cfg.AllowedHeaders, ", "))
+	if p.cfg.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(p.cfg.MaxAge))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,105 @@
+// File: internal/cors/cors_test.go
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// onlyEvents udaje router z jedną trasą: GET/POST /events.
+func onlyEvents(r *http.Request) bool {
+	return r.URL.Path == "/events" && (r.Method == "GET" || r.Method == "POST")
+}
+
+func serve(p *Policy, req *http.Request) *httptest.ResponseRecorder {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	w := httptest.NewRecorder()
+	p.Handler(next).ServeHTTP(w, req)
+	return w
+}
+
+func TestOrigins(t *testing.T) {
+	p := New(Config{AllowedOrigins: []string{"https://app.example.com", "https://*.staging.example.com"}}, onlyEvents)
+	cases := map[string]bool{
+		"https://app.example.com":         true,
+		"https://a.staging.example.com":   true,
+		"https://a.b.staging.example.com": true,
+		"https://staging.example.com":     false,
+		"http://app.example.com":          false,
+		"https://evil.com":                false,
+		"https://evilstaging.example.com": false,
+	}
+	for origin, want := range cases {
+		req := httptest.NewRequest("GET", "/events", nil)
+		req.Header.Set("Origin", origin)
+		w := serve(p, req)
+		got := w.Header().Get("Access-Control-Allow-Origin") == origin
+		if got != want {
+			t.Errorf("origin %s allowed=%v, want %v", origin, got, want)
+		}
+		if w.Header().Get("Vary") != "Origin" {
+			t.Errorf("origin %s: expected Vary: Origin, got %q", origin, w.Header().Get("Vary"))
+		}
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	p := New(Config{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	}, onlyEvents)
+
+	preflight := func(path, method, headers string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("OPTIONS", path, nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", method)
+		if headers != "" {
+			req.Header.Set("Access-Control-Request-Headers", headers)
+		}
+		return serve(p, req)
+	}
+
+	w := preflight("/events", "POST", "authorization, content-type")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	h := w.Header()
+	if h.Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		h.Get("Access-Control-Allow-Credentials") != "true" ||
+		h.Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("unexpected preflight headers: %v", h)
+	}
+
+	// Nieistniejąca trasa lub metoda – decyduje router.
+	if w := preflight("/nope", "GET", ""); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected router response for unknown path, got %d", w.Code)
+	}
+	if w := preflight("/events", "DELETE", ""); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected router response for unrouted method, got %d", w.Code)
+	}
+	// Niedozwolony nagłówek.
+	if w := preflight("/events", "POST", "X-Custom"); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for disallowed header, got %d", w.Code)
+	}
+}
+
+func TestAnyOriginWithoutCredentials(t *testing.T) {
+	p := New(Config{AllowedOrigins: []string{"*"}}, onlyEvents)
+	req := httptest.NewRequest("GET", "/events", nil)
+	req.Header.Set("Origin", "https://whatever.io")
+	w := serve(p, req)
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatalf("expected *, got %q", w.Header().Get("Access-Control-Allow-Origin"))
+	}
+	if w.Header().Get("Vary") != "" {
+		t.Errorf("Vary should not be needed for *, got %q", w.Header().Get("Vary"))
+	}
+}
@@ -0,0 +1,268 @@
+// File: internal/feed/feed.go
+//
+// Package feed zapisuje listę wpisów jako Atom (RFC 4287), RSS 2.0
+// i JSON Feed 1.1 – do osadzania nadchodzących wydarzeń na innych stronach.
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+// Feed to kanał z wpisami. Wynik zależy tylko od pól (bez bieżącego czasu),
+// więc ten sam stan daje te same bajty – i ten sam ETag.
+type Feed struct {
+	Title       string
+	Description string
+	Link        string // strona HTML kanału
+	SelfURL     string // adres samego kanału
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item to jeden wpis.
+type Item struct {
+	ID         string // stały identyfikator (IRI)
+	URL        string
+	Title      string
+	Summary    string // zwykły tekst
+	Published  time.Time
+	Updated    time.Time
+	Categories []string
+	Image      string
+	// Event opisuje wydarzenie – trafia do rozszerzenia "_event" JSON Feed
+	// (Atom i RSS nie mają na to pól, tam informacje są w Summary).
+	Event *Event
+}
+
+// Event to termin i miejsce wydarzenia.
+type Event struct {
+	Start    time.Time  `json:"start"`
+	End      *time.Time `json:"end,omitempty"`
+	Timezone string     `json:"timezone"`
+	Location string     `json:"location,omitempty"`
+	Status   string     `json:"status"`
+}
+
+// --- Atom ---
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Sub     string      `xml:"subtitle,omitempty"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Summary    string         `xml:"summary,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Author     atomAuthor     `xml:"author"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// Atom zwraca kanał w formacie Atom.
+func (f *Feed) Atom() ([]byte, error) {
+	out := atomFeed{
+		ID:      f.SelfURL,
+		Title:   f.Title,
+		Sub:     f.Description,
+		Updated: atomTime(f.Updated),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: f.SelfURL},
+			{Rel: "alternate", Type: "text/html", Href: f.Link},
+		},
+	}
+	for _, it := range f.Items {
+		e := atomEntry{
+			ID:      it.ID,
+			Title:   it.Title,
+			Updated: atomTime(it.Updated),
+			Links:   []atomLink{{Rel: "alternate", Type: "text/html", Href: it.URL}},
+			Summary: it.Summary,
+			// Atom wymaga autora wpisu albo kanału
+			Author: atomAuthor{Name: f.Title},
+		}
+		if !it.Published.IsZero() {
+			e.Published = atomTime(it.Published)
+		}
+		if it.Image != "" {
+			e.Links = append(e.Links, atomLink{Rel: "enclosure", Type: "image/jpeg", Href: it.Image})
+		}
+		for _, c := range it.Categories {
+			e.Categories = append(e.Categories, atomCategory{Term: c})
+		}
+		out.Entries = append(out.Entries, e)
+	}
+	return marshalXML(out)
+}
+
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// --- RSS ---
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Self          rssSelf   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+// rssSelf to <atom:link rel="self">, zalecany przez walidatory RSS.
+type rssSelf struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	Description string   `xml:"description,omitempty"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// RSS zwraca kanał w formacie RSS 2.0.
+func (f *Feed) RSS() ([]byte, error) {
+	out := rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			Self:        rssSelf{Href: f.SelfURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	if !f.Updated.IsZero() {
+		out.Channel.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+	}
+	for _, it := range f.Items {
+		item := rssItem{
+			Title:       it.Title,
+			Link:        it.URL,
+			GUID:        rssGUID{Value: it.ID, IsPermaLink: it.ID == it.URL},
+			Description: it.Summary,
+			Categories:  it.Categories,
+		}
+		if published := firstNonZero(it.Published, it.Updated); !published.IsZero() {
+			item.PubDate = published.UTC().Format(time.RFC1123Z)
+		}
+		out.Channel.Items = append(out.Channel.Items, item)
+	}
+	return marshalXML(out)
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(body, '\n')...), nil
+}
+
+func firstNonZero(ts ...time.Time) time.Time {
+	for _, t := range ts {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// --- JSON Feed ---
+
+type jsonFeed struct {
+	Version     string     `json:"version"`
+	Title       string     `json:"title"`
+	HomePageURL string     `json:"home_page_url"`
+	FeedURL     string     `json:"feed_url"`
+	Description string     `json:"description,omitempty"`
+	Items       []jsonItem `json:"items"`
+}
+
+type jsonItem struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	ContentText   string   `json:"content_text"`
+	Image         string   `json:"image,omitempty"`
+	DatePublished string   `json:"date_published,omitempty"`
+	DateModified  string   `json:"date_modified,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	Event         *Event   `json:"_event,omitempty"`
+}
+
+// JSON zwraca kanał w formacie JSON Feed 1.1.
+func (f *Feed) JSON() ([]byte, error) {
+	out := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.SelfURL,
+		Description: f.Description,
+		Items:       []jsonItem{},
+	}
+	for _, it := range f.Items {
+		item := jsonItem{
+			ID:          it.ID,
+			URL:         it.URL,
+			Title:       it.Title,
+			ContentText: it.Summary,
+			Image:       it.Image,
+			Tags:        it.Categories,
+			Event:       it.Event,
+		}
+		if !it.Published.IsZero() {
+			item.DatePublished = atomTime(it.Published)
+		}
+		if !it.Updated.IsZero() {
+			item.DateModified = atomTime(it.Updated)
+		}
+		out.Items = append(out.Items, item)
+	}
+	body, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(body, '\n'), nil
+}
@@ -0,0 +1,109 @@
+// File: internal/feed/feed_test.go
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sample() *Feed {
+	updated := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	return &Feed{
+		Title:       "EventHub",
+		Description: "Nadchodzące wydarzenia",
+		Link:        "https://example.com/events",
+		SelfURL:     "https://example.com/api/v1/feeds/events.atom?category=concert",
+		Updated:     updated,
+		Items: []Item{{
+			ID:         "https://example.com/events/5",
+			URL:        "https://example.com/events/5",
+			Title:      "Koncert <jazz> & blues",
+			Summary:    "18.06.2030 18:00 (Europe/Warsaw)",
+			Updated:    updated,
+			Categories: []string{"concert", "na-żywo"},
+			Image:      "https://example.com/uploads/5-card.jpg",
+			Event:      &Event{Start: time.Date(2030, 6, 18, 16, 0, 0, 0, time.UTC), Timezone: "Europe/Warsaw", Status: "published"},
+		}},
+	}
+}
+
+func TestAtom(t *testing.T) {
+	body, err := sample().Atom()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed struct {
+		ID      string `xml:"id"`
+		Updated string `xml:"updated"`
+		Entries []struct {
+			ID         string `xml:"id"`
+			Title      string `xml:"title"`
+			Categories []struct {
+				Term string `xml:"term,attr"`
+			} `xml:"category"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(body, &parsed); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, body)
+	}
+	if parsed.Updated != "2030-01-02T03:04:05Z" || len(parsed.Entries) != 1 ||
+		parsed.Entries[0].Title != "Koncert <jazz> & blues" || len(parsed.Entries[0].Categories) != 2 {
+		t.Fatalf("unexpected feed %+v\n%s", parsed, body)
+	}
+	for _, want := range []string{`<feed xmlns="http://www.w3.org/2005/Atom">`, `rel="self"`, `rel="enclosure"`, "&amp;"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+}
+
+func TestRSS(t *testing.T) {
+	body, err := sample().RSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed struct {
+		Channel struct {
+			Items []struct {
+				GUID    string `xml:"guid"`
+				PubDate string `xml:"pubDate"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(body, &parsed); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, body)
+	}
+	if len(parsed.Channel.Items) != 1 || parsed.Channel.Items[0].PubDate != "Wed, 02 Jan 2030 03:04:05 +0000" {
+		t.Fatalf("unexpected channel %+v\n%s", parsed, body)
+	}
+	for _, want := range []string{`<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">`, `<atom:link href=`, `isPermaLink="true"`} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	body, err := sample().JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	item := parsed["items"].([]interface{})[0].(map[string]interface{})
+	if parsed["version"] != "https://jsonfeed.org/version/1.1" || item["image"] == nil ||
+		item["_event"].(map[string]interface{})["timezone"] != "Europe/Warsaw" {
+		t.Fatalf("unexpected JSON feed:\n%s", body)
+	}
+
+	// Pusty kanał ma "items": [], a nie null
+	empty, _ := (&Feed{Title: "x"}).JSON()
+	if !strings.Contains(string(empty), `"items": []`) {
+		t.Fatalf("empty feed must have items array:\n%s", empty)
+	}
+}
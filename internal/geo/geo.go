@@ -0,0 +1,100 @@
+// File: internal/geo/geo.go
+//
+// Package geo liczy odległości na kuli ziemskiej i prostokąty otaczające,
+// którymi zawężamy wyszukiwanie w SQL bez rozszerzeń przestrzennych
+// (tak samo działa na Postgresie i na SQLite w testach).
+package geo
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EarthRadiusKm to średni promień Ziemi (IUGG).
+const EarthRadiusKm = 6371.0088
+
+// Distance zwraca odległość w kilometrach między dwoma punktami (wzór haversine).
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := radians(lat1), radians(lat2)
+	dPhi := radians(lat2 - lat1)
+	dLambda := radians(lon2 - lon1)
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Box to prostokąt w stopniach. Jeśli MinLon > MaxLon, prostokąt przechodzi
+// przez południk 180° (długość w [MinLon, 180] albo [-180, MaxLon]).
+type Box struct {
+	MinLat, MaxLat float64
+	MinLon, MaxLon float64
+}
+
+// BoundingBox zwraca prostokąt zawierający wszystkie punkty odległe od
+// (lat, lon) o co najwyżej radiusKm. Prostokąt bywa większy niż koło –
+// dokładną odległość sprawdza się potem przez Distance.
+func BoundingBox(lat, lon, radiusKm float64) Box {
+	r := radiusKm / EarthRadiusKm // promień kątowy w radianach
+	dLat := degrees(r)
+	b := Box{MinLat: lat - dLat, MaxLat: lat + dLat, MinLon: -180, MaxLon: 180}
+
+	// Koło obejmuje biegun – wtedy wchodzą w grę wszystkie długości.
+	if b.MaxLat >= 90 || b.MinLat <= -90 {
+		b.MinLat, b.MaxLat = math.Max(b.MinLat, -90), math.Min(b.MaxLat, 90)
+		return b
+	}
+	dLon := degrees(math.Asin(math.Sin(r) / math.Cos(radians(lat))))
+	if dLon >= 180 {
+		return b
+	}
+	b.MinLon, b.MaxLon = lon-dLon, lon+dLon
+	if b.MinLon < -180 {
+		b.MinLon += 360
+	}
+	if b.MaxLon > 180 {
+		b.MaxLon -= 360
+	}
+	return b
+}
+
+// WrapsAntimeridian mówi, czy prostokąt przechodzi przez południk 180°.
+func (b Box) WrapsAntimeridian() bool {
+	return b.MinLon > b.MaxLon
+}
+
+// Contains mówi, czy punkt leży w prostokącie.
+func (b Box) Contains(lat, lon float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+	if b.WrapsAntimeridian() {
+		return lon >= b.MinLon || lon <= b.MaxLon
+	}
+	return lon >= b.MinLon && lon <= b.MaxLon
+}
+
+// ErrBadPoint to błąd ParsePoint.
+var ErrBadPoint = errors.New("point must be lat,lon with lat in [-90, 90] and lon in [-180, 180]")
+
+// ParsePoint parsuje punkt w formacie "lat,lon", np. "52.2297,21.0122".
+func ParsePoint(s string) (lat, lon float64, err error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return 0, 0, ErrBadPoint
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lon, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err1 != nil || err2 != nil || !ValidLatLon(lat, lon) {
+		return 0, 0, ErrBadPoint
+	}
+	return lat, lon, nil
+}
+
+// ValidLatLon sprawdza zakresy współrzędnych (NaN jest niepoprawny).
+func ValidLatLon(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
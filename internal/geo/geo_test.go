@@ -0,0 +1,73 @@
+// File: internal/geo/geo_test.go
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+	}{
+		{"same point", 52.2297, 21.0122, 52.2297, 21.0122, 0},
+		{"Warsaw-Krakow", 52.2297, 21.0122, 50.0647, 19.9450, 252},
+		{"London-Paris", 51.5074, -0.1278, 48.8566, 2.3522, 344},
+		{"across antimeridian", 0, 179.5, 0, -179.5, 111},
+	}
+	for _, c := range cases {
+		got := Distance(c.lat1, c.lon1, c.lat2, c.lon2)
+		if math.Abs(got-c.want) > 1 {
+			t.Errorf("%s: expected ~%.0f km, got %.1f", c.name, c.want, got)
+		}
+	}
+}
+
+func TestBoundingBox_ContainsCircle(t *testing.T) {
+	centers := [][2]float64{{52.2297, 21.0122}, {-33.8688, 151.2093}, {0, 179.9}, {89.5, 0}, {64, -21}}
+	for _, c := range centers {
+		b := BoundingBox(c[0], c[1], 100)
+		// Punkty na okręgu o promieniu 100 km (co 10°) muszą leżeć w prostokącie.
+		for bearing := 0.0; bearing < 360; bearing += 10 {
+			lat, lon := destination(c[0], c[1], bearing, 99.9)
+			if !b.Contains(lat, lon) {
+				t.Errorf("center %v: point %.4f,%.4f (bearing %.0f) outside %+v", c, lat, lon, bearing, b)
+			}
+		}
+	}
+}
+
+func TestBoundingBox_Edges(t *testing.T) {
+	if b := BoundingBox(0, 179.9, 50); !b.WrapsAntimeridian() || !b.Contains(0, -179.9) {
+		t.Fatalf("expected box to wrap the antimeridian, got %+v", b)
+	}
+	if b := BoundingBox(89.9, 10, 50); b.MaxLat != 90 || b.MinLon != -180 || b.MaxLon != 180 {
+		t.Fatalf("expected polar box to span all longitudes, got %+v", b)
+	}
+	if b := BoundingBox(52, 21, 10); b.WrapsAntimeridian() || b.Contains(52, 23) {
+		t.Fatalf("unexpected box %+v", b)
+	}
+}
+
+func TestParsePoint(t *testing.T) {
+	lat, lon, err := ParsePoint("52.2297, 21.0122")
+	if err != nil || lat != 52.2297 || lon != 21.0122 {
+		t.Fatalf("unexpected result %v, %v, %v", lat, lon, err)
+	}
+	for _, bad := range []string{"", "52.2", "a,b", "91,0", "0,181", "1,2,3", "NaN,0"} {
+		if _, _, err := ParsePoint(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+// destination zwraca punkt odległy o km w kierunku bearing (stopnie od północy).
+func destination(lat, lon, bearing, km float64) (float64, float64) {
+	phi1, lambda1, theta, delta := radians(lat), radians(lon), radians(bearing), km/EarthRadiusKm
+	phi2 := math.Asin(math.Sin(phi1)*math.Cos(delta) + math.Cos(phi1)*math.Sin(delta)*math.Cos(theta))
+	lambda2 := lambda1 + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(phi1), math.Cos(delta)-math.Sin(phi1)*math.Sin(phi2))
+	lon2 := math.Mod(degrees(lambda2)+540, 360) - 180
+	return degrees(phi2), lon2
+}
@@ -0,0 +1,227 @@
+// File: internal/handlers/attendees.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/models"
+)
+
+// Stronicowanie listy uczestników.
+const (
+	defaultAttendeesPerPage = 50
+	maxAttendeesPerPage     = 200
+)
+
+const attendeeColumns = "r.id, r.user_id, u.email, r.tickets, r.created_at"
+
+// attendeeFilter zwraca warunek i argumenty dla rezerwacji wydarzenia,
+// zawężonych przez ?q= (fragment adresu e-mail, bez względu na wielkość liter).
+func attendeeFilter(r *http.Request, eventID int) (string, []interface{}) {
+	where := "r.event_id = $1"
+	args := []interface{}{eventID}
+	if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" {
+		args = append(args, "%"+escapeLike(strings.ToLower(q))+"%")
+		where += ` AND LOWER(u.email) LIKE $` + strconv.Itoa(len(args)) + ` ESCAPE '\'`
+	}
+	return where, args
+}
+
+// escapeLike neutralizuje znaki specjalne wzorca LIKE.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func scanAttendee(rows *sql.Rows, a *models.Attendee) error {
+	if err := rows.Scan(&a.ReservationID, &a.UserID, &a.Email, &a.Tickets, &a.ReservedAt); err != nil {
+		return err
+	}
+	a.ReservedAt = a.ReservedAt.UTC()
+	return nil
+}
+
+// pageParam czyta dodatni parametr stronicowania (domyślnie def). W razie
+// błędu sam wysyła 400 i zwraca false.
+func pageParam(w http.ResponseWriter, r *http.Request, name string, def, max int) (int, bool) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || (max > 0 && n > max) {
+		msg := "must be a positive number"
+		if max > 0 {
+			msg = "must be between 1 and " + strconv.Itoa(max)
+		}
+		writeFieldError(w, name, msg)
+		return 0, false
+	}
+	return n, true
+}
+
+// ListAttendees zwraca rezerwacje wydarzenia z adresami uczestników (tylko
+// właściciel lub admin), stronicowane przez ?page= i ?per_page=, z
+// wyszukiwaniem ?q= po adresie e-mail.
+func ListAttendees(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 1) Uprawnienia
+		eventID, _, ok := ownedEvent(w, r, db)
+		if !ok {
+			return
+		}
+
+		// 2) Parametry
+		page, ok := pageParam(w, r, "page", 1, 0)
+		if !ok {
+			return
+		}
+		perPage, ok := pageParam(w, r, "per_page", defaultAttendeesPerPage, maxAttendeesPerPage)
+		if !ok {
+			return
+		}
+		where, args := attendeeFilter(r, eventID)
+		from := " FROM reservations r JOIN users u ON u.id = r.user_id WHERE " + where
+
+		// 3) Liczności (wszystkich pasujących, nie tylko strony)
+		var total, tickets int
+		if err := db.QueryRow("SELECT COUNT(*), COALESCE(SUM(r.tickets), 0)"+from, args...).Scan(&total, &tickets); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// 4) Strona
+		args = append(args, perPage, (page-1)*perPage)
+		rows, err := db.Query(
+			"SELECT "+attendeeColumns+from+" ORDER BY r.created_at, r.id LIMIT $"+strconv.Itoa(len(args)-1)+" OFFSET $"+strconv.Itoa(len(args)),
+			args...,
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+		attendees := []models.Attendee{}
+		for rows.Next() {
+			var a models.Attendee
+			if err := scanAttendee(rows, &a); err != nil {
+				continue
+			}
+			attendees = append(attendees, a)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"attendees": attendees,
+			"total":     total,
+			"tickets":   tickets,
+			"page":      page,
+			"per_page":  perPage,
+		})
+	}
+}
+
+// ExportAttendees zwraca wszystkich uczestników wydarzenia (z filtrem ?q=)
+// jako plik CSV albo JSON (?format=csv|json, domyślnie CSV). Wiersze są
+// zapisywane do odpowiedzi w trakcie czytania z bazy – lista nie jest
+// ładowana w całości do pamięci.
+func ExportAttendees(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 1) Uprawnienia i format
+		eventID, _, ok := ownedEvent(w, r, db)
+		if !ok {
+			return
+		}
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "csv"
+		}
+		if format != "csv" && format != "json" {
+			writeFieldError(w, "format", "must be one of: csv, json")
+			return
+		}
+
+		// 2) Zapytanie
+		where, args := attendeeFilter(r, eventID)
+		rows, err := db.Query(
+			"SELECT "+attendeeColumns+" FROM reservations r JOIN users u ON u.id = r.user_id WHERE "+where+" ORDER BY r.created_at, r.id",
+			args...,
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		// 3) Strumieniowanie; po wysłaniu nagłówków błąd można już tylko zalogować
+		filename := "event-" + strconv.Itoa(eventID) + "-attendees." + format
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		if format == "csv" {
+			err = streamAttendeesCSV(w, rows)
+		} else {
+			err = streamAttendeesJSON(w, rows)
+		}
+		if err != nil {
+			log.Printf("export attendees of event %d: %v", eventID, err)
+		}
+	}
+}
+
+func streamAttendeesCSV(w http.ResponseWriter, rows *sql.Rows) error {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"reservation_id", "user_id", "email", "tickets", "reserved_at"})
+	for rows.Next() {
+		var a models.Attendee
+		if err := scanAttendee(rows, &a); err != nil {
+			return err
+		}
+		cw.Write([]string{
+			strconv.Itoa(a.ReservationID),
+			strconv.Itoa(a.UserID),
+			csvSafe(a.Email),
+			strconv.Itoa(a.Tickets),
+			a.ReservedAt.Format(time.RFC3339),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	return rows.Err()
+}
+
+func streamAttendeesJSON(w http.ResponseWriter, rows *sql.Rows) error {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	w.Write([]byte("["))
+	for first := true; rows.Next(); first = false {
+		var a models.Attendee
+		if err := scanAttendee(rows, &a); err != nil {
+			return err
+		}
+		if !first {
+			w.Write([]byte(","))
+		}
+		if err := enc.Encode(a); err != nil {
+			return err
+		}
+	}
+	w.Write([]byte("]\n"))
+	return rows.Err()
+}
+
+// csvSafe chroni przed wstrzyknięciem formuł przy otwieraniu pliku
+// w arkuszu: komórka zaczynająca się od =, +, -, @ dostaje apostrof.
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
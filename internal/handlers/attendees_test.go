@@ -0,0 +1,82 @@
+// File: internal/handlers/attendees_test.go
+package handlers_test
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestListAttendees(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	id := insertEvent(t, db, "published", 100)
+	for i, email := range []string{"anna@example.com", "bartek@example.com", "=cmd@example.com", "anna_b@example.com"} {
+		db.Exec("INSERT INTO users(id, email, password_hash, role) VALUES($1, $2, 'x', 'participant')", 10+i, email)
+		db.Exec("INSERT INTO reservations(user_id, event_id, tickets, created_at) VALUES($1, $2, $3, $4)",
+			10+i, id, i+1, time.Date(2030, 1, 1+i, 10, 0, 0, 0, time.UTC))
+	}
+	other := insertEvent(t, db, "published", 10)
+	db.Exec("INSERT INTO reservations(user_id, event_id, tickets) VALUES(10, $1, 5)", other)
+
+	type page struct {
+		Attendees []models.Attendee `json:"attendees"`
+		Total     int               `json:"total"`
+		Tickets   int               `json:"tickets"`
+	}
+	list := func(ctx context.Context, query string) (int, page) {
+		w := call(handlers.ListAttendees(db), "GET", "/events/x/attendees"+query, ctx, id, "")
+		var p page
+		json.Unmarshal(w.Body.Bytes(), &p)
+		return w.Code, p
+	}
+
+	code, p := list(owner, "?per_page=3")
+	if code != http.StatusOK || p.Total != 4 || p.Tickets != 10 || len(p.Attendees) != 3 || p.Attendees[0].Email != "anna@example.com" {
+		t.Fatalf("first page: unexpected %d %+v", code, p)
+	}
+	if _, p := list(owner, "?per_page=3&page=2"); len(p.Attendees) != 1 || p.Attendees[0].Email != "anna_b@example.com" {
+		t.Fatalf("second page: unexpected %+v", p)
+	}
+	// "_" w wyszukiwaniu to zwykły znak, nie wzorzec LIKE
+	if _, p := list(owner, "?q=ANNA_"); p.Total != 1 || p.Attendees[0].UserID != 13 {
+		t.Fatalf("search: unexpected %+v", p)
+	}
+	if code, _ := list(owner, "?per_page=1000"); code != http.StatusBadRequest {
+		t.Fatalf("per_page over limit: expected 400, got %d", code)
+	}
+	otherOrganizer := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(2), "role": "organizer"})
+	if code, _ := list(otherOrganizer, ""); code != http.StatusForbidden {
+		t.Fatalf("other organizer: expected 403, got %d", code)
+	}
+
+	// Eksport CSV – wszystkie wiersze, z ochroną przed formułami
+	w := call(handlers.ExportAttendees(db), "GET", "/events/x/attendees/export", owner, id, "")
+	if w.Header().Get("Content-Type") != "text/csv; charset=utf-8" ||
+		!strings.Contains(w.Header().Get("Content-Disposition"), "attendees.csv") {
+		t.Fatalf("csv export: unexpected headers %v", w.Header())
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil || len(records) != 5 || records[0][2] != "email" || records[3][2] != "'=cmd@example.com" {
+		t.Fatalf("csv export: unexpected %v %v", records, err)
+	}
+
+	// Eksport JSON z filtrem
+	w = call(handlers.ExportAttendees(db), "GET", "/events/x/attendees/export?format=json&q=anna", owner, id, "")
+	var exported []models.Attendee
+	if err := json.Unmarshal(w.Body.Bytes(), &exported); err != nil || len(exported) != 2 {
+		t.Fatalf("json export: unexpected %s (%v)", w.Body.String(), err)
+	}
+	if w := call(handlers.ExportAttendees(db), "GET", "/events/x/attendees/export?format=xlsx", owner, id, ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown format: expected 400, got %d", w.Code)
+	}
+}
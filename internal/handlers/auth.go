@@ -17,12 +17,11 @@ import (
 func Register(db *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req struct {
-			Email    string `json:"email"`
-			Password string `json:"password"`
-			Role     string `json:"role"`
+			Email    string `json:"email" validate:"required,email,max=255"`
+			Password string `json:"password" validate:"required,password"`
+			Role     string `json:"role" validate:"required,oneof=organizer|participant"`
 		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
@@ -45,11 +44,10 @@ func Register(db *sql.DB) http.HandlerFunc {
 func Login(db *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var creds struct {
-			Email    string `json:"email"`
-			Password string `json:"password"`
+			Email    string `json:"email" validate:"required,max=255"`
+			Password string `json:"password" validate:"required,max=72"`
 		}
-		if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		if !decodeJSON(w, r, &creds) {
 			return
 		}
 		var user models.User
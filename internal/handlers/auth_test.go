@@ -24,3 +24,38 @@ func TestRegister(t *testing.T) {
 		t.Errorf("expected status 201, got %d", w.Code)
 	}
 }
+
+func TestRegister_Validation(t *testing.T) {
+	db := storage.NewTestDB()
+	handler := handlers.Register(db)
+
+	body := []byte(`{"email":"nope","password":"a","role":"admin"}`)
+	req := httptest.NewRequest("POST", "/auth/register", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+	for _, field := range []string{`"email"`, `"password"`, `"role"`} {
+		if !bytes.Contains(w.Body.Bytes(), []byte(field)) {
+			t.Errorf("expected error for %s, body=%s", field, w.Body.String())
+		}
+	}
+}
+
+func TestRegister_BodyTooLarge(t *testing.T) {
+	db := storage.NewTestDB()
+	handler := handlers.Register(db)
+
+	body := append([]byte(`{"email":"`), bytes.Repeat([]byte("a"), 2<<20)...)
+	req := httptest.NewRequest("POST", "/auth/register", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status 413, got %d", w.Code)
+	}
+}
@@ -0,0 +1,210 @@
+// File: internal/handlers/calendar.go
+package handlers
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/ical"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/gorilla/mux"
+)
+
+const (
+	icalProdID = "-//EventHub//EventHub//PL"
+	// icalUIDDomain to część UID po "@"; UID wydarzenia nie zmienia się przy
+	// aktualizacjach, więc klient kalendarza zastępuje wpis zamiast go dublować.
+	icalUIDDomain = "eventhub"
+)
+
+// icalStatus mapuje status wydarzenia na STATUS w iCalendar.
+var icalStatus = map[string]string{
+	models.EventPublished: ical.StatusConfirmed,
+	models.EventCompleted: ical.StatusConfirmed,
+	models.EventPostponed: ical.StatusTentative,
+	models.EventCancelled: ical.StatusCancelled,
+}
+
+// icalEvent zamienia wydarzenie (z etykietami i opcjonalnym miejscem) na VEVENT.
+func icalEvent(e models.Event, venue *models.Venue, now time.Time) ical.Event {
+	ev := ical.Event{
+		UID:         "event-" + strconv.Itoa(e.ID) + "@" + icalUIDDomain,
+		Sequence:    e.Sequence,
+		Stamp:       now,
+		Start:       e.Date,
+		End:         e.EndDate,
+		Location:    location(e.Timezone),
+		Summary:     e.Title,
+		Description: e.Description,
+		Status:      icalStatus[e.Status],
+	}
+	if e.UpdatedAt != nil {
+		ev.Modified = *e.UpdatedAt
+	}
+	if venue != nil {
+		ev.Place = venue.Name + ", " + venue.Address
+		ev.Geo = &[2]float64{venue.Latitude, venue.Longitude}
+	}
+	if e.Category != "" {
+		ev.Categories = append(ev.Categories, e.Category)
+	}
+	ev.Categories = append(ev.Categories, e.Tags...)
+	return ev
+}
+
+func writeCalendar(w http.ResponseWriter, cal ical.Calendar, filename string) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if filename != "" {
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	}
+	w.Write(cal.Bytes())
+}
+
+// EventICS zwraca wydarzenie jako plik .ics (do importu w kalendarzu).
+// Widoczność jak w GetEvent – szkic tylko dla właściciela.
+func EventICS(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		var e models.Event
+		err := scanEvent(db.QueryRow(
+			"SELECT "+eventColumns+" FROM events WHERE id=$1 AND deleted_at IS NULL", id,
+		), &e)
+		if err != nil || (e.Status == models.EventDraft && !isOwner(r, e.OrganizerID)) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		if err := loadEventTags(db, &e); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var venue *models.Venue
+		if e.VenueID != nil {
+			var v models.Venue
+			if err := scanVenue(db.QueryRow("SELECT "+venueColumns+" FROM venues WHERE id=$1", *e.VenueID), &v); err == nil {
+				venue = &v
+			}
+		}
+		cal := ical.Calendar{ProdID: icalProdID, Events: []ical.Event{icalEvent(e, venue, time.Now())}}
+		writeCalendar(w, cal, "event-"+strconv.Itoa(e.ID)+".ics")
+	}
+}
+
+// CalendarFeed to subskrypcja kalendarza użytkownika pod sekretnym adresem
+// /calendar/{token}.ics: wydarzenia, na które ma rezerwacje (jak w
+// ListReservations), także odwołane – ze STATUS:CANCELLED, żeby klient je usunął.
+func CalendarFeed(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 1) Właściciel tokenu
+		var userID int
+		if err := db.QueryRow(
+			"SELECT id FROM users WHERE calendar_token=$1", mux.Vars(r)["token"],
+		).Scan(&userID); err != nil {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+
+		// 2) Zarezerwowane wydarzenia
+		where := "deleted_at IS NULL AND status <> $1 AND id IN (SELECT event_id FROM reservations WHERE user_id = $2)"
+		args := []interface{}{models.EventDraft, userID}
+		rows, err := db.Query("SELECT "+eventColumns+" FROM events WHERE "+where+" ORDER BY date, id", args...)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var events []models.Event
+		for rows.Next() {
+			var e models.Event
+			if err := scanEvent(rows, &e); err != nil {
+				continue
+			}
+			events = append(events, e)
+		}
+		rows.Close()
+		if err := attachTags(db, events,
+			"SELECT event_id, tag FROM event_tags WHERE event_id IN (SELECT id FROM events WHERE "+where+") ORDER BY tag", args...,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// 3) Miejsca tych wydarzeń
+		venues, err := loadVenues(db, where, args...)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		now := time.Now()
+		cal := ical.Calendar{ProdID: icalProdID, Name: "EventHub"}
+		for _, e := range events {
+			var venue *models.Venue
+			if e.VenueID != nil {
+				venue = venues[*e.VenueID]
+			}
+			cal.Events = append(cal.Events, icalEvent(e, venue, now))
+		}
+		w.Header().Set("Cache-Control", "private, max-age=300")
+		writeCalendar(w, cal, "")
+	}
+}
+
+// newSecretToken losuje sekretny token (subskrypcja kalendarza, link
+// przekazania rezerwacji, potwierdzenie adresu e-mail).
+func newSecretToken() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// GetCalendarToken zwraca token subskrypcji kalendarza zalogowanego
+// użytkownika (tworzy go przy pierwszym wywołaniu). Adres subskrypcji to
+// /calendar/{token}.ics.
+func GetCalendarToken(db *sql.DB) http.HandlerFunc {
+	return calendarToken(db, false)
+}
+
+// RotateCalendarToken ustawia nowy token – stary adres subskrypcji przestaje działać.
+func RotateCalendarToken(db *sql.DB) http.HandlerFunc {
+	return calendarToken(db, true)
+}
+
+func calendarToken(db *sql.DB, rotate bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		userID := int(claims["id"].(float64))
+
+		token, err := newSecretToken()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		query := "UPDATE users SET calendar_token=$1 WHERE id=$2"
+		if !rotate {
+			// Nie nadpisujemy istniejącego tokenu (także przy równoległych wywołaniach)
+			query += " AND calendar_token IS NULL"
+		}
+		if _, err := db.Exec(query, token, userID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := db.QueryRow("SELECT calendar_token FROM users WHERE id=$1", userID).Scan(&token); err != nil {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"token": token})
+	}
+}
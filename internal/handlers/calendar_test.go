@@ -0,0 +1,138 @@
+// File: internal/handlers/calendar_test.go
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+)
+
+func eventICS(t *testing.T, h http.HandlerFunc, ctx context.Context, id int) string {
+	t.Helper()
+	w := call(h, "GET", "/events/x.ics", ctx, id, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("ics: expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/calendar; charset=utf-8" {
+		t.Fatalf("unexpected Content-Type %q", ct)
+	}
+	return w.Body.String()
+}
+
+func TestEventICS_SequenceAndStatus(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	id := insertEvent(t, db, "published", 10)
+	db.Exec("UPDATE events SET timezone='Europe/Warsaw' WHERE id=$1", id)
+	uid := fmt.Sprintf("UID:event-%d@eventhub\r\n", id)
+
+	out := eventICS(t, handlers.EventICS(db), context.Background(), id)
+	for _, want := range []string{uid, "SEQUENCE:0\r\n", "TZID:Europe/Warsaw\r\n", "DTSTART;TZID=Europe/Warsaw:", "STATUS:CONFIRMED\r\n"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	// Zmiana daty podbija SEQUENCE, sama zmiana tytułu – nie
+	put := func(body string, version int) {
+		req := mux.SetURLVars(
+			httptest.NewRequest("PUT", "/events/x", bytes.NewBufferString(body)).WithContext(owner),
+			map[string]string{"id": strconv.Itoa(id)},
+		)
+		req.Header.Set("If-Match", fmt.Sprintf(`"%d"`, version))
+		w := httptest.NewRecorder()
+		handlers.UpdateEvent(db)(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("update: expected 200, got %d (%s)", w.Code, w.Body.String())
+		}
+	}
+	date := futureDateTime()
+	put(fmt.Sprintf(`{"title":"Nowy","date_time":%q,"capacity":10,"timezone":"Europe/Warsaw"}`, date), 1)
+	out = eventICS(t, handlers.EventICS(db), context.Background(), id)
+	if !strings.Contains(out, uid) || !strings.Contains(out, "SEQUENCE:1\r\n") || !strings.Contains(out, "LAST-MODIFIED:") {
+		t.Fatalf("date change must keep UID and bump SEQUENCE:\n%s", out)
+	}
+	put(fmt.Sprintf(`{"title":"Inny","date_time":%q,"capacity":10,"timezone":"Europe/Warsaw"}`, date), 2)
+	if out := eventICS(t, handlers.EventICS(db), context.Background(), id); !strings.Contains(out, "SEQUENCE:1\r\n") {
+		t.Fatalf("title change must not bump SEQUENCE:\n%s", out)
+	}
+
+	if w := doTransition(handlers.CancelEvent(db), owner, id); w.Code != http.StatusOK {
+		t.Fatalf("cancel: expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	out = eventICS(t, handlers.EventICS(db), context.Background(), id)
+	if !strings.Contains(out, "STATUS:CANCELLED\r\n") || !strings.Contains(out, "SEQUENCE:2\r\n") {
+		t.Fatalf("cancelled event: unexpected\n%s", out)
+	}
+
+	// Szkic jest niewidoczny dla innych
+	draft := insertEvent(t, db, "draft", 10)
+	if w := call(handlers.EventICS(db), "GET", "/", context.Background(), draft, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("draft: expected 404, got %d", w.Code)
+	}
+}
+
+func TestCalendarFeed_Token(t *testing.T) {
+	db := newEventDB(t)
+	db.Exec("INSERT INTO users(id, email, password_hash, role) VALUES(7, 'u@example.com', 'x', 'participant')")
+	user := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(7), "role": "participant"})
+
+	booked := insertEvent(t, db, "published", 10)
+	cancelled := insertEvent(t, db, "cancelled", 10)
+	other := insertEvent(t, db, "published", 10)
+	for _, id := range []int{booked, cancelled} {
+		db.Exec("INSERT INTO reservations(user_id, event_id, tickets) VALUES(7, $1, 1)", id)
+	}
+
+	token := func(h http.HandlerFunc) string {
+		w := call(h, "GET", "/calendar/token", user, 0, "")
+		var resp map[string]string
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusOK || len(resp["token"]) != 40 {
+			t.Fatalf("token: unexpected %d %s", w.Code, w.Body.String())
+		}
+		return resp["token"]
+	}
+	tok := token(handlers.GetCalendarToken(db))
+	if again := token(handlers.GetCalendarToken(db)); again != tok {
+		t.Fatalf("token must be stable, got %q and %q", tok, again)
+	}
+
+	feed := func(tok string) *httptest.ResponseRecorder {
+		req := mux.SetURLVars(httptest.NewRequest("GET", "/calendar/x.ics", nil), map[string]string{"token": tok})
+		w := httptest.NewRecorder()
+		handlers.CalendarFeed(db)(w, req)
+		return w
+	}
+	w := feed(tok)
+	out := w.Body.String()
+	if w.Code != http.StatusOK || strings.Count(out, "BEGIN:VEVENT") != 2 || !strings.Contains(out, "STATUS:CANCELLED\r\n") {
+		t.Fatalf("feed: unexpected %d\n%s", w.Code, out)
+	}
+	if strings.Contains(out, fmt.Sprintf("UID:event-%d@", other)) {
+		t.Fatalf("feed must contain only reserved events:\n%s", out)
+	}
+
+	// Po rotacji stary adres przestaje działać
+	rotated := token(handlers.RotateCalendarToken(db))
+	if rotated == tok {
+		t.Fatal("rotate must change the token")
+	}
+	if w := feed(tok); w.Code != http.StatusNotFound {
+		t.Fatalf("old token: expected 404, got %d", w.Code)
+	}
+	if w := feed(rotated); w.Code != http.StatusOK {
+		t.Fatalf("new token: expected 200, got %d", w.Code)
+	}
+}
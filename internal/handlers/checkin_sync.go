@@ -0,0 +1,339 @@
+// File: internal/handlers/checkin_sync.go
+package handlers
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/bartbaranski/eventhub/internal/ticket"
+)
+
+// snapshotFormat to wersja formatu migawki biletów.
+const snapshotFormat = 1
+
+// Synchronizacja czytników offline.
+const (
+	maxSyncCheckins = 5000
+	// syncClockSkew to tolerancja zegara czytnika – skany „z przyszłości”
+	// ponad ten margines są odrzucane.
+	syncClockSkew = 5 * time.Minute
+)
+
+// snapshotSignatureHeader zawiera podpis Ed25519 (base64) treści migawki.
+const snapshotSignatureHeader = "X-Snapshot-Signature"
+
+// CheckinKey zwraca klucz publiczny Ed25519, którym czytniki sprawdzają
+// podpis migawek biletów (pobierany raz, przy konfiguracji czytnika).
+func CheckinKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		public := ticket.SnapshotKey(auth.GetSecret()).Public().(ed25519.PublicKey)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"algorithm":  "Ed25519",
+			"public_key": base64.StdEncoding.EncodeToString(public),
+		})
+	}
+}
+
+// TicketSnapshot zwraca listę ważnych biletów wydarzenia (z kodami i stanem
+// wejść) do sprawdzania biletów offline. Treść odpowiedzi jest podpisana
+// (nagłówek X-Snapshot-Signature), a Version i ETag zmieniają się tylko przy
+// zmianie listy biletów lub wejść – czytnik może pytać z If-None-Match.
+func TicketSnapshot(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 1) Uprawnienia
+		eventID, _, ok := ownedEvent(w, r, db)
+		if !ok {
+			return
+		}
+
+		// 2) Wszystkie miejsca wszystkich rezerwacji, ze stanem wejść
+		rows, err := db.Query(
+			`SELECT r.id, r.tickets, r.ticket_generation, u.email FROM reservations r JOIN users u ON u.id = r.user_id
+			 WHERE r.event_id=$1 ORDER BY r.id`,
+			eventID,
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		type reservation struct {
+			id, tickets, generation int
+			email                   string
+		}
+		var reservations []reservation
+		for rows.Next() {
+			var res reservation
+			if err := rows.Scan(&res.id, &res.tickets, &res.generation, &res.email); err != nil {
+				continue
+			}
+			reservations = append(reservations, res)
+		}
+		rows.Close()
+
+		checkedIn := map[[2]int]time.Time{}
+		rows, err = db.Query("SELECT reservation_id, seat, created_at FROM checkins WHERE event_id=$1", eventID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for rows.Next() {
+			var rid, seat int
+			var at time.Time
+			if err := rows.Scan(&rid, &seat, &at); err != nil {
+				continue
+			}
+			checkedIn[[2]int{rid, seat}] = at.UTC()
+		}
+		rows.Close()
+
+		key := ticketKey()
+		snap := models.TicketSnapshot{Format: snapshotFormat, EventID: eventID, Tickets: []models.SnapshotTicket{}}
+		hash := sha256.New()
+		for _, res := range reservations {
+			for seat := 1; seat <= res.tickets; seat++ {
+				t := models.SnapshotTicket{
+					Code:          ticket.Sign(key, ticket.Ticket{Reservation: res.id, Event: eventID, Seat: seat, Generation: res.generation}),
+					ReservationID: res.id,
+					Seat:          seat,
+					Email:         res.email,
+				}
+				if at, ok := checkedIn[[2]int{res.id, seat}]; ok {
+					t.CheckedInAt = &at
+				}
+				snap.Tickets = append(snap.Tickets, t)
+				json.NewEncoder(hash).Encode(t)
+			}
+		}
+
+		// 3) Wersja = skrót zawartości (bez czasu wygenerowania)
+		snap.Version = hex.EncodeToString(hash.Sum(nil)[:8])
+		etag := `"` + snap.Version + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "private, no-cache")
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		// 4) Podpis dokładnie tych bajtów, które wysyłamy
+		snap.GeneratedAt = time.Now().UTC().Truncate(time.Second)
+		body, err := json.Marshal(snap)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sig := ed25519.Sign(ticket.SnapshotKey(auth.GetSecret()), body)
+		w.Header().Set(snapshotSignatureHeader, base64.StdEncoding.EncodeToString(sig))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}
+}
+
+type syncRequest struct {
+	DeviceID string        `json:"device_id" validate:"required,max=100"`
+	Checkins []syncCheckin `json:"checkins" validate:"required"`
+}
+
+type syncCheckin struct {
+	Code      string    `json:"code"`
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+// SyncCheckins przyjmuje skany zebrane przez czytnik offline. Skany są
+// rozpatrywane w kolejności czasu skanowania; o tym, kto wszedł, decyduje
+// najwcześniejszy skan danego miejsca – także gdy inny czytnik (albo wejście
+// online) zsynchronizował się wcześniej, ale skanował później. Pozostałe skany
+// tego miejsca są duplikatami i trafiają do raportu CheckinDuplicates.
+func SyncCheckins(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// 1) Uprawnienia i body
+		eventID, actorID, ok := ownedEvent(w, r, db)
+		if !ok {
+			return
+		}
+		var req syncRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		if len(req.Checkins) > maxSyncCheckins {
+			writeFieldError(w, "checkins", "must contain at most "+strconv.Itoa(maxSyncCheckins)+" items")
+			return
+		}
+		if open, err := openForCheckin(db, eventID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		} else if !open {
+			http.Error(w, "Event is not open for check-in", http.StatusConflict)
+			return
+		}
+
+		// 2) Kolejność skanowania (przy równych czasach – kolejność przesłania)
+		order := make([]int, len(req.Checkins))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return req.Checkins[order[a]].ScannedAt.Before(req.Checkins[order[b]].ScannedAt)
+		})
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		// 3) Rozstrzygnięcie każdego skanu
+		results := make([]models.CheckinSyncResult, len(req.Checkins))
+		counts := map[string]int{}
+		latest := time.Now().UTC().Add(syncClockSkew)
+		for _, i := range order {
+			in := req.Checkins[i]
+			res, err := syncOne(tx, eventID, actorID, req.DeviceID, in, latest)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			res.Index = i
+			results[i] = res
+			counts[res.Status]++
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"processed":  len(results),
+			"checked_in": counts["checked_in"],
+			"duplicates": counts["duplicate"],
+			"invalid":    counts["invalid"],
+			"results":    results,
+		})
+	}
+}
+
+// syncOne rozstrzyga jeden skan z czytnika offline. Błąd oznacza wyłącznie
+// błąd bazy – niepoprawne skany dają wynik "invalid".
+func syncOne(tx *sql.Tx, eventID, actorID int, deviceID string, in syncCheckin, latest time.Time) (models.CheckinSyncResult, error) {
+	out := models.CheckinSyncResult{Status: "invalid"}
+	scannedAt := in.ScannedAt.UTC()
+	if scannedAt.IsZero() {
+		out.Error = "scanned_at is required"
+		return out, nil
+	}
+	if scannedAt.After(latest) {
+		out.Error = "scanned_at is in the future"
+		return out, nil
+	}
+	c, err := lookupTicket(tx, eventID, in.Code)
+	if ticketRejected(err) {
+		out.Error = err.Error()
+		return out, nil
+	}
+	if err != nil {
+		return out, err
+	}
+	out.ReservationID, out.Seat, out.Email = c.ReservationID, c.Seat, c.Email
+
+	// 1) Pierwszy skan tego miejsca
+	res, err := tx.Exec(
+		`INSERT INTO checkins(reservation_id, seat, event_id, checked_in_by, device_id, created_at)
+		 VALUES($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (reservation_id, seat) DO NOTHING`,
+		c.ReservationID, c.Seat, eventID, actorID, deviceID, scannedAt,
+	)
+	if err != nil {
+		return out, err
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		out.Status, out.CheckedInAt, out.DeviceID = "checked_in", &scannedAt, deviceID
+		return out, nil
+	}
+
+	// 2) Miejsce już skasowane: wcześniejszy skan przejmuje wejście, a
+	// dotychczasowe (późniejsze) staje się duplikatem
+	firstAt, firstDevice, err := firstCheckin(tx, c.ReservationID, c.Seat)
+	if err != nil {
+		return out, err
+	}
+	if scannedAt.Before(firstAt) {
+		res, err := tx.Exec(
+			`UPDATE checkins SET created_at=$1, device_id=$2, checked_in_by=$3
+			 WHERE reservation_id=$4 AND seat=$5 AND created_at > $1`,
+			scannedAt, deviceID, actorID, c.ReservationID, c.Seat,
+		)
+		if err != nil {
+			return out, err
+		}
+		if n, _ := res.RowsAffected(); n == 1 {
+			if err := recordDuplicate(tx, eventID, c, firstDevice, firstAt); err != nil {
+				return out, err
+			}
+			out.Status, out.CheckedInAt, out.DeviceID = "checked_in", &scannedAt, deviceID
+			return out, nil
+		}
+		// Ktoś zmienił wpis w międzyczasie – rozpatrujemy skan jeszcze raz
+		if firstAt, firstDevice, err = firstCheckin(tx, c.ReservationID, c.Seat); err != nil {
+			return out, err
+		}
+	}
+	if err := recordDuplicate(tx, eventID, c, deviceID, scannedAt); err != nil {
+		return out, err
+	}
+	out.Status, out.CheckedInAt, out.DeviceID = "duplicate", &firstAt, firstDevice
+	return out, nil
+}
+
+// CheckinDuplicates zwraca raport prób ponownego wejścia na skasowane bilety
+// (online i z czytników offline), od najnowszej. Próby dotyczące miejsc,
+// których skasowanie cofnięto, nie są pokazywane.
+func CheckinDuplicates(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		eventID, _, ok := ownedEvent(w, r, db)
+		if !ok {
+			return
+		}
+		rows, err := db.Query(
+			`SELECT d.reservation_id, d.seat, u.email, d.device_id, d.scanned_at, c.created_at, c.device_id
+			 FROM checkin_duplicates d
+			 JOIN reservations r ON r.id = d.reservation_id
+			 JOIN users u ON u.id = r.user_id
+			 JOIN checkins c ON c.reservation_id = d.reservation_id AND c.seat = d.seat
+			 WHERE d.event_id=$1
+			 ORDER BY d.scanned_at DESC, d.id DESC`,
+			eventID,
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		out := []models.CheckinDuplicate{}
+		for rows.Next() {
+			var d models.CheckinDuplicate
+			if err := rows.Scan(&d.ReservationID, &d.Seat, &d.Email, &d.DeviceID, &d.ScannedAt, &d.FirstCheckedInAt, &d.FirstDeviceID); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			d.ScannedAt, d.FirstCheckedInAt = d.ScannedAt.UTC(), d.FirstCheckedInAt.UTC()
+			out = append(out, d)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(out)
+	}
+}
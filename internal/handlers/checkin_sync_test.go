@@ -0,0 +1,147 @@
+// File: internal/handlers/checkin_sync_test.go
+package handlers_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/bartbaranski/eventhub/internal/ticket"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+)
+
+func TestTicketSnapshot(t *testing.T) {
+	db := newEventDB(t)
+	auth.Init("test-secret")
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	eventID := insertEvent(t, db, "published", 100)
+	db.Exec("INSERT INTO users(id, email, password_hash, role) VALUES(5, 'ola@example.com', 'x', 'participant')")
+	db.Exec("INSERT INTO reservations(id, user_id, event_id, tickets) VALUES(1, 5, $1, 2)", eventID)
+
+	// Klucz publiczny czytnika
+	w := httptest.NewRecorder()
+	handlers.CheckinKey()(w, httptest.NewRequest("GET", "/checkin/key", nil))
+	var key struct {
+		PublicKey string `json:"public_key"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &key)
+	public, _ := base64.StdEncoding.DecodeString(key.PublicKey)
+
+	snapshot := func(etag string) *httptest.ResponseRecorder {
+		req := mux.SetURLVars(httptest.NewRequest("GET", "/events/x/checkin/snapshot", nil).WithContext(owner),
+			map[string]string{"id": fmt.Sprint(eventID)})
+		req.Header.Set("If-None-Match", etag)
+		w := httptest.NewRecorder()
+		handlers.TicketSnapshot(db)(w, req)
+		return w
+	}
+	w = snapshot("")
+	sig, _ := base64.StdEncoding.DecodeString(w.Header().Get("X-Snapshot-Signature"))
+	if w.Code != http.StatusOK || !ed25519.Verify(public, w.Body.Bytes(), sig) {
+		t.Fatalf("snapshot: unexpected %d or bad signature %s", w.Code, w.Body.String())
+	}
+	var snap models.TicketSnapshot
+	json.Unmarshal(w.Body.Bytes(), &snap)
+	if len(snap.Tickets) != 2 || snap.Tickets[1].Seat != 2 || snap.Tickets[0].CheckedInAt != nil || w.Header().Get("ETag") != `"`+snap.Version+`"` {
+		t.Fatalf("snapshot: unexpected %+v", snap)
+	}
+	if _, err := ticket.Verify(ticket.Key([]byte("test-secret")), snap.Tickets[0].Code); err != nil {
+		t.Fatalf("snapshot code does not verify: %v", err)
+	}
+
+	// Ta sama wersja – 304; po wejściu – nowa wersja ze stanem miejsca
+	etag := w.Header().Get("ETag")
+	if w := snapshot(etag); w.Code != http.StatusNotModified {
+		t.Fatalf("unchanged snapshot: expected 304, got %d", w.Code)
+	}
+	call(handlers.CheckIn(db), "POST", "/events/x/checkin", owner, eventID, fmt.Sprintf(`{"code":%q}`, snap.Tickets[0].Code))
+	w = snapshot(etag)
+	json.Unmarshal(w.Body.Bytes(), &snap)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag || snap.Tickets[0].CheckedInAt == nil {
+		t.Fatalf("changed snapshot: unexpected %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSyncCheckins(t *testing.T) {
+	db := newEventDB(t)
+	auth.Init("test-secret")
+	key := ticket.Key([]byte("test-secret"))
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	eventID := insertEvent(t, db, "published", 100)
+	db.Exec("INSERT INTO users(id, email, password_hash, role) VALUES(5, 'ola@example.com', 'x', 'participant')")
+	db.Exec("INSERT INTO reservations(id, user_id, event_id, tickets) VALUES(1, 5, $1, 2)", eventID)
+	code := func(seat int) string {
+		return ticket.Sign(key, ticket.Ticket{Reservation: 1, Event: eventID, Seat: seat})
+	}
+	base := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+
+	type syncResponse struct {
+		CheckedIn  int                        `json:"checked_in"`
+		Duplicates int                        `json:"duplicates"`
+		Invalid    int                        `json:"invalid"`
+		Results    []models.CheckinSyncResult `json:"results"`
+	}
+	sync := func(device string, scans ...[2]interface{}) syncResponse {
+		t.Helper()
+		items := []map[string]interface{}{}
+		for _, s := range scans {
+			items = append(items, map[string]interface{}{"code": s[0], "scanned_at": s[1]})
+		}
+		body, _ := json.Marshal(map[string]interface{}{"device_id": device, "checkins": items})
+		w := call(handlers.SyncCheckins(db), "POST", "/events/x/checkin/sync", owner, eventID, string(body))
+		if w.Code != http.StatusOK {
+			t.Fatalf("sync: unexpected %d %s", w.Code, w.Body.String())
+		}
+		var resp syncResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	// Bramka A: miejsce 1 o +10 min i ponownie o +12 min, błędny kod, skan z przyszłości
+	resp := sync("gate-a",
+		[2]interface{}{code(1), base.Add(12 * time.Minute)},
+		[2]interface{}{code(1), base.Add(10 * time.Minute)},
+		[2]interface{}{"EH1.1.1.1.xxxxxxxxxxxxxxxxxxxxxx", base},
+		[2]interface{}{code(2), time.Now().Add(time.Hour)},
+	)
+	if resp.CheckedIn != 1 || resp.Duplicates != 1 || resp.Invalid != 2 ||
+		resp.Results[1].Status != "checked_in" || resp.Results[0].Status != "duplicate" ||
+		!resp.Results[0].CheckedInAt.Equal(base.Add(10*time.Minute)) {
+		t.Fatalf("gate A: unexpected %+v", resp)
+	}
+
+	// Bramka B synchronizuje się później, ale skanowała wcześniej – to jej wejście się liczy
+	resp = sync("gate-b", [2]interface{}{code(1), base.Add(5 * time.Minute)}, [2]interface{}{code(2), base})
+	if resp.CheckedIn != 2 || resp.Results[0].DeviceID != "gate-b" {
+		t.Fatalf("gate B: unexpected %+v", resp)
+	}
+
+	// Raport duplikatów: dwa skany bramki A dla miejsca 1, pierwsze wejście – bramka B
+	w := call(handlers.CheckinDuplicates(db), "GET", "/events/x/checkin/duplicates", owner, eventID, "")
+	var dups []models.CheckinDuplicate
+	json.Unmarshal(w.Body.Bytes(), &dups)
+	if len(dups) != 2 || dups[0].DeviceID != "gate-a" || dups[1].DeviceID != "gate-a" ||
+		dups[0].FirstDeviceID != "gate-b" || !dups[0].FirstCheckedInAt.Equal(base.Add(5*time.Minute)) || dups[0].Email != "ola@example.com" {
+		t.Fatalf("duplicates: unexpected %s", w.Body.String())
+	}
+
+	// Skan online po synchronizacji to też duplikat
+	if w := call(handlers.CheckIn(db), "POST", "/events/x/checkin", owner, eventID, fmt.Sprintf(`{"code":%q,"device_id":"door"}`, code(2))); w.Code != http.StatusConflict {
+		t.Fatalf("online after sync: expected 409, got %d", w.Code)
+	}
+	w = call(handlers.CheckinDuplicates(db), "GET", "/events/x/checkin/duplicates", owner, eventID, "")
+	json.Unmarshal(w.Body.Bytes(), &dups)
+	if len(dups) != 3 || dups[0].DeviceID != "door" {
+		t.Fatalf("duplicates after online scan: unexpected %s", w.Body.String())
+	}
+}
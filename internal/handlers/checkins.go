@@ -0,0 +1,267 @@
+// File: internal/handlers/checkins.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/bartbaranski/eventhub/internal/ticket"
+)
+
+type checkinRequest struct {
+	Code     string `json:"code" validate:"required,max=200"`
+	DeviceID string `json:"device_id" validate:"max=100"` // opcjonalny identyfikator czytnika
+}
+
+// Błędy lookupTicket.
+var (
+	errTicketInvalid    = errors.New("invalid ticket code")
+	errTicketOtherEvent = errors.New("ticket is for another event")
+	errTicketNotFound   = errors.New("ticket not found")
+	errTicketRevoked    = errors.New("ticket is no longer valid (reservation was transferred)")
+)
+
+// ticketRejected mówi, czy błąd lookupTicket dotyczy samego biletu; inne
+// błędy to błędy bazy.
+func ticketRejected(err error) bool {
+	switch err {
+	case errTicketInvalid, errTicketOtherEvent, errTicketNotFound, errTicketRevoked:
+		return true
+	}
+	return false
+}
+
+// lookupTicket sprawdza podpis kodu, zgodność z wydarzeniem i istnienie
+// miejsca w rezerwacji, zwracając dane do odpowiedzi (bez CheckedIn
+// i CheckedInAt).
+func lookupTicket(q queryRower, eventID int, code string) (models.Checkin, error) {
+	var c models.Checkin
+	var generation int
+	t, err := ticket.Verify(ticketKey(), code)
+	if err != nil {
+		return c, errTicketInvalid
+	}
+	if t.Event != eventID {
+		return c, errTicketOtherEvent
+	}
+
+	// Poprawny podpis nie wystarcza – rezerwacja mogła zostać usunięta
+	err = q.QueryRow(
+		`SELECT r.id, r.user_id, u.email, r.tickets, r.ticket_generation
+		 FROM reservations r JOIN users u ON u.id = r.user_id
+		 WHERE r.id=$1 AND r.event_id=$2`,
+		t.Reservation, eventID,
+	).Scan(&c.ReservationID, &c.UserID, &c.Email, &c.Tickets, &generation)
+	if err == sql.ErrNoRows || (err == nil && t.Seat > c.Tickets) {
+		return c, errTicketNotFound
+	}
+	if err != nil {
+		return c, err
+	}
+	if t.Generation != generation {
+		return c, errTicketRevoked
+	}
+	c.Seat = t.Seat
+	return c, nil
+}
+
+// scannedTicket to lookupTicket dla pojedynczego skanu: w razie błędu sam
+// wysyła odpowiedź (400 dla złego kodu, 404 dla nieistniejącego miejsca,
+// 500 dla błędu bazy) i zwraca false.
+func scannedTicket(w http.ResponseWriter, db *sql.DB, eventID int, code string) (models.Checkin, bool) {
+	c, err := lookupTicket(db, eventID, code)
+	switch err {
+	case nil:
+		return c, true
+	case errTicketNotFound:
+		http.Error(w, "Ticket not found", http.StatusNotFound)
+	case errTicketInvalid, errTicketOtherEvent, errTicketRevoked:
+		writeFieldError(w, "code", err.Error())
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+	return c, false
+}
+
+// firstCheckin zwraca czas i czytnik skasowania miejsca.
+func firstCheckin(q queryRower, reservationID, seat int) (at time.Time, deviceID string, err error) {
+	err = q.QueryRow(
+		"SELECT created_at, device_id FROM checkins WHERE reservation_id=$1 AND seat=$2", reservationID, seat,
+	).Scan(&at, &deviceID)
+	return at.UTC(), deviceID, err
+}
+
+// recordDuplicate zapisuje próbę ponownego wejścia na skasowany bilet
+// (do raportu CheckinDuplicates).
+func recordDuplicate(db interface {
+	Exec(string, ...interface{}) (sql.Result, error)
+}, eventID int, c models.Checkin, deviceID string, scannedAt time.Time) error {
+	_, err := db.Exec(
+		`INSERT INTO checkin_duplicates(reservation_id, seat, event_id, device_id, scanned_at)
+		 VALUES($1, $2, $3, $4, $5)`,
+		c.ReservationID, c.Seat, eventID, deviceID, scannedAt,
+	)
+	return err
+}
+
+// openForCheckin mówi, czy wydarzenie przyjmuje gości (nie jest szkicem ani odwołane).
+func openForCheckin(q queryRower, eventID int) (bool, error) {
+	var status string
+	if err := q.QueryRow("SELECT status FROM events WHERE id=$1", eventID).Scan(&status); err != nil {
+		return false, err
+	}
+	return status != models.EventCancelled && status != models.EventDraft, nil
+}
+
+// countCheckins zwraca liczbę skasowanych miejsc rezerwacji.
+func countCheckins(db queryRower, reservationID int) (int, error) {
+	var n int
+	err := db.QueryRow("SELECT COUNT(*) FROM checkins WHERE reservation_id=$1", reservationID).Scan(&n)
+	return n, err
+}
+
+// CheckIn kasuje bilet przy wejściu (właściciel wydarzenia lub admin): kod
+// musi mieć poprawny podpis i dotyczyć tego wydarzenia. Każde miejsce można
+// skasować tylko raz – także gdy ten sam bilet zeskanują jednocześnie dwa
+// czytniki (decyduje klucz główny checkins). Ponowne skanowanie daje 409
+// z czasem pierwszego wejścia i trafia do raportu duplikatów.
+func CheckIn(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// 1) Uprawnienia i body
+		eventID, actorID, ok := ownedEvent(w, r, db)
+		if !ok {
+			return
+		}
+		var req checkinRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+
+		// 2) Kod biletu
+		c, ok := scannedTicket(w, db, eventID, req.Code)
+		if !ok {
+			return
+		}
+		if open, err := openForCheckin(db, eventID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		} else if !open {
+			http.Error(w, "Event is not open for check-in", http.StatusConflict)
+			return
+		}
+
+		// 3) Skasowanie – przy konflikcie klucza wygrywa pierwszy skan
+		now := time.Now().UTC()
+		res, err := db.Exec(
+			`INSERT INTO checkins(reservation_id, seat, event_id, checked_in_by, device_id, created_at)
+			 VALUES($1, $2, $3, $4, $5, $6)
+			 ON CONFLICT (reservation_id, seat) DO NOTHING`,
+			c.ReservationID, c.Seat, eventID, actorID, req.DeviceID, now,
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			c.CheckedInAt, c.DeviceID, _ = firstCheckin(db, c.ReservationID, c.Seat)
+			if err := recordDuplicate(db, eventID, c, req.DeviceID, now); err != nil {
+				log.Printf("record duplicate check-in of reservation %d: %v", c.ReservationID, err)
+			}
+			if c.CheckedIn, err = countCheckins(db, c.ReservationID); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "Ticket already checked in",
+				"checkin": c,
+			})
+			return
+		}
+
+		c.CheckedInAt, c.DeviceID = now, req.DeviceID
+		if c.CheckedIn, err = countCheckins(db, c.ReservationID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(c)
+	}
+}
+
+// UndoCheckIn cofa skasowanie biletu (pomyłka przy wejściu), tak by można
+// go było zeskanować ponownie.
+func UndoCheckIn(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		eventID, _, ok := ownedEvent(w, r, db)
+		if !ok {
+			return
+		}
+		var req checkinRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		c, ok := scannedTicket(w, db, eventID, req.Code)
+		if !ok {
+			return
+		}
+
+		res, err := db.Exec("DELETE FROM checkins WHERE reservation_id=$1 AND seat=$2", c.ReservationID, c.Seat)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			http.Error(w, "Ticket is not checked in", http.StatusConflict)
+			return
+		}
+		if c.CheckedIn, err = countCheckins(db, c.ReservationID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(c)
+	}
+}
+
+// CheckinStats zwraca licznik wejść: ile miejsc skasowano, ile jest
+// wszystkich i z ilu rezerwacji weszła już choć jedna osoba.
+func CheckinStats(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		eventID, _, ok := ownedEvent(w, r, db)
+		if !ok {
+			return
+		}
+		var checkedIn, reservationsIn, tickets, reservations int
+		if err := db.QueryRow(
+			"SELECT COUNT(*), COUNT(DISTINCT reservation_id) FROM checkins WHERE event_id=$1", eventID,
+		).Scan(&checkedIn, &reservationsIn); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := db.QueryRow(
+			"SELECT COALESCE(SUM(tickets), 0), COUNT(*) FROM reservations WHERE event_id=$1", eventID,
+		).Scan(&tickets, &reservations); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(map[string]int{
+			"checked_in":              checkedIn,
+			"tickets":                 tickets,
+			"reservations_checked_in": reservationsIn,
+			"reservations":            reservations,
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// File: internal/handlers/checkins_test.go
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/bartbaranski/eventhub/internal/ticket"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestCheckIn(t *testing.T) {
+	db := newEventDB(t)
+	auth.Init("test-secret")
+	key := ticket.Key([]byte("test-secret"))
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	eventID := insertEvent(t, db, "published", 100)
+	db.Exec("INSERT INTO users(id, email, password_hash, role) VALUES(5, 'ola@example.com', 'x', 'participant')")
+	res, _ := db.Exec("INSERT INTO reservations(user_id, event_id, tickets) VALUES(5, $1, 2)", eventID)
+	id64, _ := res.LastInsertId()
+	rid := int(id64)
+	code := func(event, seat int) string {
+		return fmt.Sprintf(`{"code":%q}`, ticket.Sign(key, ticket.Ticket{Reservation: rid, Event: event, Seat: seat}))
+	}
+
+	// Dwa czytniki skanują ten sam bilet jednocześnie – wchodzi tylko jedna osoba
+	var wg sync.WaitGroup
+	codes := make([]int, 5)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = call(handlers.CheckIn(db), "POST", "/events/x/checkin", owner, eventID, code(eventID, 1)).Code
+		}(i)
+	}
+	wg.Wait()
+	created := 0
+	for _, c := range codes {
+		if c == http.StatusCreated {
+			created++
+		} else if c != http.StatusConflict {
+			t.Fatalf("concurrent check-in: unexpected status %d", c)
+		}
+	}
+	if created != 1 {
+		t.Fatalf("expected exactly one successful check-in, got %d (%v)", created, codes)
+	}
+
+	// Drugie miejsce tej samej rezerwacji to osobny bilet
+	w := call(handlers.CheckIn(db), "POST", "/events/x/checkin", owner, eventID, code(eventID, 2))
+	var c models.Checkin
+	json.Unmarshal(w.Body.Bytes(), &c)
+	if w.Code != http.StatusCreated || c.Email != "ola@example.com" || c.Tickets != 2 || c.CheckedIn != 2 || c.Seat != 2 {
+		t.Fatalf("second seat: unexpected %d %s", w.Code, w.Body.String())
+	}
+
+	// Ponowny skan: 409 z czasem pierwszego wejścia
+	w = call(handlers.CheckIn(db), "POST", "/events/x/checkin", owner, eventID, code(eventID, 2))
+	var dup struct {
+		Checkin models.Checkin `json:"checkin"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &dup)
+	if w.Code != http.StatusConflict || dup.Checkin.Seat != 2 || dup.Checkin.CheckedInAt.IsZero() {
+		t.Fatalf("repeated scan: unexpected %d %s", w.Code, w.Body.String())
+	}
+
+	// Błędne kody
+	other := insertEvent(t, db, "published", 10)
+	for name, body := range map[string]string{
+		"forged":        `{"code":"EH1.1.1.1.AAAAAAAAAAAAAAAAAAAAAA"}`,
+		"other event":   code(other, 1),
+		"missing code":  `{}`,
+		"seat too high": code(eventID, 3),
+	} {
+		want := http.StatusBadRequest
+		if name == "seat too high" {
+			want = http.StatusNotFound
+		}
+		if w := call(handlers.CheckIn(db), "POST", "/events/x/checkin", owner, eventID, body); w.Code != want {
+			t.Fatalf("%s: expected %d, got %d %s", name, want, w.Code, w.Body.String())
+		}
+	}
+	stranger := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(2), "role": "organizer"})
+	if w := call(handlers.CheckIn(db), "POST", "/events/x/checkin", stranger, eventID, code(eventID, 1)); w.Code != http.StatusForbidden {
+		t.Fatalf("other organizer: expected 403, got %d", w.Code)
+	}
+
+	// Licznik i cofnięcie
+	stats := func() map[string]int {
+		var s map[string]int
+		json.Unmarshal(call(handlers.CheckinStats(db), "GET", "/events/x/checkin", owner, eventID, "").Body.Bytes(), &s)
+		return s
+	}
+	if s := stats(); s["checked_in"] != 2 || s["tickets"] != 2 || s["reservations_checked_in"] != 1 {
+		t.Fatalf("stats: unexpected %v", s)
+	}
+	if w := call(handlers.UndoCheckIn(db), "POST", "/events/x/checkin/undo", owner, eventID, code(eventID, 1)); w.Code != http.StatusOK {
+		t.Fatalf("undo: expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	if w := call(handlers.UndoCheckIn(db), "POST", "/events/x/checkin/undo", owner, eventID, code(eventID, 1)); w.Code != http.StatusConflict {
+		t.Fatalf("second undo: expected 409, got %d", w.Code)
+	}
+	if s := stats(); s["checked_in"] != 1 {
+		t.Fatalf("stats after undo: unexpected %v", s)
+	}
+	if w := call(handlers.CheckIn(db), "POST", "/events/x/checkin", owner, eventID, code(eventID, 1)); w.Code != http.StatusCreated {
+		t.Fatalf("check-in after undo: expected 201, got %d", w.Code)
+	}
+
+	// Błąd bazy to nie brak biletu – ani przy skanie, ani przy synchronizacji
+	if _, err := db.Exec("ALTER TABLE users RENAME TO users_old"); err != nil {
+		t.Fatal(err)
+	}
+	if w := call(handlers.CheckIn(db), "POST", "/events/x/checkin", owner, eventID, code(eventID, 2)); w.Code != http.StatusInternalServerError {
+		t.Fatalf("lookup failure: expected 500, got %d %s", w.Code, w.Body.String())
+	}
+	scan := fmt.Sprintf(`{"device_id":"gate-a","checkins":[{"code":%q,"scanned_at":%q}]}`,
+		ticket.Sign(key, ticket.Ticket{Reservation: rid, Event: eventID, Seat: 2}), time.Now().UTC().Add(-time.Minute).Format(time.RFC3339))
+	if w := call(handlers.SyncCheckins(db), "POST", "/events/x/checkin/sync", owner, eventID, scan); w.Code != http.StatusInternalServerError {
+		t.Fatalf("sync lookup failure: expected 500, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -10,15 +10,146 @@ import (
 
 	"github.com/bartbaranski/eventhub/internal/auth"
 	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/bartbaranski/eventhub/internal/recurrence"
+	"github.com/bartbaranski/eventhub/internal/validate"
 	"github.com/gorilla/mux"
 )
 
-// ListEvents zwraca wszystkie wydarzenia.
+// eventRequest to body żądań tworzenia i aktualizacji wydarzenia.
+type eventRequest struct {
+	Title       string `json:"title" validate:"required,max=255"`
+	Description string `json:"description" validate:"max=5000"`
+	DateTime    string `json:"date_time" validate:"required,datetime"` // RFC 3339 albo "YYYY-MM-DDTHH:MM" w strefie timezone
+	EndDateTime string `json:"end_date_time" validate:"datetime"`
+	Timezone    string `json:"timezone" validate:"max=64,timezone"` // IANA, np. "Europe/Warsaw"
+	Capacity    int    `json:"capacity" validate:"min=1,max=100000"`
+	ImageURL    string `json:"image_url" validate:"max=2048,url=http|https"`
+	// Status przy tworzeniu: "draft" (domyślnie) albo "published"; przy aktualizacji ignorowany.
+	Status  string `json:"status" validate:"oneof=draft|published"`
+	VenueID *int   `json:"venue_id" validate:"min=1"` // miejsce (GET /venues); capacity nie może go przekraczać
+	// Category to jedna z models.EventCategories; Tags – dowolne etykiety (patrz normalizeTag).
+	Category string   `json:"category" validate:"oneof=concert|workshop|conference|meetup|sport|theatre|exhibition|festival|other"`
+	Tags     []string `json:"tags" validate:"max=10"`
+}
+
+// eventColumns to kolumny wydarzenia w kolejności oczekiwanej przez scanEvent.
+const eventColumns = `id, title, COALESCE(description, ''), date, capacity, organizer_id,
+	COALESCE(image_url, ''), status, version, series_id, recurrence_id, detached,
+	end_date, COALESCE(timezone, 'UTC'), venue_id, COALESCE(category, ''), sequence, updated_at,
+	transfers_enabled, max_tickets_per_user, require_verified_email`
+
+// rowScanner to wspólny interfejs *sql.Row i *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanEvent czyta wiersz wybrany przez eventColumns i uzupełnia daty lokalne.
+func scanEvent(row rowScanner, e *models.Event) error {
+	var seriesID, venueID, maxTickets sql.NullInt64
+	var recurrenceID, endDate, updatedAt sql.NullTime
+	err := row.Scan(
+		&e.ID,
+		&e.Title,
+		&e.Description,
+		&e.Date,
+		&e.Capacity,
+		&e.OrganizerID,
+		&e.ImageURL,
+		&e.Status,
+		&e.Version,
+		&seriesID,
+		&recurrenceID,
+		&e.Detached,
+		&endDate,
+		&e.Timezone,
+		&venueID,
+		&e.Category,
+		&e.Sequence,
+		&updatedAt,
+		&e.TransfersEnabled,
+		&maxTickets,
+		&e.RequireVerifiedEmail,
+	)
+	if err != nil {
+		return err
+	}
+	if maxTickets.Valid {
+		n := int(maxTickets.Int64)
+		e.MaxTicketsPerUser = &n
+	}
+	if endDate.Valid {
+		end := endDate.Time
+		e.EndDate = &end
+	}
+	if seriesID.Valid {
+		id := int(seriesID.Int64)
+		e.SeriesID = &id
+	}
+	if recurrenceID.Valid {
+		t := recurrenceID.Time.UTC()
+		e.RecurrenceID = &t
+	}
+	if venueID.Valid {
+		id := int(venueID.Int64)
+		e.VenueID = &id
+	}
+	if updatedAt.Valid {
+		t := updatedAt.Time.UTC()
+		e.UpdatedAt = &t
+	}
+	e.Tags = []string{} // etykiety są w event_tags – patrz attachTags
+	localize(e)
+	return nil
+}
+
+// ListEvents zwraca wydarzenia widoczne publicznie (bez szkiców).
+// Zalogowany organizator widzi dodatkowo własne szkice.
+// Opcjonalne parametry zawężają listę: ?status=, ?category=, ?tag= (można
+// powtórzyć – wydarzenie musi mieć wszystkie etykiety) oraz
+// ?near=lat,lon&radius_km= (wydarzenia w pobliżu, posortowane po odległości).
+// Z ?facets=1 odpowiedź to {events, facets} – z licznościami kategorii
+// i etykiet; każdy facet liczy wydarzenia spełniające wszystkie filtry poza
+// własnym (np. kategorie przy ?category= pokazują też pozostałe kategorie).
 func ListEvents(db *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		rows, err := db.Query(
-			"SELECT id, title, description, date, capacity, organizer_id, image_url FROM events",
-		)
+		viewerID := 0
+		if claims, ok := auth.FromContext(r.Context()); ok {
+			viewerID = int(claims["id"].(float64))
+		}
+		near, ok := parseNear(w, r)
+		if !ok {
+			return
+		}
+
+		q := r.URL.Query()
+		where := "deleted_at IS NULL AND (status <> $1 OR organizer_id = $2)"
+		args := []interface{}{models.EventDraft, viewerID}
+		if st := q.Get("status"); st != "" {
+			args = append(args, st)
+			where += " AND status = $" + strconv.Itoa(len(args))
+		}
+		// Kategorię i etykiety filtrujemy po pobraniu, bo facety liczą się
+		// także dla wydarzeń odrzuconych tylko przez własny filtr
+		category := q.Get("category")
+		var tags []string
+		for _, t := range q["tag"] {
+			tag, _ := normalizeTag(t)
+			tags = append(tags, tag)
+		}
+
+		// W pobliżu: najpierw prostokąt w SQL, dokładny promień w filterNear
+		var venues map[int]models.Venue
+		if near != nil {
+			var err error
+			if venues, err = venuesInBox(db, near); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			var cond string
+			cond, args = near.boxFilter(args)
+			where += " AND venue_id IN (SELECT id FROM venues WHERE " + cond + ")"
+		}
+		rows, err := db.Query("SELECT "+eventColumns+" FROM events WHERE "+where+" ORDER BY date", args...)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -28,35 +159,42 @@ func ListEvents(db *sql.DB) http.HandlerFunc {
 		events := []models.Event{}
 		for rows.Next() {
 			var e models.Event
-			if err := rows.Scan(
-				&e.ID,
-				&e.Title,
-				&e.Description,
-				&e.Date,
-				&e.Capacity,
-				&e.OrganizerID,
-				&e.ImageURL,
-			); err != nil {
+			if err := scanEvent(rows, &e); err != nil {
 				continue
 			}
 			events = append(events, e)
 		}
+		rows.Close()
+		if err := attachTags(db, events,
+			"SELECT event_id, tag FROM event_tags WHERE event_id IN (SELECT id FROM events WHERE "+where+") ORDER BY tag", args...,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := attachImages(db, events,
+			"SELECT event_id, variant, url, width, height FROM event_images WHERE event_id IN (SELECT id FROM events WHERE "+where+")", args...,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if near != nil {
+			events = filterNear(events, venues, near)
+		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(events)
+		if q.Get("facets") == "1" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"events": filterEvents(events, category, tags),
+				"facets": eventFacets(events, category, tags),
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(filterEvents(events, category, tags))
 	}
 }
 
 // CreateEvent tworzy nowe wydarzenie (tylko organizator).
 func CreateEvent(db *sql.DB) http.HandlerFunc {
-	type request struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		DateTime    string `json:"date_time"` // spodziewamy się "YYYY-MM-DDTHH:MM"
-		Capacity    int    `json:"capacity"`
-		ImageURL    string `json:"image_url"`
-	}
-
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
@@ -68,27 +206,34 @@ func CreateEvent(db *sql.DB) http.HandlerFunc {
 		}
 		organizerID := int(claims["id"].(float64))
 
-		// 2) Dekodowanie requestu
-		var req request
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		// 2) Dekodowanie i walidacja requestu
+		var req eventRequest
+		if !decodeBody(w, r, &req) {
+			return
+		}
+		if errs := newEventErrors(req); errs != nil {
+			writeFieldErrors(w, errs)
 			return
 		}
 
-		// 3) Parsowanie daty+godziny "YYYY-MM-DDTHH:MM" na time.Time
-		parsedDateTime, err := time.Parse("2006-01-02T15:04", req.DateTime)
-		if err != nil {
-			http.Error(w, "Invalid datetime format, use YYYY-MM-DDTHH:MM", http.StatusBadRequest)
+		// 3) Strefa, daty, miejsce i etykiety
+		ev, errs := prepareEvent(db, req)
+		if errs != nil {
+			writeFieldErrors(w, errs)
 			return
 		}
 
-		// 4) Wstawienie do bazy; Postgres przyjmie parsedDateTime jako TIMESTAMP
-		var newID int
-		err = db.QueryRow(
-			`INSERT INTO events(title, description, date, capacity, organizer_id, image_url)
-			 VALUES($1, $2, $3, $4, $5, $6) RETURNING id`,
-			req.Title, req.Description, parsedDateTime, req.Capacity, organizerID, req.ImageURL,
-		).Scan(&newID)
+		// 4) Wstawienie do bazy (razem z etykietami)
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		newID, err := insertNewEvent(tx, organizerID, ev)
+		if err == nil {
+			err = tx.Commit()
+		}
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -100,41 +245,120 @@ func CreateEvent(db *sql.DB) http.HandlerFunc {
 	}
 }
 
-// GetEvent zwraca pojedyncze wydarzenie po ID.
+// newEventErrors sprawdza body nowego wydarzenia: tagi eventRequest oraz
+// termin w przyszłości. Przy aktualizacji termin sprawdza dopiero eventTimes,
+// i to tylko zmieniony – PUT wydarzenia, które już trwa, ma przejść.
+func newEventErrors(req eventRequest) validate.Errors {
+	errs, _ := validate.Struct(&req).(validate.Errors)
+	for _, fe := range errs {
+		if fe.Field == "date_time" {
+			return errs
+		}
+	}
+	date := struct {
+		DateTime string `json:"date_time" validate:"future"`
+	}{req.DateTime}
+	if err := validate.Struct(&date); err != nil {
+		errs = append(errs, err.(validate.Errors)...)
+	}
+	return errs
+}
+
+// newEvent to sprawdzone dane nowego wydarzenia (CreateEvent, import CSV).
+type newEvent struct {
+	eventRequest
+	tz     string
+	start  time.Time
+	end    *time.Time
+	tags   []string
+	status string
+}
+
+// prepareEvent sprawdza to, czego nie obejmują tagi validate żądania:
+// daty w strefie (bez strefy – czas lokalny w timezone), miejsce i etykiety.
+// Nowe wydarzenie jest szkicem, chyba że organizator od razu je publikuje.
+func prepareEvent(q queryRower, req eventRequest) (newEvent, validate.Errors) {
+	ev := newEvent{eventRequest: req, tz: req.Timezone, status: req.Status}
+	if ev.tz == "" {
+		ev.tz = defaultTimezone
+	}
+	if ev.status == "" {
+		ev.status = models.EventDraft
+	}
+	var errs validate.Errors
+	var fe *validate.FieldError
+	if ev.start, ev.end, fe = parseEventTimes(req.DateTime, req.EndDateTime, ev.tz, time.Time{}); fe != nil {
+		errs = append(errs, *fe)
+	}
+	if fe = venueError(q, req.VenueID, req.Capacity); fe != nil {
+		errs = append(errs, *fe)
+	}
+	if ev.tags, fe = parseTags(req.Tags); fe != nil {
+		errs = append(errs, *fe)
+	}
+	return ev, errs
+}
+
+// insertNewEvent wstawia wydarzenie razem z etykietami; daty zapisujemy
+// w UTC, strefę osobno.
+func insertNewEvent(tx *sql.Tx, organizerID int, ev newEvent) (int, error) {
+	var id int
+	err := tx.QueryRow(
+		`INSERT INTO events(title, description, date, end_date, timezone, capacity, organizer_id, image_url, status, venue_id, category)
+		 VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`,
+		ev.Title, ev.Description, ev.start, ev.end, ev.tz, ev.Capacity, organizerID, ev.ImageURL, ev.status, ev.VenueID, ev.Category,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, setEventTags(tx, id, ev.tags)
+}
+
+// GetEvent zwraca pojedyncze wydarzenie po ID (razem z miejscem i wariantami obrazu).
+// Szkic widzi tylko jego właściciel.
 func GetEvent(db *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, _ := strconv.Atoi(mux.Vars(r)["id"])
 		var e models.Event
-		err := db.QueryRow(
-			"SELECT id, title, description, date, capacity, organizer_id, image_url FROM events WHERE id=$1", id,
-		).Scan(
-			&e.ID,
-			&e.Title,
-			&e.Description,
-			&e.Date,
-			&e.Capacity,
-			&e.OrganizerID,
-			&e.ImageURL,
-		)
-		if err != nil {
+		err := scanEvent(db.QueryRow(
+			"SELECT "+eventColumns+" FROM events WHERE id=$1 AND deleted_at IS NULL", id,
+		), &e)
+		if err != nil || (e.Status == models.EventDraft && !isOwner(r, e.OrganizerID)) {
 			http.Error(w, "Not found", http.StatusNotFound)
 			return
 		}
+		if err := loadEventTags(db, &e); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := loadEventImages(db, &e); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if e.VenueID != nil {
+			var v models.Venue
+			if err := scanVenue(db.QueryRow("SELECT "+venueColumns+" FROM venues WHERE id=$1", *e.VenueID), &v); err == nil {
+				e.Venue = &v
+			}
+		}
 		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", eventETag(e.Version))
 		json.NewEncoder(w).Encode(e)
 	}
 }
 
-// UpdateEvent aktualizuje istniejące wydarzenie (tylko właściciel).
-func UpdateEvent(db *sql.DB) http.HandlerFunc {
-	type request struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		DateTime    string `json:"date_time"` // spodziewamy się "YYYY-MM-DDTHH:MM"
-		Capacity    int    `json:"capacity"`
-		ImageURL    string `json:"image_url"`
+// isOwner sprawdza, czy zalogowany użytkownik jest organizatorem o danym ID.
+func isOwner(r *http.Request, organizerID int) bool {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok || claims["role"] != "organizer" {
+		return false
 	}
+	id, ok := claims["id"].(float64)
+	return ok && int(id) == organizerID
+}
 
+// UpdateEvent aktualizuje istniejące wydarzenie (tylko właściciel).
+func UpdateEvent(db *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
@@ -150,47 +374,235 @@ func UpdateEvent(db *sql.DB) http.HandlerFunc {
 		id, _ := strconv.Atoi(mux.Vars(r)["id"])
 
 		// 3) Dekodowanie requestu
-		var req request
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		var req eventRequest
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
-		// 4) Parsowanie daty+godziny
-		parsedDateTime, err := time.Parse("2006-01-02T15:04", req.DateTime)
+		// 4) Transakcja: bieżący stan, UPDATE zmienionych pól i zapis rewizji razem
+		tx, err := db.Begin()
 		if err != nil {
-			http.Error(w, "Invalid datetime format, use YYYY-MM-DDTHH:MM", http.StatusBadRequest)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		defer tx.Rollback()
 
 		// 5) Sprawdź, czy organizator jest właścicielem
-		var owner int
-		if err := db.QueryRow(
-			"SELECT organizer_id FROM events WHERE id=$1", id,
-		).Scan(&owner); err != nil || owner != organizerID {
+		var current models.Event
+		if err := scanEvent(tx.QueryRow(
+			"SELECT "+eventColumns+" FROM events WHERE id=$1 AND deleted_at IS NULL", id,
+		), &current); err != nil || current.OrganizerID != organizerID {
 			http.Error(w, "Forbidden or not found", http.StatusForbidden)
 			return
 		}
 
-		// 6) Wykonaj UPDATE
-		_, err = db.Exec(
-			`UPDATE events
-			 SET title=$1, description=$2, date=$3, capacity=$4, image_url=$5
-			 WHERE id=$6`,
-			req.Title, req.Description, parsedDateTime, req.Capacity, req.ImageURL, id,
-		)
+		if err := loadEventTags(tx, &current); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// 6) Nadpisać można tylko wersję, którą klient widział (If-Match)
+		if !checkIfMatch(w, r, current.Version, true) {
+			return
+		}
+		scope, ok := seriesScope(w, r, current)
+		if !ok {
+			return
+		}
+
+		// 7) Daty; bez podanej strefy wydarzenie zostaje w dotychczasowej
+		tz := req.Timezone
+		if tz == "" {
+			tz = current.Timezone
+		}
+		start, end, ok := eventTimes(w, req.DateTime, req.EndDateTime, tz, current.Date)
+		if !ok || !checkVenue(w, tx, req.VenueID, req.Capacity) {
+			return
+		}
+		tags, ok := normalizeTags(w, req.Tags)
+		if !ok {
+			return
+		}
+
+		// 8) Wykonaj UPDATE i zapisz rewizję
+		updated := current
+		updated.Title = req.Title
+		updated.Description = req.Description
+		updated.Date = start
+		updated.EndDate = end
+		updated.Timezone = tz
+		updated.Capacity = req.Capacity
+		updated.ImageURL = req.ImageURL
+		updated.VenueID = req.VenueID
+		updated.Category = req.Category
+		updated.Tags = tags
+		changes := diffSnapshots(eventSnapshot(current), eventSnapshot(updated))
+		version, err := saveEventChanges(tx, id, organizerID, current.Version, changes, nil)
 		if err != nil {
+			writeSaveError(w, err)
+			return
+		}
+
+		// 9) Wystąpienie serii: zmiana tylko tego, kolejnych albo wszystkich (?scope=)
+		if err := applySeriesChanges(tx, current, changes, scope, organizerID); err != nil {
+			writeSaveError(w, err)
+			return
+		}
+		if err := tx.Commit(); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
+		w.Header().Set("ETag", eventETag(version))
 		w.WriteHeader(http.StatusOK)
 	}
 }
 
-// DeleteEvent usuwa wydarzenie (tylko właściciel).
-// DeleteEvent usuwa wydarzenie (tylko właściciel).
-// Najpierw usuwa powiązane rezerwacje, by uniknąć błędu FK.
+// eventPatchRequest to body PATCH – pominięte pola zostają bez zmian.
+type eventPatchRequest struct {
+	Title       *string   `json:"title" validate:"notblank,max=255"`
+	Description *string   `json:"description" validate:"max=5000"`
+	DateTime    *string   `json:"date_time" validate:"notblank,datetime"`
+	EndDateTime *string   `json:"end_date_time" validate:"datetime"` // "" usuwa koniec
+	Timezone    *string   `json:"timezone" validate:"notblank,max=64,timezone"`
+	Capacity    *int      `json:"capacity" validate:"min=1,max=100000"`
+	ImageURL    *string   `json:"image_url" validate:"max=2048,url=http|https"`
+	VenueID     *int      `json:"venue_id" validate:"min=0"`                                                                            // 0 usuwa miejsce
+	Category    *string   `json:"category" validate:"oneof=concert|workshop|conference|meetup|sport|theatre|exhibition|festival|other"` // "" usuwa kategorię
+	Tags        *[]string `json:"tags" validate:"max=10"`
+}
+
+// PatchEvent aktualizuje tylko przesłane pola wydarzenia (tylko właściciel).
+// If-Match jest opcjonalny, ale jeśli go podano – musi pasować do wersji.
+func PatchEvent(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// 1) Uwierzytelnienie
+		claims, ok := auth.FromContext(r.Context())
+		if !ok || claims["role"] != "organizer" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		organizerID := int(claims["id"].(float64))
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		// 2) Dekodowanie requestu
+		var req eventPatchRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		// 3) Bieżący stan, właściciel i wersja
+		var current models.Event
+		if err := scanEvent(tx.QueryRow(
+			"SELECT "+eventColumns+" FROM events WHERE id=$1 AND deleted_at IS NULL", id,
+		), &current); err != nil || current.OrganizerID != organizerID {
+			http.Error(w, "Forbidden or not found", http.StatusForbidden)
+			return
+		}
+		if err := loadEventTags(tx, &current); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !checkIfMatch(w, r, current.Version, false) {
+			return
+		}
+		scope, ok := seriesScope(w, r, current)
+		if !ok {
+			return
+		}
+
+		// 4) Nałożenie przesłanych pól
+		updated := current
+		if req.Title != nil {
+			updated.Title = *req.Title
+		}
+		if req.Description != nil {
+			updated.Description = *req.Description
+		}
+		if req.Timezone != nil {
+			updated.Timezone = *req.Timezone
+		}
+		if req.DateTime != nil || req.EndDateTime != nil {
+			// Brakującą datę bierzemy z bieżącego stanu (jako chwilę, RFC 3339)
+			dateTime, endDateTime := current.Date.Format(time.RFC3339), ""
+			if current.EndDate != nil {
+				endDateTime = current.EndDate.Format(time.RFC3339)
+			}
+			if req.DateTime != nil {
+				dateTime = *req.DateTime
+			}
+			if req.EndDateTime != nil {
+				endDateTime = *req.EndDateTime
+			}
+			start, end, ok := eventTimes(w, dateTime, endDateTime, updated.Timezone, current.Date)
+			if !ok {
+				return
+			}
+			updated.Date, updated.EndDate = start, end
+		}
+		if req.Capacity != nil {
+			updated.Capacity = *req.Capacity
+		}
+		if req.ImageURL != nil {
+			updated.ImageURL = *req.ImageURL
+		}
+		if req.VenueID != nil {
+			updated.VenueID = req.VenueID
+			if *req.VenueID == 0 {
+				updated.VenueID = nil
+			}
+		}
+		if (req.VenueID != nil || req.Capacity != nil) && !checkVenue(w, tx, updated.VenueID, updated.Capacity) {
+			return
+		}
+		if req.Category != nil {
+			updated.Category = *req.Category
+		}
+		if req.Tags != nil {
+			if updated.Tags, ok = normalizeTags(w, *req.Tags); !ok {
+				return
+			}
+		}
+
+		// 5) Zapis zmian z rewizją
+		changes := diffSnapshots(eventSnapshot(current), eventSnapshot(updated))
+		updated.Version, err = saveEventChanges(tx, id, organizerID, current.Version, changes, nil)
+		if err != nil {
+			writeSaveError(w, err)
+			return
+		}
+		if err := applySeriesChanges(tx, current, changes, scope, organizerID); err != nil {
+			writeSaveError(w, err)
+			return
+		}
+		if current.SeriesID != nil && len(changes) > 0 && scope == scopeThis {
+			updated.Detached = true
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		localize(&updated)
+		w.Header().Set("ETag", eventETag(updated.Version))
+		json.NewEncoder(w).Encode(updated)
+	}
+}
+
+// DeleteEvent usuwa wydarzenie (tylko właściciel) – miękko, przez ustawienie
+// deleted_at, więc można je przywrócić (RestoreEvent) w okresie retencji.
+// Wydarzenia z rezerwacjami trzeba najpierw odwołać (POST /events/{id}/cancel),
+// żeby uczestnicy mogli zostać powiadomieni. Dla wystąpienia serii ?scope=
+// pozwala usunąć też kolejne (following) albo wszystkie (all) wystąpienia.
 func DeleteEvent(db *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// 1) Uwierzytelnienie
@@ -204,27 +616,156 @@ func DeleteEvent(db *sql.DB) http.HandlerFunc {
 		// 2) Pobranie ID
 		id, _ := strconv.Atoi(mux.Vars(r)["id"])
 
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
 		// 3) Sprawdź właściciela
+		var current models.Event
+		if err := scanEvent(tx.QueryRow(
+			"SELECT "+eventColumns+" FROM events WHERE id=$1 AND deleted_at IS NULL", id,
+		), &current); err != nil || current.OrganizerID != organizerID {
+			http.Error(w, "Forbidden or not found", http.StatusForbidden)
+			return
+		}
+		if !checkIfMatch(w, r, current.Version, true) {
+			return
+		}
+		scope, ok := seriesScope(w, r, current)
+		if !ok {
+			return
+		}
+
+		// 4) Usuwane wydarzenia: to jedno albo część serii
+		targets := []models.Event{current}
+		var series models.EventSeries
+		if current.SeriesID != nil {
+			if err := scanSeries(tx.QueryRow(
+				"SELECT "+seriesColumns+" FROM event_series WHERE id=$1", *current.SeriesID,
+			), &series); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if scope == scopeFollowing && !current.RecurrenceID.After(series.Start) {
+				scope = scopeAll
+			}
+			from := time.Time{}
+			if scope == scopeFollowing {
+				from = *current.RecurrenceID
+			}
+			if scope != scopeThis {
+				if targets, err = seriesOccurrences(tx, series.ID, from); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+			}
+		}
+
+		// 5) Aktywnych wydarzeń z rezerwacjami nie usuwamy – trzeba je odwołać
+		for _, e := range targets {
+			var reservations int
+			if err := tx.QueryRow(
+				"SELECT COUNT(*) FROM reservations WHERE event_id=$1", e.ID,
+			).Scan(&reservations); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if reservations > 0 && e.Status != models.EventCancelled && e.Status != models.EventCompleted {
+				http.Error(w, "Event has reservations, cancel it instead", http.StatusConflict)
+				return
+			}
+		}
+
+		// 6) Miękkie usunięcie – rezerwacje i historia zostają
+		now := time.Now().UTC()
+		for _, e := range targets {
+			res, err := tx.Exec(
+				`UPDATE events SET deleted_at=$1, version=version+1, updated_at=$1
+				 WHERE id=$2 AND deleted_at IS NULL AND version=$3`, now, e.ID, e.Version,
+			)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if n, _ := res.RowsAffected(); n == 0 {
+				http.Error(w, "Event was modified concurrently", http.StatusPreconditionFailed)
+				return
+			}
+		}
+
+		// 7) Reguła serii: usunięty termin staje się wyjątkiem, a "following" ją skraca
+		if current.SeriesID != nil {
+			switch scope {
+			case scopeThis:
+				_, err = tx.Exec(
+					"UPDATE event_series SET exdates=$1 WHERE id=$2",
+					recurrence.FormatDates(append(series.ExDates, *current.RecurrenceID)), series.ID,
+				)
+			case scopeFollowing:
+				_, _, err = endSeriesBefore(tx, series, *current.RecurrenceID)
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// RestoreEvent przywraca miękko usunięte wydarzenie (właściciel lub admin),
+// o ile nie minął okres retencji – potem wydarzenie czeka już tylko na purge.
+func RestoreEvent(db *sql.DB, retention time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// 1) Uwierzytelnienie: organizator lub admin
+		claims, ok := auth.FromContext(r.Context())
+		if !ok || (claims["role"] != "organizer" && claims["role"] != "admin") {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		userID := int(claims["id"].(float64))
+
+		// 2) Pobranie usuniętego wydarzenia
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
 		var owner int
+		var deletedAt sql.NullTime
 		if err := db.QueryRow(
-			"SELECT organizer_id FROM events WHERE id=$1", id,
-		).Scan(&owner); err != nil || owner != organizerID {
+			"SELECT organizer_id, deleted_at FROM events WHERE id=$1", id,
+		).Scan(&owner, &deletedAt); err != nil {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		if claims["role"] != "admin" && owner != userID {
 			http.Error(w, "Forbidden or not found", http.StatusForbidden)
 			return
 		}
+		if !deletedAt.Valid {
+			http.Error(w, "Event is not deleted", http.StatusConflict)
+			return
+		}
 
-		// 4) Usuń powiązane rezerwacje z tabeli reservations
-		if _, err := db.Exec("DELETE FROM reservations WHERE event_id=$1", id); err != nil {
-			http.Error(w, "Error deleting related reservations", http.StatusInternalServerError)
+		// 3) Po okresie retencji przywrócenie nie jest już możliwe
+		if time.Since(deletedAt.Time) > retention {
+			http.Error(w, "Retention period has expired", http.StatusGone)
 			return
 		}
 
-		// 5) Usuń samo wydarzenie
-		if _, err := db.Exec("DELETE FROM events WHERE id=$1", id); err != nil {
+		if _, err := db.Exec(
+			"UPDATE events SET deleted_at=NULL, version=version+1, updated_at=$1 WHERE id=$2", time.Now().UTC(), id,
+		); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-
-		w.WriteHeader(http.StatusNoContent)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": id, "restored": true})
 	}
 }
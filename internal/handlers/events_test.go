@@ -7,35 +7,184 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/bartbaranski/eventhub/internal/auth"
 	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/bartbaranski/eventhub/internal/models"
 	"github.com/bartbaranski/eventhub/internal/storage"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
 )
 
-// newEventDB tworzy in-memory BD ze wszystkimi kolumnami, jakie wykorzystuje handler.
+// newEventDB tworzy in-memory BD ze wszystkimi kolumnami, jakie wykorzystują
+// handlery wydarzeń i rezerwacji.
 func newEventDB(t *testing.T) *sql.DB {
 	db := storage.NewTestDB()
-	schema := `
-    CREATE TABLE events (
+	stmts := []string{
+		`CREATE TABLE events (
       id           INTEGER PRIMARY KEY AUTOINCREMENT,
       title        TEXT    NOT NULL,
       description  TEXT,
       date         DATETIME NOT NULL,
       capacity     INTEGER NOT NULL,
       organizer_id INTEGER NOT NULL,
-      image_url    TEXT
-    );`
-	if _, err := db.Exec(schema); err != nil {
-		t.Fatalf("create events table: %v", err)
+      image_url    TEXT,
+      status       TEXT    NOT NULL DEFAULT 'draft',
+      deleted_at   DATETIME,
+      version      INTEGER NOT NULL DEFAULT 1,
+      updated_at   DATETIME,
+      series_id     INTEGER,
+      recurrence_id DATETIME,
+      detached      BOOLEAN NOT NULL DEFAULT FALSE,
+      end_date      DATETIME,
+      timezone      TEXT NOT NULL DEFAULT 'UTC',
+      venue_id      INTEGER,
+      category      TEXT,
+      sequence      INTEGER NOT NULL DEFAULT 0,
+      transfers_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+      max_tickets_per_user INTEGER,
+      require_verified_email BOOLEAN NOT NULL DEFAULT FALSE
+    );`,
+		`ALTER TABLE users ADD COLUMN calendar_token TEXT`,
+		`ALTER TABLE users ADD COLUMN email_verified_at DATETIME`,
+		`ALTER TABLE users ADD COLUMN email_verification_token TEXT`,
+		`ALTER TABLE users ADD COLUMN email_verification_expires_at DATETIME`,
+		`CREATE TABLE reservations (
+      id         INTEGER PRIMARY KEY AUTOINCREMENT,
+      user_id    INTEGER NOT NULL,
+      event_id   INTEGER NOT NULL,
+      tickets    INTEGER NOT NULL,
+      created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+      ticket_generation INTEGER NOT NULL DEFAULT 0,
+      tier_id    INTEGER
+    );`,
+		`CREATE TABLE event_tiers (
+      id                   INTEGER PRIMARY KEY AUTOINCREMENT,
+      event_id             INTEGER NOT NULL,
+      name                 TEXT    NOT NULL,
+      capacity             INTEGER NOT NULL,
+      max_tickets_per_user INTEGER,
+      UNIQUE (event_id, name)
+    );`,
+		`CREATE TABLE event_revisions (
+      id          INTEGER PRIMARY KEY AUTOINCREMENT,
+      event_id    INTEGER NOT NULL,
+      actor_id    INTEGER NOT NULL,
+      changes     TEXT    NOT NULL,
+      rollback_of INTEGER,
+      created_at  DATETIME
+    );`,
+		`CREATE TABLE event_series (
+      id           INTEGER PRIMARY KEY AUTOINCREMENT,
+      organizer_id INTEGER NOT NULL,
+      rrule        TEXT    NOT NULL,
+      dtstart      DATETIME NOT NULL,
+      exdates      TEXT    NOT NULL DEFAULT '',
+      title        TEXT    NOT NULL,
+      description  TEXT,
+      capacity     INTEGER NOT NULL,
+      image_url    TEXT,
+      status       TEXT    NOT NULL DEFAULT 'draft',
+      timezone     TEXT    NOT NULL DEFAULT 'UTC',
+      duration     INTEGER,
+      venue_id     INTEGER,
+      category     TEXT,
+      tags         TEXT
+    );`,
+		`CREATE TABLE venues (
+      id            INTEGER PRIMARY KEY AUTOINCREMENT,
+      organizer_id  INTEGER NOT NULL,
+      name          TEXT    NOT NULL,
+      address       TEXT    NOT NULL,
+      latitude      REAL    NOT NULL,
+      longitude     REAL    NOT NULL,
+      capacity      INTEGER NOT NULL DEFAULT 0,
+      accessibility TEXT    NOT NULL DEFAULT '{}'
+    );`,
+		`CREATE TABLE event_tags (
+      event_id INTEGER NOT NULL,
+      tag      TEXT    NOT NULL,
+      PRIMARY KEY (event_id, tag)
+    );`,
+		`CREATE TABLE event_images (
+      event_id    INTEGER  NOT NULL,
+      variant     TEXT     NOT NULL,
+      storage_key TEXT     NOT NULL,
+      url         TEXT     NOT NULL,
+      width       INTEGER  NOT NULL,
+      height      INTEGER  NOT NULL,
+      created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+      PRIMARY KEY (event_id, variant)
+    );`,
+		`CREATE TABLE event_sessions (
+      id        INTEGER PRIMARY KEY AUTOINCREMENT,
+      event_id  INTEGER  NOT NULL,
+      title     TEXT     NOT NULL,
+      speaker   TEXT,
+      room      TEXT,
+      starts_at DATETIME NOT NULL,
+      ends_at   DATETIME NOT NULL,
+      capacity  INTEGER,
+      signed_up INTEGER  NOT NULL DEFAULT 0
+    );`,
+		`CREATE TABLE session_signups (
+      session_id INTEGER  NOT NULL,
+      event_id   INTEGER  NOT NULL,
+      user_id    INTEGER  NOT NULL,
+      created_at DATETIME NOT NULL,
+      PRIMARY KEY (session_id, user_id)
+    );`,
+		`CREATE TABLE checkins (
+      reservation_id INTEGER  NOT NULL,
+      seat           INTEGER  NOT NULL,
+      event_id       INTEGER  NOT NULL,
+      checked_in_by  INTEGER  NOT NULL,
+      device_id      TEXT     NOT NULL DEFAULT '',
+      created_at     DATETIME NOT NULL,
+      PRIMARY KEY (reservation_id, seat)
+    );`,
+		`CREATE TABLE checkin_duplicates (
+      id             INTEGER PRIMARY KEY AUTOINCREMENT,
+      reservation_id INTEGER  NOT NULL,
+      seat           INTEGER  NOT NULL,
+      event_id       INTEGER  NOT NULL,
+      device_id      TEXT     NOT NULL DEFAULT '',
+      scanned_at     DATETIME NOT NULL
+    );`,
+		`CREATE TABLE reservation_transfers (
+      id             INTEGER PRIMARY KEY AUTOINCREMENT,
+      reservation_id INTEGER  NOT NULL,
+      event_id       INTEGER  NOT NULL,
+      from_user_id   INTEGER  NOT NULL,
+      to_email       TEXT     NOT NULL,
+      token          TEXT     NOT NULL UNIQUE,
+      status         TEXT     NOT NULL DEFAULT 'pending',
+      created_at     DATETIME NOT NULL,
+      expires_at     DATETIME NOT NULL,
+      accepted_by    INTEGER,
+      accepted_at    DATETIME
+    );`,
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatalf("create schema: %v", err)
+		}
 	}
 	return db
 }
 
+// futureDateTime zwraca datę za miesiąc w formacie YYYY-MM-DDTHH:MM.
+func futureDateTime() string {
+	return time.Now().AddDate(0, 1, 0).Format("2006-01-02T15:04")
+}
+
 func TestListEvents_Empty(t *testing.T) {
 	db := newEventDB(t)
 	h := handlers.ListEvents(db)
@@ -64,11 +213,11 @@ func TestCreateAndListEvents(t *testing.T) {
 	claims := jwt.MapClaims{"id": float64(1), "role": "organizer"}
 	ctx := auth.NewContext(context.Background(), claims)
 
-	// Payload z polem "date_time" w formacie YYYY-MM-DDTHH:MM
+	// Payload z polem "date_time" w formacie YYYY-MM-DDTHH:MM (data w przyszłości)
 	payload := map[string]interface{}{
 		"title":       "Tytuł",
 		"description": "Opis",
-		"date_time":   "2025-06-01T09:00", // dokładny format
+		"date_time":   futureDateTime(),
 		"capacity":    100,
 		"image_url":   "/images/test.jpg",
 	}
@@ -85,25 +234,323 @@ func TestCreateAndListEvents(t *testing.T) {
 	if w.Code != http.StatusCreated {
 		t.Fatalf("expected 201 Created, got %d; body=%s", w.Code, w.Body.String())
 	}
+	var created map[string]int
+	json.Unmarshal(w.Body.Bytes(), &created)
 
-	// Teraz GET /events
-	hList := handlers.ListEvents(db)
-	req2 := httptest.NewRequest("GET", "/events", nil)
-	w2 := httptest.NewRecorder()
-	hList(w2, req2)
+	// Szkic nie jest widoczny na publicznej liście
+	if ev := listEvents(t, db, context.Background()); len(ev) != 0 {
+		t.Fatalf("expected draft to be hidden, got %v", ev)
+	}
+	// ...ale właściciel go widzi
+	if ev := listEvents(t, db, ctx); len(ev) != 1 {
+		t.Fatalf("expected owner to see own draft, got %v", ev)
+	}
 
-	if w2.Code != http.StatusOK {
-		t.Fatalf("expected 200 OK on list, got %d", w2.Code)
+	// Publikacja
+	if w := doTransition(handlers.PublishEvent(db), ctx, created["id"]); w.Code != http.StatusOK {
+		t.Fatalf("publish: expected 200, got %d; body=%s", w.Code, w.Body.String())
+	}
+
+	// Teraz GET /events
+	ev := listEvents(t, db, context.Background())
+	if len(ev) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(ev))
+	}
+	if ev[0]["title"] != "Tytuł" || ev[0]["status"] != "published" {
+		t.Errorf("unexpected event %v", ev[0])
 	}
+}
 
+// listEvents wywołuje GET /events z podanym kontekstem (anonimowym lub z claims).
+func listEvents(t *testing.T, db *sql.DB, ctx context.Context) []map[string]interface{} {
+	t.Helper()
+	w := httptest.NewRecorder()
+	handlers.ListEvents(db)(w, httptest.NewRequest("GET", "/events", nil).WithContext(ctx))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK on list, got %d", w.Code)
+	}
 	var ev []map[string]interface{}
-	if err := json.Unmarshal(w2.Body.Bytes(), &ev); err != nil {
+	if err := json.Unmarshal(w.Body.Bytes(), &ev); err != nil {
 		t.Fatalf("unmarshal list: %v", err)
 	}
-	if len(ev) != 1 {
-		t.Fatalf("expected 1 event, got %d", len(ev))
+	return ev
+}
+
+func TestCreateEvent_ValidationErrors(t *testing.T) {
+	db := newEventDB(t)
+	h := handlers.CreateEvent(db)
+	ctx := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+
+	body := `{"title":"","date_time":"2001-01-01T10:00","capacity":-5,"image_url":"ftp://x/y.jpg"}`
+	req := httptest.NewRequest("POST", "/events", bytes.NewBufferString(body)).WithContext(ctx)
+	w := httptest.NewRecorder()
+	h(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d; body=%s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Errors []struct {
+			Field string `json:"field"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	got := map[string]bool{}
+	for _, e := range resp.Errors {
+		got[e.Field] = true
+	}
+	for _, f := range []string{"title", "date_time", "capacity", "image_url"} {
+		if !got[f] {
+			t.Errorf("expected error for field %q, got %v", f, resp.Errors)
+		}
+	}
+}
+
+func TestCreateEvent_UnknownField(t *testing.T) {
+	db := newEventDB(t)
+	h := handlers.CreateEvent(db)
+	ctx := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+
+	body := `{"title":"A","date_time":"` + futureDateTime() + `","capacity":5,"organizer_id":2}`
+	req := httptest.NewRequest("POST", "/events", bytes.NewBufferString(body)).WithContext(ctx)
+	w := httptest.NewRecorder()
+	h(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown field, got %d", w.Code)
+	}
+}
+
+// eventReq buduje żądanie z claims organizatora i zmienną {id} z routera.
+func eventReq(method string, ctx context.Context, id int) *http.Request {
+	req := httptest.NewRequest(method, "/events/"+strconv.Itoa(id), nil).WithContext(ctx)
+	return mux.SetURLVars(req, map[string]string{"id": strconv.Itoa(id)})
+}
+
+func TestDeleteEvent_SoftDeleteAndRestore(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	id := insertEvent(t, db, "cancelled", 10)
+	if _, err := db.Exec("INSERT INTO reservations(user_id, event_id, tickets) VALUES(7, $1, 2)", id); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	req := eventReq("DELETE", owner, id)
+	req.Header.Set("If-Match", `"1"`)
+	handlers.DeleteEvent(db)(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d (%s)", w.Code, w.Body.String())
 	}
-	if ev[0]["title"] != "Tytuł" {
-		t.Errorf("expected title %q, got %q", "Tytuł", ev[0]["title"])
+
+	// Usunięte wydarzenie znika z odczytów, ale rezerwacje zostają
+	w = httptest.NewRecorder()
+	handlers.GetEvent(db)(w, eventReq("GET", owner, id))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("get deleted: expected 404, got %d", w.Code)
+	}
+	if ev := listEvents(t, db, owner); len(ev) != 0 {
+		t.Fatalf("deleted event listed: %v", ev)
+	}
+	var n int
+	db.QueryRow("SELECT COUNT(*) FROM reservations WHERE event_id=$1", id).Scan(&n)
+	if n != 1 {
+		t.Fatalf("reservations must survive soft delete, got %d", n)
+	}
+
+	// Obcy organizator nie przywróci, właściciel tak
+	other := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(2), "role": "organizer"})
+	w = httptest.NewRecorder()
+	handlers.RestoreEvent(db, time.Hour)(w, eventReq("POST", other, id))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("foreign restore: expected 403, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	handlers.RestoreEvent(db, time.Hour)(w, eventReq("POST", owner, id))
+	if w.Code != http.StatusOK {
+		t.Fatalf("restore: expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	handlers.GetEvent(db)(w, eventReq("GET", owner, id))
+	if w.Code != http.StatusOK {
+		t.Fatalf("get restored: expected 200, got %d", w.Code)
+	}
+}
+
+func TestRestoreEvent_AfterRetention(t *testing.T) {
+	db := newEventDB(t)
+	admin := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(99), "role": "admin"})
+	id := insertEvent(t, db, "draft", 10)
+	db.Exec("UPDATE events SET deleted_at=$1 WHERE id=$2", time.Now().UTC().Add(-48*time.Hour), id)
+
+	w := httptest.NewRecorder()
+	handlers.RestoreEvent(db, 24*time.Hour)(w, eventReq("POST", admin, id))
+	if w.Code != http.StatusGone {
+		t.Fatalf("expected 410 after retention, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	handlers.RestoreEvent(db, 72*time.Hour)(w, eventReq("POST", admin, id))
+	if w.Code != http.StatusOK {
+		t.Fatalf("admin restore within retention: expected 200, got %d", w.Code)
+	}
+}
+
+func TestUpdateEvent_IfMatch(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	id := insertEvent(t, db, "published", 10)
+
+	// GET zwraca ETag z bieżącą wersją
+	w := httptest.NewRecorder()
+	handlers.GetEvent(db)(w, eventReq("GET", owner, id))
+	if etag := w.Header().Get("ETag"); etag != `"1"` {
+		t.Fatalf(`expected ETag "1", got %q`, etag)
+	}
+
+	put := func(ifMatch string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"title":"Nowy","description":"","date_time":%q,"capacity":10}`, futureDateTime())
+		req := mux.SetURLVars(
+			httptest.NewRequest("PUT", "/events/x", bytes.NewBufferString(body)).WithContext(owner),
+			map[string]string{"id": strconv.Itoa(id)},
+		)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		handlers.UpdateEvent(db)(w, req)
+		return w
+	}
+
+	if w := put(""); w.Code != http.StatusPreconditionRequired {
+		t.Fatalf("missing If-Match: expected 428, got %d", w.Code)
+	}
+	if w := put(`"1"`); w.Code != http.StatusOK || w.Header().Get("ETag") != `"2"` {
+		t.Fatalf("update: expected 200 with ETag \"2\", got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+	// Drugi klient z nieaktualną wersją dostaje 412 i aktualny ETag
+	w = put(`"1"`)
+	if w.Code != http.StatusPreconditionFailed || w.Header().Get("ETag") != `"2"` {
+		t.Fatalf("stale If-Match: expected 412 with ETag \"2\", got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestUpdateEvent_StartedEvent(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	id := insertEvent(t, db, "published", 10)
+	started := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	db.Exec("UPDATE events SET date=$1 WHERE id=$2", started, id)
+
+	put := func(version int, dateTime string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"title":"Poprawka","description":"","date_time":%q,"capacity":12}`, dateTime)
+		req := mux.SetURLVars(
+			httptest.NewRequest("PUT", "/events/x", bytes.NewBufferString(body)).WithContext(owner),
+			map[string]string{"id": strconv.Itoa(id)},
+		)
+		req.Header.Set("If-Match", `"`+strconv.Itoa(version)+`"`)
+		w := httptest.NewRecorder()
+		handlers.UpdateEvent(db)(w, req)
+		return w
+	}
+
+	// Niezmieniony termin w przeszłości nie blokuje poprawek
+	if w := put(1, started.Format(time.RFC3339)); w.Code != http.StatusOK {
+		t.Fatalf("unchanged past date: expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	// Nowy termin musi być w przyszłości
+	if w := put(2, started.Add(-time.Hour).Format(time.RFC3339)); w.Code != http.StatusBadRequest {
+		t.Fatalf("moved to the past: expected 400, got %d", w.Code)
+	}
+}
+
+func TestPatchEvent_PartialUpdate(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	id := insertEvent(t, db, "published", 10)
+
+	patch := func(body, ifMatch string) *httptest.ResponseRecorder {
+		req := mux.SetURLVars(
+			httptest.NewRequest("PATCH", "/events/x", bytes.NewBufferString(body)).WithContext(owner),
+			map[string]string{"id": strconv.Itoa(id)},
+		)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		handlers.PatchEvent(db)(w, req)
+		return w
+	}
+
+	// Bez If-Match PATCH jest dozwolony i zmienia tylko podane pola
+	w := patch(`{"capacity":25}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("patch: expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	var ev models.Event
+	json.Unmarshal(w.Body.Bytes(), &ev)
+	if ev.Capacity != 25 || ev.Title != "Event" || ev.Version != 2 {
+		t.Fatalf("unexpected event after patch: %+v", ev)
+	}
+
+	if w := patch(`{"title":""}`, ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("blank title: expected 400, got %d", w.Code)
+	}
+	if w := patch(`{"title":"Stary"}`, `"1"`); w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("stale If-Match: expected 412, got %d", w.Code)
+	}
+}
+
+func TestCreateEvent_Timezone(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	year := time.Now().Year() + 1
+
+	create := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handlers.CreateEvent(db)(w, httptest.NewRequest("POST", "/events", bytes.NewBufferString(body)).WithContext(owner))
+		return w
+	}
+
+	// Data bez strefy to czas lokalny w timezone; zapis w UTC
+	w := create(fmt.Sprintf(
+		`{"title":"Koncert","date_time":"%d-06-18T18:00","end_date_time":"%d-06-18T21:30","timezone":"Europe/Warsaw","capacity":5}`,
+		year, year,
+	))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d (%s)", w.Code, w.Body.String())
+	}
+	var created map[string]int
+	json.Unmarshal(w.Body.Bytes(), &created)
+	e := getEvent(t, db, owner, created["id"])
+	if e.Date.Hour() != 16 || e.Date.Location() != time.UTC || e.Timezone != "Europe/Warsaw" {
+		t.Fatalf("unexpected stored start: %+v", e)
+	}
+	if want := fmt.Sprintf("%d-06-18T18:00:00+02:00", year); e.LocalDate != want {
+		t.Fatalf("local_date: got %q, want %q", e.LocalDate, want)
+	}
+	if e.EndDate == nil || e.EndDate.Hour() != 19 || e.LocalEndDate != fmt.Sprintf("%d-06-18T21:30:00+02:00", year) {
+		t.Fatalf("unexpected end: %v %q", e.EndDate, e.LocalEndDate)
+	}
+
+	// RFC 3339 wskazuje konkretną chwilę niezależnie od timezone
+	w = create(fmt.Sprintf(`{"title":"Online","date_time":"%d-01-10T09:00:00-05:00","timezone":"Asia/Tokyo","capacity":5}`, year))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create rfc3339: expected 201, got %d (%s)", w.Code, w.Body.String())
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if e := getEvent(t, db, owner, created["id"]); e.Date.Hour() != 14 || e.LocalDate != fmt.Sprintf("%d-01-10T23:00:00+09:00", year) {
+		t.Fatalf("unexpected rfc3339 event: %+v", e)
+	}
+
+	for name, body := range map[string]string{
+		"timezone":      fmt.Sprintf(`{"title":"X","date_time":"%d-06-18T18:00","timezone":"Europe/Gotham","capacity":5}`, year),
+		"end_date_time": fmt.Sprintf(`{"title":"X","date_time":"%d-06-18T18:00","end_date_time":"%d-06-18T17:00","capacity":5}`, year, year),
+	} {
+		w := create(body)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"`+name+`"`) {
+			t.Errorf("%s: expected 400 for the field, got %d (%s)", name, w.Code, w.Body.String())
+		}
 	}
 }
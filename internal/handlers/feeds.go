@@ -0,0 +1,228 @@
+// File: internal/handlers/feeds.go
+package handlers
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/feed"
+	"github.com/bartbaranski/eventhub/internal/models"
+)
+
+// feedLimit to maksymalna liczba wydarzeń w kanale.
+const feedLimit = 50
+
+// AtomFeed zwraca nadchodzące wydarzenia jako Atom.
+func AtomFeed(db *sql.DB, siteURL string) http.HandlerFunc {
+	return eventsFeed(db, siteURL, "application/atom+xml; charset=utf-8", (*feed.Feed).Atom)
+}
+
+// RSSFeed zwraca nadchodzące wydarzenia jako RSS 2.0.
+func RSSFeed(db *sql.DB, siteURL string) http.HandlerFunc {
+	return eventsFeed(db, siteURL, "application/rss+xml; charset=utf-8", (*feed.Feed).RSS)
+}
+
+// JSONFeed zwraca nadchodzące wydarzenia jako JSON Feed 1.1.
+func JSONFeed(db *sql.DB, siteURL string) http.HandlerFunc {
+	return eventsFeed(db, siteURL, "application/feed+json; charset=utf-8", (*feed.Feed).JSON)
+}
+
+// eventsFeed to publiczny kanał opublikowanych, nadchodzących wydarzeń
+// (bez logowania), filtrowany przez ?organizer=, ?category= i ?tag=.
+// Linki wskazują stronę wydarzenia we frontendzie pod siteURL (pusty –
+// adres, pod którym przyszło żądanie).
+//
+// Obsługuje warunkowy GET: ETag to skrót treści, a Last-Modified to
+// ostatnia zmiana dowolnego wydarzenia pasującego do filtra – także
+// odwołanego czy usuniętego, bo to usuwa je z kanału.
+func eventsFeed(db *sql.DB, siteURL, contentType string, encode func(*feed.Feed) ([]byte, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 1) Filtry
+		q := r.URL.Query()
+		filter := "TRUE"
+		var args []interface{}
+		if o := q.Get("organizer"); o != "" {
+			id, err := strconv.Atoi(o)
+			if err != nil {
+				writeFieldError(w, "organizer", "organizer must be a user ID")
+				return
+			}
+			args = append(args, id)
+			filter += " AND organizer_id = $" + strconv.Itoa(len(args))
+		}
+		if c := q.Get("category"); c != "" {
+			args = append(args, c)
+			filter += " AND category = $" + strconv.Itoa(len(args))
+		}
+		for _, t := range q["tag"] {
+			tag, _ := normalizeTag(t)
+			args = append(args, tag)
+			filter += " AND id IN (SELECT event_id FROM event_tags WHERE tag = $" + strconv.Itoa(len(args)) + ")"
+		}
+
+		// 2) Ostatnia zmiana
+		var lastModified time.Time
+		var updated sql.NullTime
+		err := db.QueryRow(
+			"SELECT updated_at FROM events WHERE "+filter+" AND updated_at IS NOT NULL ORDER BY updated_at DESC LIMIT 1", args...,
+		).Scan(&updated)
+		if err != nil && err != sql.ErrNoRows {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if updated.Valid {
+			lastModified = updated.Time.UTC()
+		}
+
+		// 3) Nadchodzące wydarzenia
+		args = append(args, models.EventPublished, time.Now().UTC())
+		where := filter + " AND deleted_at IS NULL AND status = $" + strconv.Itoa(len(args)-1) +
+			" AND date >= $" + strconv.Itoa(len(args))
+		rows, err := db.Query(
+			"SELECT "+eventColumns+" FROM events WHERE "+where+" ORDER BY date, id LIMIT "+strconv.Itoa(feedLimit), args...,
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		events := []models.Event{}
+		for rows.Next() {
+			var e models.Event
+			if err := scanEvent(rows, &e); err != nil {
+				continue
+			}
+			events = append(events, e)
+		}
+		rows.Close()
+		if err := attachTags(db, events,
+			"SELECT event_id, tag FROM event_tags WHERE event_id IN (SELECT id FROM events WHERE "+where+") ORDER BY tag", args...,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := attachImages(db, events,
+			"SELECT event_id, variant, url, width, height FROM event_images WHERE event_id IN (SELECT id FROM events WHERE "+where+")", args...,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		venues, err := loadVenues(db, where, args...)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// 4) Kanał w żądanym formacie
+		origin := requestOrigin(r)
+		site := strings.TrimSuffix(siteURL, "/")
+		if site == "" {
+			site = origin
+		}
+		f := feed.Feed{
+			Title:       "EventHub",
+			Description: "Upcoming events",
+			Link:        site + "/events",
+			SelfURL:     origin + r.URL.RequestURI(),
+			Updated:     lastModified,
+		}
+		for _, e := range events {
+			var venue *models.Venue
+			if e.VenueID != nil {
+				venue = venues[*e.VenueID]
+			}
+			f.Items = append(f.Items, feedItem(e, venue, site, origin, lastModified))
+		}
+		body, err := encode(&f)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// 5) Odpowiedź; ServeContent odpowiada 304 na If-None-Match / If-Modified-Since
+		sum := sha256.Sum256(body)
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		http.ServeContent(w, r, "", lastModified, strings.NewReader(string(body)))
+	}
+}
+
+// feedItem zamienia wydarzenie na wpis kanału. Termin i miejsce trafiają
+// do opisu (Atom/RSS) i do rozszerzenia "_event" (JSON Feed).
+func feedItem(e models.Event, venue *models.Venue, site, origin string, fallback time.Time) feed.Item {
+	url := site + "/events/" + strconv.Itoa(e.ID)
+	loc := location(e.Timezone)
+	when := e.Date.In(loc).Format("2006-01-02 15:04")
+	if e.EndDate != nil {
+		when += " – " + e.EndDate.In(loc).Format("2006-01-02 15:04")
+	}
+	summary := when + " (" + e.Timezone + ")"
+	ev := &feed.Event{Start: e.Date, End: e.EndDate, Timezone: e.Timezone, Status: e.Status}
+	if venue != nil {
+		ev.Location = venue.Name + ", " + venue.Address
+		summary += "\n" + ev.Location
+	}
+	if e.Description != "" {
+		summary += "\n\n" + e.Description
+	}
+
+	it := feed.Item{
+		ID:      url,
+		URL:     url,
+		Title:   e.Title,
+		Summary: summary,
+		Updated: fallback,
+		Event:   ev,
+	}
+	if e.UpdatedAt != nil {
+		it.Updated = *e.UpdatedAt
+	}
+	if e.Category != "" {
+		it.Categories = append(it.Categories, e.Category)
+	}
+	it.Categories = append(it.Categories, e.Tags...)
+	image := e.ImageURL
+	if e.Images != nil {
+		image = e.Images.Card.URL
+	}
+	if strings.HasPrefix(image, "/") {
+		// pliki z lokalnego magazynu serwuje ten serwer
+		image = origin + image
+	}
+	it.Image = image
+	return it
+}
+
+// requestOrigin zwraca schemat i host, pod którymi przyszło żądanie
+// (z uwzględnieniem X-Forwarded-Proto za reverse proxy).
+func requestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// loadVenues zwraca miejsca wydarzeń spełniających warunek where.
+func loadVenues(db *sql.DB, where string, args ...interface{}) (map[int]*models.Venue, error) {
+	rows, err := db.Query(
+		"SELECT "+venueColumns+" FROM venues WHERE id IN (SELECT venue_id FROM events WHERE "+where+")", args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	venues := map[int]*models.Venue{}
+	for rows.Next() {
+		var v models.Venue
+		if err := scanVenue(rows, &v); err == nil {
+			venues[v.ID] = &v
+		}
+	}
+	return venues, rows.Err()
+}
@@ -0,0 +1,129 @@
+// File: internal/handlers/feeds_test.go
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/handlers"
+)
+
+func getFeed(h http.HandlerFunc, target string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", target, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h(w, req)
+	return w
+}
+
+func TestJSONFeed_FiltersAndConditionalGet(t *testing.T) {
+	db := newEventDB(t)
+	modified := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	concert := insertEvent(t, db, "published", 10)
+	talk := insertEvent(t, db, "published", 10)
+	draft := insertEvent(t, db, "draft", 10)
+	past := insertEvent(t, db, "published", 10)
+	db.Exec("UPDATE events SET updated_at=$1", modified)
+	db.Exec("UPDATE events SET category='concert' WHERE id=$1", concert)
+	db.Exec("UPDATE events SET organizer_id=2 WHERE id=$1", talk)
+	db.Exec("UPDATE events SET date=$1 WHERE id=$2", time.Now().AddDate(0, 0, -1).UTC(), past)
+
+	h := handlers.JSONFeed(db, "https://example.com/")
+	ids := func(w *httptest.ResponseRecorder) []string {
+		var f struct {
+			Items []struct {
+				ID string `json:"id"`
+			} `json:"items"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &f); err != nil {
+			t.Fatalf("invalid JSON feed: %v\n%s", err, w.Body.String())
+		}
+		var out []string
+		for _, it := range f.Items {
+			out = append(out, it.ID)
+		}
+		return out
+	}
+	url := func(id int) string { return fmt.Sprintf("https://example.com/events/%d", id) }
+
+	w := getFeed(h, "/feeds/events.json", nil)
+	if got := ids(w); len(got) != 2 || got[0] != url(concert) || got[1] != url(talk) {
+		t.Fatalf("expected only upcoming published events, got %v (draft %d, past %d)", got, draft, past)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/feed+json; charset=utf-8" {
+		t.Fatalf("unexpected Content-Type %q", ct)
+	}
+	if got := ids(getFeed(h, "/feeds/events.json?category=concert", nil)); len(got) != 1 || got[0] != url(concert) {
+		t.Fatalf("category filter: unexpected %v", got)
+	}
+	if got := ids(getFeed(h, "/feeds/events.json?organizer=2", nil)); len(got) != 1 || got[0] != url(talk) {
+		t.Fatalf("organizer filter: unexpected %v", got)
+	}
+	if w := getFeed(h, "/feeds/events.json?organizer=abc", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid organizer: expected 400, got %d", w.Code)
+	}
+
+	// Warunkowy GET
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Header().Get("Last-Modified") != modified.Format(http.TimeFormat) {
+		t.Fatalf("expected ETag and Last-Modified, got %v", w.Header())
+	}
+	if w := getFeed(h, "/feeds/events.json", map[string]string{"If-None-Match": etag}); w.Code != http.StatusNotModified {
+		t.Fatalf("If-None-Match: expected 304, got %d", w.Code)
+	}
+	if w := getFeed(h, "/feeds/events.json", map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}); w.Code != http.StatusNotModified {
+		t.Fatalf("If-Modified-Since: expected 304, got %d", w.Code)
+	}
+
+	// Odwołanie wydarzenia zmienia kanał
+	db.Exec("UPDATE events SET status='cancelled', updated_at=$1 WHERE id=$2", modified.Add(time.Hour), concert)
+	w = getFeed(h, "/feeds/events.json", map[string]string{"If-None-Match": etag})
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag || len(ids(w)) != 1 {
+		t.Fatalf("after cancel: expected 200 with new ETag, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+	if w := getFeed(h, "/feeds/events.json", map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}); w.Code != http.StatusOK {
+		t.Fatalf("after cancel: If-Modified-Since must not match, got %d", w.Code)
+	}
+}
+
+func TestAtomAndRSSFeeds(t *testing.T) {
+	db := newEventDB(t)
+	id := insertEvent(t, db, "published", 10)
+	db.Exec("UPDATE events SET title='Jazz & blues', image_url='/uploads/poster.jpg' WHERE id=$1", id)
+
+	for name, tc := range map[string]struct {
+		h    http.HandlerFunc
+		ct   string
+		want []string
+	}{
+		"atom": {handlers.AtomFeed(db, ""), "application/atom+xml; charset=utf-8", []string{
+			`<feed xmlns="http://www.w3.org/2005/Atom">`,
+			`href="http://example.com/api/v1/feeds/events.atom"`,
+			fmt.Sprintf("<id>http://example.com/events/%d</id>", id),
+			"Jazz &amp; blues",
+			`href="http://example.com/uploads/poster.jpg"`,
+		}},
+		"rss": {handlers.RSSFeed(db, ""), "application/rss+xml; charset=utf-8", []string{
+			`<rss version="2.0"`,
+			fmt.Sprintf("<link>http://example.com/events/%d</link>", id),
+			"Jazz &amp; blues",
+		}},
+	} {
+		w := getFeed(tc.h, "http://example.com/api/v1/feeds/events."+name, nil)
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != tc.ct {
+			t.Fatalf("%s: unexpected %d %q", name, w.Code, w.Header().Get("Content-Type"))
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(w.Body.String(), want) {
+				t.Errorf("%s: missing %q in:\n%s", name, want, w.Body.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,391 @@
+// File: internal/handlers/history.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/bartbaranski/eventhub/internal/validate"
+	"github.com/gorilla/mux"
+)
+
+// revisableColumns mapuje pola wersjonowane w historii na kolumny tabeli events.
+var revisableColumns = map[string]string{
+	"title":       "title",
+	"description": "description",
+	"date":        "date",
+	"end_date":    "end_date",
+	"timezone":    "timezone",
+	"capacity":    "capacity",
+	"image_url":   "image_url",
+	"venue_id":    "venue_id",
+	"category":    "category",
+	// reguły rezerwacji zmienia SetEventLimits
+	"max_tickets_per_user":   "max_tickets_per_user",
+	"require_verified_email": "require_verified_email",
+	// status zmieniają tylko przejścia (transitionEvent), nie PUT/PATCH ani cofnięcie
+	"status": "status",
+}
+
+// eventSnapshot zwraca wersjonowane pola wydarzenia w postaci JSON-owej
+// (liczby jako float64, daty jako RFC 3339 w UTC, brak końca, miejsca lub limitu jako nil), żeby
+// dało się je porównywać z wartościami odczytanymi z zapisanych rewizji.
+func eventSnapshot(e models.Event) map[string]interface{} {
+	var end, venue, limit interface{}
+	if e.EndDate != nil {
+		end = e.EndDate.UTC().Format(time.RFC3339)
+	}
+	if e.VenueID != nil {
+		venue = float64(*e.VenueID)
+	}
+	if e.MaxTicketsPerUser != nil {
+		limit = float64(*e.MaxTicketsPerUser)
+	}
+	tags := make([]interface{}, len(e.Tags))
+	for i, t := range e.Tags {
+		tags[i] = t
+	}
+	return map[string]interface{}{
+		"title":       e.Title,
+		"description": e.Description,
+		"date":        e.Date.UTC().Format(time.RFC3339),
+		"end_date":    end,
+		"timezone":    e.Timezone,
+		"capacity":    float64(e.Capacity),
+		"image_url":   e.ImageURL,
+		"venue_id":    venue,
+		"category":    e.Category,
+		"tags":        tags,
+
+		"max_tickets_per_user":   limit,
+		"require_verified_email": e.RequireVerifiedEmail,
+	}
+}
+
+// diffSnapshots zwraca pola, które różnią się między from a to.
+func diffSnapshots(from, to map[string]interface{}) map[string]models.FieldChange {
+	changes := map[string]models.FieldChange{}
+	for field, newVal := range to {
+		if oldVal := from[field]; !reflect.DeepEqual(oldVal, newVal) {
+			changes[field] = models.FieldChange{Old: oldVal, New: newVal}
+		}
+	}
+	return changes
+}
+
+// columnValue zamienia wartość ze snapshotu na parametr zapytania SQL.
+func columnValue(field string, v interface{}) (interface{}, error) {
+	switch field {
+	case "date":
+		s, _ := v.(string)
+		return time.Parse(time.RFC3339, s)
+	case "end_date":
+		s, ok := v.(string)
+		if !ok {
+			return nil, nil
+		}
+		return time.Parse(time.RFC3339, s)
+	case "capacity":
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("invalid capacity %v", v)
+		}
+		return int(f), nil
+	case "venue_id", "max_tickets_per_user":
+		f, ok := v.(float64)
+		if !ok {
+			return nil, nil
+		}
+		return int(f), nil
+	default:
+		return v, nil
+	}
+}
+
+// snapshotErrors sprawdza stan wydarzenia po cofnięciu zmian tak jak
+// UpdateEvent: zmieniony termin musi być w przyszłości, koniec po początku,
+// a pojemność nie może przekraczać pojemności miejsca.
+func snapshotErrors(q queryRower, snap map[string]interface{}, changes map[string]models.FieldChange) validate.Errors {
+	var errs validate.Errors
+	_, dateChanged := changes["date"]
+	_, endChanged := changes["end_date"]
+	if dateChanged || endChanged {
+		start, _ := columnValue("date", snap["date"])
+		end, _ := columnValue("end_date", snap["end_date"])
+		startTime, _ := start.(time.Time)
+		if dateChanged && !startTime.After(time.Now()) {
+			errs = append(errs, validate.FieldError{Field: "date_time", Message: "must be in the future"})
+		}
+		if endTime, ok := end.(time.Time); ok && !endTime.After(startTime) {
+			errs = append(errs, validate.FieldError{Field: "end_date_time", Message: "must be after date_time"})
+		}
+	}
+	_, venueChanged := changes["venue_id"]
+	_, capacityChanged := changes["capacity"]
+	if venueChanged || capacityChanged {
+		venue, _ := columnValue("venue_id", snap["venue_id"])
+		capacity, _ := columnValue("capacity", snap["capacity"])
+		var venueID *int
+		if id, ok := venue.(int); ok {
+			venueID = &id
+		}
+		n, _ := capacity.(int)
+		if fe := venueError(q, venueID, n); fe != nil {
+			errs = append(errs, *fe)
+		}
+	}
+	return errs
+}
+
+// errVersionConflict oznacza, że wydarzenie zmieniło się od odczytu (inna wersja).
+var errVersionConflict = errors.New("event was modified concurrently")
+
+// saveEventChanges aktualizuje zmienione kolumny wydarzenia (o ile wciąż ma
+// wersję version), podbija wersję i zapisuje rewizję w tej samej transakcji.
+// Etykiety (tags) nie są kolumną – zastępuje je setEventTags.
+// Zwraca nową wersję; pusty zestaw zmian niczego nie zapisuje.
+func saveEventChanges(tx *sql.Tx, eventID, actorID, version int, changes map[string]models.FieldChange, rollbackOf *int) (int, error) {
+	if len(changes) == 0 {
+		return version, nil
+	}
+
+	fields := make([]string, 0, len(changes))
+	for f := range changes {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	now := time.Now().UTC()
+	query := "UPDATE events SET version=version+1, updated_at=$1"
+	args := []interface{}{now}
+	for _, f := range fields {
+		if f == "tags" {
+			continue
+		}
+		col, ok := revisableColumns[f]
+		if !ok {
+			return 0, fmt.Errorf("field %q is not revisable", f)
+		}
+		v, err := columnValue(f, changes[f].New)
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, v)
+		query += ", " + col + "=$" + strconv.Itoa(len(args))
+	}
+	// Zmiana terminu lub statusu to nowa wersja wpisu w kalendarzach subskrybentów
+	for _, f := range []string{"date", "end_date", "status"} {
+		if _, ok := changes[f]; ok {
+			query += ", sequence=sequence+1"
+			break
+		}
+	}
+	args = append(args, eventID, version)
+	query += " WHERE id=$" + strconv.Itoa(len(args)-1) + " AND version=$" + strconv.Itoa(len(args))
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, errVersionConflict
+	}
+	if ch, ok := changes["tags"]; ok {
+		if err := setEventTags(tx, eventID, tagList(ch.New)); err != nil {
+			return 0, err
+		}
+	}
+
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec(
+		`INSERT INTO event_revisions(event_id, actor_id, changes, rollback_of, created_at)
+		 VALUES($1, $2, $3, $4, $5)`,
+		eventID, actorID, string(payload), rollbackOf, now,
+	)
+	return version + 1, err
+}
+
+// writeSaveError mapuje błąd saveEventChanges na odpowiedź HTTP.
+func writeSaveError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errVersionConflict) {
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		return
+	}
+	if errors.Is(err, errSeriesDateShift) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// ownedEvent sprawdza, czy zalogowany organizator (lub admin) może zarządzać
+// wydarzeniem z {id}; zwraca ID wydarzenia i ID aktora.
+func ownedEvent(w http.ResponseWriter, r *http.Request, q interface {
+	QueryRow(string, ...interface{}) *sql.Row
+}) (eventID, actorID int, ok bool) {
+	claims, ok := auth.FromContext(r.Context())
+	if !ok || (claims["role"] != "organizer" && claims["role"] != "admin") {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return 0, 0, false
+	}
+	actorID = int(claims["id"].(float64))
+	eventID, _ = strconv.Atoi(mux.Vars(r)["id"])
+
+	var owner int
+	if err := q.QueryRow(
+		"SELECT organizer_id FROM events WHERE id=$1 AND deleted_at IS NULL", eventID,
+	).Scan(&owner); err != nil || (claims["role"] != "admin" && owner != actorID) {
+		http.Error(w, "Forbidden or not found", http.StatusForbidden)
+		return 0, 0, false
+	}
+	return eventID, actorID, true
+}
+
+// loadRevisions zwraca rewizje wydarzenia od najnowszej; after > 0 ogranicza
+// je do rewizji nowszych niż podana.
+func loadRevisions(q interface {
+	Query(string, ...interface{}) (*sql.Rows, error)
+}, eventID, after int) ([]models.EventRevision, error) {
+	rows, err := q.Query(
+		`SELECT id, event_id, actor_id, changes, rollback_of, created_at
+		 FROM event_revisions WHERE event_id=$1 AND id>$2 ORDER BY id DESC`,
+		eventID, after,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.EventRevision{}
+	for rows.Next() {
+		var rev models.EventRevision
+		var payload string
+		var rollbackOf sql.NullInt64
+		if err := rows.Scan(&rev.ID, &rev.EventID, &rev.ActorID, &payload, &rollbackOf, &rev.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(payload), &rev.Changes); err != nil {
+			return nil, err
+		}
+		if rollbackOf.Valid {
+			v := int(rollbackOf.Int64)
+			rev.RollbackOf = &v
+		}
+		out = append(out, rev)
+	}
+	return out, rows.Err()
+}
+
+// EventHistory zwraca historię zmian wydarzenia (właściciel lub admin).
+func EventHistory(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		eventID, _, ok := ownedEvent(w, r, db)
+		if !ok {
+			return
+		}
+		revisions, err := loadRevisions(db, eventID, 0)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(revisions)
+	}
+}
+
+// RollbackEvent przywraca wydarzenie do stanu zaraz po rewizji {rev}.
+// Cofnięcie samo jest zapisywane jako nowa rewizja z rollback_of.
+func RollbackEvent(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		revID, err := strconv.Atoi(mux.Vars(r)["rev"])
+		if err != nil {
+			http.Error(w, "Invalid revision", http.StatusBadRequest)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		// 1) Uprawnienia i istnienie rewizji
+		eventID, actorID, ok := ownedEvent(w, r, tx)
+		if !ok {
+			return
+		}
+		var exists int
+		if err := tx.QueryRow(
+			"SELECT COUNT(*) FROM event_revisions WHERE id=$1 AND event_id=$2", revID, eventID,
+		).Scan(&exists); err != nil || exists == 0 {
+			http.Error(w, "Revision not found", http.StatusNotFound)
+			return
+		}
+
+		// 2) Stan docelowy: bieżący stan z cofniętymi (od najnowszej) wszystkimi późniejszymi zmianami
+		var current models.Event
+		if err := scanEvent(tx.QueryRow(
+			"SELECT "+eventColumns+" FROM events WHERE id=$1", eventID,
+		), &current); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := loadEventTags(tx, &current); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !checkIfMatch(w, r, current.Version, false) {
+			return
+		}
+		later, err := loadRevisions(tx, eventID, revID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		from := eventSnapshot(current)
+		target := eventSnapshot(current)
+		for _, rev := range later {
+			for field, ch := range rev.Changes {
+				if field == "status" {
+					continue // statusu nie cofa się – służą do tego przejścia
+				}
+				target[field] = ch.Old
+			}
+		}
+
+		// 3) Stan docelowy musi przejść te same reguły co UpdateEvent
+		changes := diffSnapshots(from, target)
+		if errs := snapshotErrors(tx, target, changes); len(errs) > 0 {
+			writeFieldErrors(w, errs)
+			return
+		}
+
+		// 4) Zapis zmian jako nowa rewizja
+		version, err := saveEventChanges(tx, eventID, actorID, current.Version, changes, &revID)
+		if err != nil {
+			writeSaveError(w, err)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("ETag", eventETag(version))
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": eventID, "changes": changes})
+	}
+}
@@ -0,0 +1,142 @@
+// File: internal/handlers/history_test.go
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+)
+
+// putEvent wykonuje PUT /events/{id} z podanym tytułem i pojemnością.
+func putEvent(t *testing.T, db *sql.DB, ctx context.Context, id int, title string, capacity int) {
+	t.Helper()
+	var version int
+	if err := db.QueryRow("SELECT version FROM events WHERE id=$1", id).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	body := fmt.Sprintf(`{"title":%q,"description":"","date_time":%q,"capacity":%d}`, title, futureDateTime(), capacity)
+	req := mux.SetURLVars(
+		httptest.NewRequest("PUT", "/events/x", bytes.NewBufferString(body)).WithContext(ctx),
+		map[string]string{"id": strconv.Itoa(id)},
+	)
+	req.Header.Set("If-Match", `"`+strconv.Itoa(version)+`"`)
+	w := httptest.NewRecorder()
+	handlers.UpdateEvent(db)(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+}
+
+func history(t *testing.T, h http.HandlerFunc, ctx context.Context, id int) []models.EventRevision {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h(w, eventReq("GET", ctx, id))
+	if w.Code != http.StatusOK {
+		t.Fatalf("history: expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	var revs []models.EventRevision
+	if err := json.Unmarshal(w.Body.Bytes(), &revs); err != nil {
+		t.Fatal(err)
+	}
+	return revs
+}
+
+func TestEventHistoryAndRollback(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	id := insertEvent(t, db, "published", 10)
+	putEvent(t, db, owner, id, "Pierwsza zmiana", 20)
+	putEvent(t, db, owner, id, "Druga zmiana", 20)
+
+	revs := history(t, handlers.EventHistory(db), owner, id)
+	if len(revs) != 2 {
+		t.Fatalf("expected 2 revisions, got %d", len(revs))
+	}
+	// Najnowsza pierwsza; pojemność nie zmieniła się w drugiej edycji
+	latest, first := revs[0], revs[1]
+	if latest.Changes["title"].Old != "Pierwsza zmiana" || latest.Changes["title"].New != "Druga zmiana" {
+		t.Errorf("unexpected latest change: %+v", latest.Changes)
+	}
+	if _, ok := latest.Changes["capacity"]; ok {
+		t.Errorf("unchanged capacity recorded: %+v", latest.Changes)
+	}
+	if first.Changes["capacity"].Old != float64(10) || first.Changes["capacity"].New != float64(20) || first.ActorID != 1 {
+		t.Errorf("unexpected first revision: %+v", first)
+	}
+
+	// Cofnięcie do stanu po pierwszej rewizji
+	req := mux.SetURLVars(
+		httptest.NewRequest("POST", "/x", nil).WithContext(owner),
+		map[string]string{"id": strconv.Itoa(id), "rev": strconv.Itoa(first.ID)},
+	)
+	w := httptest.NewRecorder()
+	handlers.RollbackEvent(db)(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("rollback: expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	var title string
+	var capacity int
+	db.QueryRow("SELECT title, capacity FROM events WHERE id=$1", id).Scan(&title, &capacity)
+	if title != "Pierwsza zmiana" || capacity != 20 {
+		t.Fatalf("after rollback got %q/%d", title, capacity)
+	}
+
+	revs = history(t, handlers.EventHistory(db), owner, id)
+	if len(revs) != 3 || revs[0].RollbackOf == nil || *revs[0].RollbackOf != first.ID {
+		t.Fatalf("rollback should be recorded as a revision, got %+v", revs[0])
+	}
+
+	// Historia tylko dla właściciela
+	other := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(2), "role": "organizer"})
+	w = httptest.NewRecorder()
+	handlers.EventHistory(db)(w, eventReq("GET", other, id))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("foreign history: expected 403, got %d", w.Code)
+	}
+}
+
+func TestRollbackEvent_Validation(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	id := insertEvent(t, db, "published", 10)
+	putEvent(t, db, owner, id, "Pierwsza zmiana", 10)
+
+	// Późniejsza rewizja przesunęła termin z daty, która już minęła
+	var date time.Time
+	db.QueryRow("SELECT date FROM events WHERE id=$1", id).Scan(&date)
+	current := date.UTC().Format(time.RFC3339)
+	past := time.Now().AddDate(0, 0, -1).UTC().Format(time.RFC3339)
+	db.Exec(
+		`INSERT INTO event_revisions(event_id, actor_id, changes, created_at) VALUES($1, 1, $2, CURRENT_TIMESTAMP)`,
+		id, fmt.Sprintf(`{"date":{"old":%q,"new":%q}}`, past, current),
+	)
+	first := history(t, handlers.EventHistory(db), owner, id)[1]
+
+	req := mux.SetURLVars(
+		httptest.NewRequest("POST", "/x", nil).WithContext(owner),
+		map[string]string{"id": strconv.Itoa(id), "rev": strconv.Itoa(first.ID)},
+	)
+	w := httptest.NewRecorder()
+	handlers.RollbackEvent(db)(w, req)
+	if w.Code != http.StatusBadRequest || !bytes.Contains(w.Body.Bytes(), []byte("date_time")) {
+		t.Fatalf("rollback into the past: expected 400 on date_time, got %d (%s)", w.Code, w.Body.String())
+	}
+	var after time.Time
+	db.QueryRow("SELECT date FROM events WHERE id=$1", id).Scan(&after)
+	if !after.Equal(date) {
+		t.Fatalf("rejected rollback must not change the date, got %v", after)
+	}
+}
@@ -0,0 +1,309 @@
+// File: internal/handlers/images.go
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+
+	"github.com/bartbaranski/eventhub/internal/blobstore"
+	"github.com/bartbaranski/eventhub/internal/imaging"
+	"github.com/bartbaranski/eventhub/internal/models"
+)
+
+const (
+	// maxImageBytes ogranicza rozmiar przesyłanego pliku obrazu.
+	maxImageBytes = 10 << 20
+	// maxImagePixels chroni przed "bombami" – małymi plikami o ogromnej rozdzielczości.
+	maxImagePixels = 50_000_000
+	imageQuality   = 85
+)
+
+// imageVariant to rozmiar, do którego skalowany jest przesłany obraz.
+// Fill przycina do dokładnych wymiarów, w przeciwnym razie obraz jest tylko
+// zmniejszany z zachowaniem proporcji.
+type imageVariant struct {
+	Name          string
+	Width, Height int
+	Fill          bool
+}
+
+var imageVariants = []imageVariant{
+	{Name: "thumbnail", Width: 160, Height: 160, Fill: true},
+	{Name: "card", Width: 640, Height: 360, Fill: true},
+	{Name: "hero", Width: 1600, Height: 900},
+}
+
+// storedImage to wariant zapisany w magazynie plików.
+type storedImage struct {
+	Variant string
+	Key     string
+	models.EventImage
+}
+
+// UploadEventImage przyjmuje obraz wydarzenia (multipart/form-data, pole
+// "image"; JPEG, PNG lub GIF), zapisuje warianty thumbnail/card/hero w store
+// i ustawia image_url na adres wariantu hero (jako zwykła, wersjonowana zmiana).
+// Poprzednie pliki zostają w magazynie – mogą do nich prowadzić rewizje.
+func UploadEventImage(db *sql.DB, store blobstore.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// 1) Uprawnienia i bieżąca wersja
+		eventID, actorID, ok := ownedEvent(w, r, db)
+		if !ok {
+			return
+		}
+		var current models.Event
+		if err := scanEvent(db.QueryRow(
+			"SELECT "+eventColumns+" FROM events WHERE id=$1", eventID,
+		), &current); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !checkIfMatch(w, r, current.Version, false) {
+			return
+		}
+
+		// 2) Plik z formularza
+		data, ok := readImagePart(w, r)
+		if !ok {
+			return
+		}
+
+		// 3) Rozpoznanie typu po treści (nie po nagłówkach klienta) i dekodowanie
+		src, mime, err := imaging.Decode(data, maxImagePixels)
+		switch {
+		case errors.Is(err, imaging.ErrUnsupported):
+			http.Error(w, "Unsupported image type "+mime+" (use JPEG, PNG or GIF)", http.StatusUnsupportedMediaType)
+			return
+		case errors.Is(err, imaging.ErrTooLarge):
+			writeFieldError(w, "image", fmt.Sprintf("must have at most %d pixels", maxImagePixels))
+			return
+		case err != nil:
+			writeFieldError(w, "image", "is not a valid image")
+			return
+		}
+
+		// 4) Warianty w magazynie
+		stored, err := storeImageVariants(r.Context(), store, eventID, src)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		cleanup := func() {
+			for _, s := range stored {
+				store.Delete(context.Background(), s.Key)
+			}
+		}
+
+		// 5) Zapis adresów przy wydarzeniu
+		version, images, err := saveEventImages(db, eventID, actorID, current, stored)
+		if err != nil {
+			cleanup()
+			writeSaveError(w, err)
+			return
+		}
+		w.Header().Set("ETag", eventETag(version))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":        eventID,
+			"image_url": images.Hero.URL,
+			"images":    images,
+		})
+	}
+}
+
+// readImagePart czyta pole "image" z body multipart/form-data, pilnując
+// limitu rozmiaru. W razie błędu sam wysyła odpowiedź i zwraca false.
+func readImagePart(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	// Zapas na nagłówki części i ewentualne inne pola formularza
+	r.Body = http.MaxBytesReader(w, r.Body, maxImageBytes+64<<10)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "Expected multipart/form-data body", http.StatusBadRequest)
+		return nil, false
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			writeFieldError(w, "image", "is required")
+			return nil, false
+		}
+		if err != nil {
+			writeMultipartError(w, err)
+			return nil, false
+		}
+		if part.FormName() != "image" {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(part, maxImageBytes+1))
+		if err != nil {
+			writeMultipartError(w, err)
+			return nil, false
+		}
+		if len(data) > maxImageBytes {
+			http.Error(w, fmt.Sprintf("Image too large (max %d MB)", maxImageBytes>>20), http.StatusRequestEntityTooLarge)
+			return nil, false
+		}
+		if len(data) == 0 {
+			writeFieldError(w, "image", "is required")
+			return nil, false
+		}
+		return data, true
+	}
+}
+
+func writeMultipartError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+// storeImageVariants skaluje obraz do imageVariants i zapisuje je jako JPEG
+// pod kluczami events/{id}/{losowy prefiks}-{wariant}.jpg. Losowy prefiks
+// sprawia, że adresy się nie powtarzają i mogą być cache'owane bez końca.
+// Przy błędzie usuwa już zapisane warianty.
+func storeImageVariants(ctx context.Context, store blobstore.Store, eventID int, src *image.RGBA) ([]storedImage, error) {
+	prefix := make([]byte, 8)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+
+	stored := make([]storedImage, 0, len(imageVariants))
+	for _, v := range imageVariants {
+		var img *image.RGBA
+		if v.Fill {
+			img = imaging.Fill(src, v.Width, v.Height)
+		} else {
+			img = imaging.Fit(src, v.Width, v.Height)
+		}
+		data, err := imaging.EncodeJPEG(img, imageQuality)
+		if err == nil {
+			key := fmt.Sprintf("events/%d/%s-%s.jpg", eventID, hex.EncodeToString(prefix), v.Name)
+			err = store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "image/jpeg")
+			if err == nil {
+				b := img.Bounds()
+				stored = append(stored, storedImage{
+					Variant:    v.Name,
+					Key:        key,
+					EventImage: models.EventImage{URL: store.URL(key), Width: b.Dx(), Height: b.Dy()},
+				})
+				continue
+			}
+		}
+		for _, s := range stored {
+			store.Delete(context.Background(), s.Key)
+		}
+		return nil, err
+	}
+	return stored, nil
+}
+
+// saveEventImages ustawia image_url na wariant hero (z rewizją w historii)
+// i zastępuje warianty wydarzenia w event_images – w jednej transakcji.
+func saveEventImages(db *sql.DB, eventID, actorID int, current models.Event, stored []storedImage) (int, models.EventImages, error) {
+	var images models.EventImages
+	for _, s := range stored {
+		switch s.Variant {
+		case "thumbnail":
+			images.Thumbnail = s.EventImage
+		case "card":
+			images.Card = s.EventImage
+		case "hero":
+			images.Hero = s.EventImage
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, images, err
+	}
+	defer tx.Rollback()
+
+	changes := map[string]models.FieldChange{
+		"image_url": {Old: current.ImageURL, New: images.Hero.URL},
+	}
+	version, err := saveEventChanges(tx, eventID, actorID, current.Version, changes, nil)
+	if err != nil {
+		return 0, images, err
+	}
+	if _, err := tx.Exec("DELETE FROM event_images WHERE event_id=$1", eventID); err != nil {
+		return 0, images, err
+	}
+	for _, s := range stored {
+		if _, err := tx.Exec(
+			`INSERT INTO event_images(event_id, variant, storage_key, url, width, height)
+			 VALUES($1, $2, $3, $4, $5, $6)`,
+			eventID, s.Variant, s.Key, s.URL, s.Width, s.Height,
+		); err != nil {
+			return 0, images, err
+		}
+	}
+	return version, images, tx.Commit()
+}
+
+// attachImages uzupełnia Images wydarzeń wynikami zapytania zwracającego
+// (event_id, variant, url, width, height). Warianty są dołączane tylko, gdy
+// hero to bieżący image_url – po zmianie lub cofnięciu image_url są nieaktualne.
+func attachImages(q interface {
+	Query(string, ...interface{}) (*sql.Rows, error)
+}, events []models.Event, query string, args ...interface{}) error {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	found := map[int]*models.EventImages{}
+	for rows.Next() {
+		var id int
+		var variant string
+		var img models.EventImage
+		if err := rows.Scan(&id, &variant, &img.URL, &img.Width, &img.Height); err != nil {
+			return err
+		}
+		set := found[id]
+		if set == nil {
+			set = &models.EventImages{}
+			found[id] = set
+		}
+		switch variant {
+		case "thumbnail":
+			set.Thumbnail = img
+		case "card":
+			set.Card = img
+		case "hero":
+			set.Hero = img
+		}
+	}
+	for i := range events {
+		if set, ok := found[events[i].ID]; ok && set.Hero.URL == events[i].ImageURL {
+			events[i].Images = set
+		}
+	}
+	return rows.Err()
+}
+
+// loadEventImages uzupełnia warianty obrazu pojedynczego wydarzenia.
+func loadEventImages(q interface {
+	Query(string, ...interface{}) (*sql.Rows, error)
+}, e *models.Event) error {
+	events := []models.Event{*e}
+	if err := attachImages(q, events,
+		"SELECT event_id, variant, url, width, height FROM event_images WHERE event_id=$1", e.ID,
+	); err != nil {
+		return err
+	}
+	e.Images = events[0].Images
+	return nil
+}
@@ -0,0 +1,138 @@
+// File: internal/handlers/images_test.go
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/blobstore"
+	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+)
+
+// uploadImage wysyła plik w polu "image" formularza multipart.
+func uploadImage(h http.HandlerFunc, ctx context.Context, id int, data []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, _ := mw.CreateFormFile("image", "poster.png")
+	part.Write(data)
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/events/"+strconv.Itoa(id)+"/image", &body).WithContext(ctx)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req = mux.SetURLVars(req, map[string]string{"id": strconv.Itoa(id)})
+	w := httptest.NewRecorder()
+	h(w, req)
+	return w
+}
+
+func testPoster(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploadEventImage(t *testing.T) {
+	db := newEventDB(t)
+	dir := t.TempDir()
+	store, err := blobstore.NewLocal(blobstore.LocalConfig{Dir: dir, BaseURL: "/uploads"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	upload := handlers.UploadEventImage(db, store)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	eventID := insertEvent(t, db, "published", 10)
+
+	w := uploadImage(upload, owner, eventID, testPoster(t, 2000, 1000))
+	if w.Code != http.StatusOK {
+		t.Fatalf("upload: expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	if w.Header().Get("ETag") != `"2"` {
+		t.Fatalf("expected ETag \"2\", got %q", w.Header().Get("ETag"))
+	}
+	var resp struct {
+		ImageURL string             `json:"image_url"`
+		Images   models.EventImages `json:"images"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	for name, img := range map[string]struct {
+		got  models.EventImage
+		w, h int
+	}{
+		"thumbnail": {resp.Images.Thumbnail, 160, 160},
+		"card":      {resp.Images.Card, 640, 360},
+		"hero":      {resp.Images.Hero, 1600, 800},
+	} {
+		if img.got.Width != img.w || img.got.Height != img.h || !strings.HasSuffix(img.got.URL, "-"+name+".jpg") {
+			t.Errorf("%s: unexpected %+v", name, img.got)
+		}
+		key := strings.TrimPrefix(img.got.URL, "/uploads/")
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(key))); err != nil {
+			t.Errorf("%s: file not stored: %v", name, err)
+		}
+	}
+	if resp.ImageURL != resp.Images.Hero.URL {
+		t.Fatalf("image_url should point to hero, got %q", resp.ImageURL)
+	}
+
+	// Wydarzenie ma nowy image_url i warianty, a zmiana jest w historii
+	e := getEvent(t, db, owner, eventID)
+	if e.ImageURL != resp.Images.Hero.URL || e.Images == nil || e.Images.Card.URL != resp.Images.Card.URL {
+		t.Fatalf("event not updated: %+v", e)
+	}
+	var revisions int
+	db.QueryRow("SELECT COUNT(*) FROM event_revisions WHERE event_id=$1", eventID).Scan(&revisions)
+	if revisions != 1 {
+		t.Fatalf("expected 1 revision, got %d", revisions)
+	}
+
+	// Po ręcznej zmianie image_url warianty nie są już dołączane
+	db.Exec("UPDATE events SET image_url='https://cdn.example.com/x.jpg' WHERE id=$1", eventID)
+	if e := getEvent(t, db, owner, eventID); e.Images != nil {
+		t.Fatalf("stale variants attached: %+v", e.Images)
+	}
+}
+
+func TestUploadEventImage_Rejected(t *testing.T) {
+	db := newEventDB(t)
+	store, _ := blobstore.NewLocal(blobstore.LocalConfig{Dir: t.TempDir()})
+	upload := handlers.UploadEventImage(db, store)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	other := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(2), "role": "organizer"})
+	eventID := insertEvent(t, db, "published", 10)
+
+	if w := uploadImage(upload, other, eventID, testPoster(t, 10, 10)); w.Code != http.StatusForbidden {
+		t.Fatalf("other organizer: expected 403, got %d", w.Code)
+	}
+	// Typ rozpoznawany po treści, nie po nazwie pliku
+	if w := uploadImage(upload, owner, eventID, []byte("<html><script>alert(1)</script></html>")); w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("html: expected 415, got %d", w.Code)
+	}
+	if w := uploadImage(upload, owner, eventID, testPoster(t, 10, 10)[:40]); w.Code != http.StatusBadRequest {
+		t.Fatalf("broken image: expected 400, got %d", w.Code)
+	}
+	if w := uploadImage(upload, owner, eventID, bytes.Repeat([]byte{0xff}, 11<<20)); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("too large: expected 413, got %d", w.Code)
+	}
+
+	if e := getEvent(t, db, owner, eventID); e.ImageURL != "" || e.Version != 1 {
+		t.Fatalf("rejected uploads changed the event: %+v", e)
+	}
+}
@@ -0,0 +1,323 @@
+// File: internal/handlers/import.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/validate"
+)
+
+const (
+	// maxImportBytes ogranicza rozmiar importowanego pliku.
+	maxImportBytes = 5 << 20
+	// maxImportRows to maksymalna liczba wydarzeń w jednym imporcie.
+	maxImportRows = 1000
+)
+
+// importFields to pola wydarzenia (jak w JSON CreateEvent), które można
+// wczytać z kolumn pliku.
+var importFields = []string{
+	"title", "description", "date_time", "end_date_time", "timezone", "capacity",
+	"image_url", "status", "venue_id", "category", "tags",
+}
+
+// importError to błąd pola w danym wierszu pliku (nagłówek to wiersz 1,
+// tak jak w arkuszu kalkulacyjnym).
+type importError struct {
+	Row int `json:"row"`
+	validate.FieldError
+}
+
+// ImportEvents tworzy wydarzenia z pliku CSV albo TSV (tylko organizator).
+//
+// Body to multipart/form-data z polami:
+//
+//	file     – plik z wierszem nagłówka (wymagany)
+//	mapping  – JSON {"pole": "nagłówek kolumny"}; bez niego kolumny muszą
+//	           nazywać się jak pola (title, date_time, ...)
+//	format   – "csv" albo "tsv"; domyślnie według rozszerzenia pliku
+//	timezone – strefa IANA wierszy bez własnej strefy (domyślnie UTC)
+//
+// Każdy wiersz przechodzi te same reguły co CreateEvent. Z ?dry_run=true
+// zwracane są tylko błędy wierszy; w przeciwnym razie import jest
+// wszystko-albo-nic: przy jakimkolwiek błędzie nic nie jest zapisywane.
+func ImportEvents(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// 1) Uwierzytelnienie
+		claims, ok := auth.FromContext(r.Context())
+		if !ok || claims["role"] != "organizer" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		organizerID := int(claims["id"].(float64))
+		dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+
+		// 2) Plik i mapowanie kolumn
+		records, ok := readImportFile(w, r)
+		if !ok {
+			return
+		}
+		columns, ok := importColumns(w, r.FormValue("mapping"), records[0])
+		if !ok {
+			return
+		}
+		defaults := struct {
+			Timezone string `json:"timezone" validate:"max=64,timezone"`
+		}{r.FormValue("timezone")}
+		if !validateRequest(w, &defaults) {
+			return
+		}
+
+		// 3) Walidacja wszystkich wierszy (numer wiersza jak w arkuszu – nagłówek to 1)
+		type dataRow struct {
+			row    int
+			record []string
+		}
+		var data []dataRow
+		for i, record := range records[1:] {
+			if !blankRecord(record) {
+				data = append(data, dataRow{row: i + 2, record: record})
+			}
+		}
+		if len(data) == 0 {
+			writeFieldError(w, "file", "has no rows to import")
+			return
+		}
+		if len(data) > maxImportRows {
+			writeFieldError(w, "file", "must have at most "+strconv.Itoa(maxImportRows)+" rows")
+			return
+		}
+		var events []newEvent
+		rowErrors := []importError{}
+		for _, d := range data {
+			req, errs := importRequest(d.record, columns, defaults.Timezone)
+			if errs == nil {
+				var ev newEvent
+				if ev, errs = prepareEvent(db, req); errs == nil {
+					events = append(events, ev)
+				}
+			}
+			for _, fe := range errs {
+				rowErrors = append(rowErrors, importError{Row: d.row, FieldError: fe})
+			}
+		}
+		rows := len(data)
+		if dryRun {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"dry_run": true,
+				"rows":    rows,
+				"valid":   len(events),
+				"errors":  rowErrors,
+			})
+			return
+		}
+		if len(rowErrors) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{"rows": rows, "errors": rowErrors})
+			return
+		}
+
+		// 4) Zapis w jednej transakcji
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+		ids := make([]int, 0, len(events))
+		for _, ev := range events {
+			id, err := insertNewEvent(tx, organizerID, ev)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			ids = append(ids, id)
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"created": len(ids), "ids": ids})
+	}
+}
+
+// readImportFile czyta pole "file" formularza jako CSV albo TSV i zwraca
+// wszystkie rekordy (pierwszy to nagłówek). W razie błędu sam wysyła
+// odpowiedź i zwraca false.
+func readImportFile(w http.ResponseWriter, r *http.Request) ([][]string, bool) {
+	// Zapas na nagłówki części i pozostałe pola formularza
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes+64<<10)
+	if err := r.ParseMultipartForm(maxImportBytes); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "File too large (max "+strconv.Itoa(maxImportBytes>>20)+" MB)", http.StatusRequestEntityTooLarge)
+			return nil, false
+		}
+		http.Error(w, "Expected multipart/form-data body", http.StatusBadRequest)
+		return nil, false
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeFieldError(w, "file", "is required")
+		return nil, false
+	}
+	defer file.Close()
+
+	format := strings.ToLower(r.FormValue("format"))
+	if format == "" {
+		format = "csv"
+		if ext := strings.ToLower(filepath.Ext(header.Filename)); ext == ".tsv" || ext == ".tab" {
+			format = "tsv"
+		}
+	}
+	cr := csv.NewReader(file)
+	switch format {
+	case "csv":
+	case "tsv":
+		// W TSV cudzysłowy nie mają specjalnego znaczenia
+		cr.Comma = '\t'
+		cr.LazyQuotes = true
+	default:
+		writeFieldError(w, "format", "must be one of: csv, tsv")
+		return nil, false
+	}
+	// Arkusze często ucinają puste komórki na końcu wiersza
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		writeFieldError(w, "file", "is not valid "+strings.ToUpper(format)+": "+err.Error())
+		return nil, false
+	}
+	if len(records) == 0 {
+		writeFieldError(w, "file", "must start with a header row")
+		return nil, false
+	}
+	// Excel zapisuje CSV w UTF-8 z BOM
+	records[0][0] = strings.TrimPrefix(records[0][0], "\ufeff")
+	return records, true
+}
+
+// importColumns zwraca indeks kolumny dla każdego importowanego pola.
+// Bez mapowania kolumny dopasowywane są po nazwie pola (bez względu na
+// wielkość liter). W razie błędu sam wysyła 400 i zwraca false.
+func importColumns(w http.ResponseWriter, rawMapping string, header []string) (map[string]int, bool) {
+	byName := map[string]int{}
+	for i, h := range header {
+		byName[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	mapping := map[string]string{}
+	if rawMapping != "" {
+		if err := json.Unmarshal([]byte(rawMapping), &mapping); err != nil {
+			writeFieldError(w, "mapping", "must be a JSON object of field names to column headers")
+			return nil, false
+		}
+	} else {
+		for _, f := range importFields {
+			if _, ok := byName[f]; ok {
+				mapping[f] = f
+			}
+		}
+	}
+
+	known := map[string]bool{}
+	for _, f := range importFields {
+		known[f] = true
+	}
+	for field := range mapping {
+		if !known[field] {
+			writeFieldError(w, "mapping", "unknown field "+strconv.Quote(field)+" (allowed: "+strings.Join(importFields, ", ")+")")
+			return nil, false
+		}
+	}
+	columns := map[string]int{}
+	for _, field := range importFields {
+		column, ok := mapping[field]
+		if !ok {
+			continue
+		}
+		i, ok := byName[strings.ToLower(strings.TrimSpace(column))]
+		if !ok {
+			writeFieldError(w, "mapping."+field, "column "+strconv.Quote(column)+" not found in header")
+			return nil, false
+		}
+		columns[field] = i
+	}
+	for _, f := range []string{"title", "date_time"} {
+		if _, ok := columns[f]; !ok {
+			writeFieldError(w, "mapping."+f, "is required")
+			return nil, false
+		}
+	}
+	return columns, true
+}
+
+// importRequest buduje żądanie utworzenia wydarzenia z wiersza pliku
+// i sprawdza je regułami eventRequest. Etykiety w komórce oddziela się
+// przecinkami albo średnikami; pusta strefa to tz.
+func importRequest(record []string, columns map[string]int, tz string) (eventRequest, validate.Errors) {
+	cell := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var errs validate.Errors
+	number := func(field string) *int {
+		s := cell(field)
+		if s == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			errs = append(errs, validate.FieldError{Field: field, Message: "must be a whole number"})
+			return nil
+		}
+		return &n
+	}
+	req := eventRequest{
+		Title:       cell("title"),
+		Description: cell("description"),
+		DateTime:    cell("date_time"),
+		EndDateTime: cell("end_date_time"),
+		Timezone:    cell("timezone"),
+		ImageURL:    cell("image_url"),
+		Status:      strings.ToLower(cell("status")),
+		VenueID:     number("venue_id"),
+		Category:    strings.ToLower(cell("category")),
+		Tags: strings.FieldsFunc(cell("tags"), func(r rune) bool {
+			return r == ',' || r == ';'
+		}),
+	}
+	if req.Timezone == "" {
+		req.Timezone = tz
+	}
+	if capacity := number("capacity"); capacity != nil {
+		req.Capacity = *capacity
+	}
+	errs = append(errs, newEventErrors(req)...)
+	return req, errs
+}
+
+func blankRecord(record []string) bool {
+	for _, c := range record {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+// File: internal/handlers/import_test.go
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// importFile wysyła plik (i pola formularza) do ImportEvents.
+func importFile(db *sql.DB, ctx context.Context, query, filename, content string, fields map[string]string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		mw.WriteField(k, v)
+	}
+	fw, _ := mw.CreateFormFile("file", filename)
+	fw.Write([]byte(content))
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/events/import"+query, &body).WithContext(ctx)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	handlers.ImportEvents(db)(w, req)
+	return w
+}
+
+type importResponse struct {
+	Rows    int   `json:"rows"`
+	Valid   int   `json:"valid"`
+	Created int   `json:"created"`
+	IDs     []int `json:"ids"`
+	Errors  []struct {
+		Row   int    `json:"row"`
+		Field string `json:"field"`
+	} `json:"errors"`
+}
+
+func countEvents(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var n int
+	db.QueryRow("SELECT COUNT(*) FROM events").Scan(&n)
+	return n
+}
+
+func TestImportEvents_DryRunAndAllOrNothing(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	future := time.Now().AddDate(0, 1, 0).Format("2006-01-02T15:04")
+	csv := "\ufefftitle,date_time,capacity,category,tags\n" +
+		"Koncert," + future + ",100,concert,\"jazz, na żywo\"\n" +
+		",,,,\n" + // pusty wiersz z arkusza jest pomijany
+		"Bez daty,jutro,dużo,concert,\n" +
+		"Warsztat," + future + ",20,cooking,\n"
+
+	w := importFile(db, owner, "?dry_run=true", "events.csv", csv, nil)
+	var resp importResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.Rows != 3 || resp.Valid != 1 {
+		t.Fatalf("dry run: unexpected %d %s", w.Code, w.Body.String())
+	}
+	got := map[string]int{}
+	for _, e := range resp.Errors {
+		got[e.Field] = e.Row
+	}
+	if got["capacity"] != 4 || got["date_time"] != 4 || got["category"] != 5 {
+		t.Fatalf("dry run: unexpected row errors %s", w.Body.String())
+	}
+	if n := countEvents(t, db); n != 0 {
+		t.Fatalf("dry run must not create events, got %d", n)
+	}
+
+	// Bez dry_run błędy blokują cały import
+	if w := importFile(db, owner, "", "events.csv", csv, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("import with errors: expected 400, got %d", w.Code)
+	}
+	if n := countEvents(t, db); n != 0 {
+		t.Fatalf("failed import must not create events, got %d", n)
+	}
+
+	// Tylko organizator może importować
+	participant := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(7), "role": "participant"})
+	if w := importFile(db, participant, "", "events.csv", csv, nil); w.Code != http.StatusForbidden {
+		t.Fatalf("participant: expected 403, got %d", w.Code)
+	}
+}
+
+func TestImportEvents_TSVWithMapping(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	future := time.Now().AddDate(0, 1, 0).Format("2006-01-02T15:04")
+	tsv := "Nazwa\tStart\tMiejsca\tStatus\tEtykiety\n" +
+		"Koncert \"Lato\"\t" + future + "\t100\tpublished\trock;plener\n" +
+		"Meetup\t" + future + "\t30\t\t\n"
+	form := map[string]string{
+		"mapping":  `{"title":"Nazwa","date_time":"Start","capacity":"Miejsca","status":"Status","tags":"Etykiety"}`,
+		"timezone": "Europe/Warsaw",
+	}
+
+	if w := importFile(db, owner, "", "events.tsv", tsv, map[string]string{"mapping": `{"title":"Tytuł","date_time":"Start"}`}); w.Code != http.StatusBadRequest ||
+		!strings.Contains(w.Body.String(), "mapping.title") {
+		t.Fatalf("missing column: expected 400 on mapping.title, got %d %s", w.Code, w.Body.String())
+	}
+
+	w := importFile(db, owner, "", "events.tsv", tsv, form)
+	var resp importResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusCreated || resp.Created != 2 || len(resp.IDs) != 2 {
+		t.Fatalf("import: expected 201 with 2 events, got %d %s", w.Code, w.Body.String())
+	}
+	first := getEvent(t, db, owner, resp.IDs[0])
+	if first.Title != `Koncert "Lato"` || first.Status != "published" || first.OrganizerID != 1 ||
+		first.Timezone != "Europe/Warsaw" || first.LocalDate[:16] != future || len(first.Tags) != 2 || first.Tags[0] != "plener" {
+		t.Fatalf("unexpected imported event %+v", first)
+	}
+	if second := getEvent(t, db, owner, resp.IDs[1]); second.Status != "draft" {
+		t.Fatalf("status defaults to draft, got %q", second.Status)
+	}
+}
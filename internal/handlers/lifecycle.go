@@ -0,0 +1,91 @@
+// File: internal/handlers/lifecycle.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/bartbaranski/eventhub/internal/models"
+)
+
+// PublishEvent publikuje szkic albo przywraca przełożone wydarzenie.
+func PublishEvent(db *sql.DB) http.HandlerFunc {
+	return transitionEvent(db, models.EventPublished)
+}
+
+// CancelEvent odwołuje wydarzenie. Rezerwacje zostają, żeby można było
+// powiadomić uczestników.
+func CancelEvent(db *sql.DB) http.HandlerFunc {
+	return transitionEvent(db, models.EventCancelled)
+}
+
+// PostponeEvent oznacza wydarzenie jako przełożone (nowy termin ustawia się
+// przez PUT, a potem ponownie publikuje).
+func PostponeEvent(db *sql.DB) http.HandlerFunc {
+	return transitionEvent(db, models.EventPostponed)
+}
+
+// CompleteEvent oznacza wydarzenie jako zakończone.
+func CompleteEvent(db *sql.DB) http.HandlerFunc {
+	return transitionEvent(db, models.EventCompleted)
+}
+
+// transitionEvent zmienia status wydarzenia zgodnie z models.CanTransition
+// (właściciel lub admin). Zmiana trafia do historii jako rewizja, a
+// opcjonalny If-Match chroni przed nadpisaniem równoległej edycji.
+func transitionEvent(db *sql.DB, to string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		// 1) Uprawnienia i bieżący stan
+		current, actorID, ok := loadOwnedEvent(w, r, tx)
+		if !ok {
+			return
+		}
+		if !checkIfMatch(w, r, current.Version, false) {
+			return
+		}
+
+		// 2) Walidacja przejścia
+		if !models.CanTransition(current.Status, to) {
+			http.Error(w, "Cannot change status from "+current.Status+" to "+to, http.StatusConflict)
+			return
+		}
+
+		// 3) Zapis z warunkiem na wersję – chroni przed równoległą zmianą
+		changes := map[string]models.FieldChange{"status": {Old: current.Status, New: to}}
+		version, err := saveEventChanges(tx, current.ID, actorID, current.Version, changes, nil)
+		if err != nil {
+			writeSaveError(w, err)
+			return
+		}
+
+		// 4) Liczba rezerwacji – uczestnicy do powiadomienia
+		var reservations int
+		if err := tx.QueryRow(
+			"SELECT COUNT(*) FROM reservations WHERE event_id=$1", current.ID,
+		).Scan(&reservations); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("ETag", eventETag(version))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":           current.ID,
+			"status":       to,
+			"reservations": reservations,
+		})
+	}
+}
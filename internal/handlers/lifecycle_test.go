@@ -0,0 +1,122 @@
+// File: internal/handlers/lifecycle_test.go
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+)
+
+// doTransition wywołuje handler zmiany statusu dla wydarzenia id.
+func doTransition(h http.HandlerFunc, ctx context.Context, id int) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/events/"+strconv.Itoa(id)+"/x", nil).WithContext(ctx)
+	req = mux.SetURLVars(req, map[string]string{"id": strconv.Itoa(id)})
+	w := httptest.NewRecorder()
+	h(w, req)
+	return w
+}
+
+func TestEventLifecycle(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	other := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(2), "role": "organizer"})
+	id := insertEvent(t, db, "draft", 10)
+
+	// Szkic nie może zostać przełożony
+	if w := doTransition(handlers.PostponeEvent(db), owner, id); w.Code != http.StatusConflict {
+		t.Fatalf("draft->postponed: expected 409, got %d", w.Code)
+	}
+	// Cudze wydarzenie
+	if w := doTransition(handlers.PublishEvent(db), other, id); w.Code != http.StatusForbidden {
+		t.Fatalf("foreign publish: expected 403, got %d", w.Code)
+	}
+
+	// Nieaktualny If-Match
+	req := mux.SetURLVars(httptest.NewRequest("POST", "/events/x/publish", nil).WithContext(owner), map[string]string{"id": strconv.Itoa(id)})
+	req.Header.Set("If-Match", `"7"`)
+	w := httptest.NewRecorder()
+	handlers.PublishEvent(db)(w, req)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("stale If-Match: expected 412, got %d", w.Code)
+	}
+
+	steps := []struct {
+		h    http.HandlerFunc
+		want string
+	}{
+		{handlers.PublishEvent(db), "published"},
+		{handlers.PostponeEvent(db), "postponed"},
+		{handlers.PublishEvent(db), "published"},
+		{handlers.CancelEvent(db), "cancelled"},
+	}
+	for _, st := range steps {
+		w := doTransition(st.h, owner, id)
+		if w.Code != http.StatusOK {
+			t.Fatalf("-> %s: expected 200, got %d (%s)", st.want, w.Code, w.Body.String())
+		}
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp["status"] != st.want {
+			t.Fatalf("expected status %s, got %v", st.want, resp["status"])
+		}
+		if w.Header().Get("ETag") == "" {
+			t.Fatalf("-> %s: expected an ETag", st.want)
+		}
+	}
+
+	// Każde przejście jest rewizją w historii
+	revs := history(t, handlers.EventHistory(db), owner, id)
+	if len(revs) != len(steps) || revs[0].Changes["status"].New != "cancelled" || revs[0].Changes["status"].Old != "published" {
+		t.Fatalf("expected %d status revisions, got %+v", len(steps), revs)
+	}
+
+	// Admin może zmieniać status cudzych wydarzeń
+	admin := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(9), "role": "admin"})
+	foreign := insertEvent(t, db, "draft", 10)
+	if w := doTransition(handlers.PublishEvent(db), admin, foreign); w.Code != http.StatusOK {
+		t.Fatalf("admin publish: expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+
+	// Odwołane wydarzenie jest stanem końcowym
+	if w := doTransition(handlers.PublishEvent(db), owner, id); w.Code != http.StatusConflict {
+		t.Fatalf("cancelled->published: expected 409, got %d", w.Code)
+	}
+}
+
+func TestCancelKeepsReservations(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	id := insertEvent(t, db, "published", 10)
+	if _, err := db.Exec("INSERT INTO reservations(user_id, event_id, tickets) VALUES(7, $1, 2)", id); err != nil {
+		t.Fatal(err)
+	}
+
+	// Usunięcie wydarzenia z rezerwacjami jest blokowane
+	req := mux.SetURLVars(httptest.NewRequest("DELETE", "/events/x", nil).WithContext(owner), map[string]string{"id": strconv.Itoa(id)})
+	req.Header.Set("If-Match", `"1"`)
+	w := httptest.NewRecorder()
+	handlers.DeleteEvent(db)(w, req)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("delete with reservations: expected 409, got %d", w.Code)
+	}
+
+	w = doTransition(handlers.CancelEvent(db), owner, id)
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["reservations"] != float64(1) {
+		t.Fatalf("expected 1 retained reservation, got %v", resp)
+	}
+	var n int
+	db.QueryRow("SELECT COUNT(*) FROM reservations WHERE event_id=$1", id).Scan(&n)
+	if n != 1 {
+		t.Fatalf("reservations must be kept after cancel, got %d", n)
+	}
+}
@@ -0,0 +1,100 @@
+// File: internal/handlers/request.go
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bartbaranski/eventhub/internal/validate"
+)
+
+// maxBodyBytes ogranicza rozmiar body JSON przyjmowanego przez handlery.
+const maxBodyBytes = 1 << 20
+
+// decodeJSON dekoduje body żądania do dst (odrzucając nieznane pola i zbyt
+// duże body), a następnie waliduje je według tagów `validate`.
+// W razie błędu sam wysyła odpowiedź i zwraca false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	return decodeBody(w, r, dst) && validateRequest(w, dst)
+}
+
+// decodeBody to decodeJSON bez walidacji – dla handlerów, które sprawdzają
+// body same (np. z regułami zależnymi od innych pól).
+func decodeBody(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return false
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	// Po obiekcie JSON nie może być już nic więcej.
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		http.Error(w, "Request body must contain a single JSON object", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// validateRequest uruchamia walidację i zwraca 400 z listą wszystkich błędów pól.
+func validateRequest(w http.ResponseWriter, v interface{}) bool {
+	err := validate.Struct(v)
+	if err == nil {
+		return true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]interface{}{"errors": err})
+	return false
+}
+
+// writeFieldError wysyła 400 z błędem jednego pola, w formacie validateRequest.
+func writeFieldError(w http.ResponseWriter, field, message string) {
+	writeFieldErrors(w, validate.Errors{{Field: field, Message: message}})
+}
+
+// writeFieldErrors wysyła 400 z listą błędów pól, w formacie validateRequest.
+func writeFieldErrors(w http.ResponseWriter, errs validate.Errors) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]interface{}{"errors": errs})
+}
+
+// eventETag zwraca ETag wydarzenia w danej wersji.
+func eventETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// checkIfMatch porównuje nagłówek If-Match z bieżącą wersją zasobu.
+// Brak nagłówka przy required daje 428, niezgodność – 412. W razie błędu
+// sam wysyła odpowiedź i zwraca false.
+func checkIfMatch(w http.ResponseWriter, r *http.Request, version int, required bool) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		if required {
+			http.Error(w, "If-Match header is required", http.StatusPreconditionRequired)
+			return false
+		}
+		return true
+	}
+	current := eventETag(version)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		// If-Match używa silnego porównania – słabe ETagi (W/) nie pasują.
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	w.Header().Set("ETag", current)
+	http.Error(w, "Event has been modified, reload and try again", http.StatusPreconditionFailed)
+	return false
+}
@@ -25,7 +25,7 @@ func ListReservations(db *sql.DB) http.HandlerFunc {
 
 		// Query do bazy
 		rows, err := db.Query(
-			"SELECT id, user_id, event_id, tickets, created_at FROM reservations WHERE user_id = $1",
+			"SELECT id, user_id, event_id, tier_id, tickets, created_at FROM reservations WHERE user_id = $1",
 			userID,
 		)
 		if err != nil {
@@ -38,17 +38,38 @@ func ListReservations(db *sql.DB) http.HandlerFunc {
 		var out []models.Reservation
 		for rows.Next() {
 			var rsv models.Reservation
+			var tierID sql.NullInt64
 			if err := rows.Scan(
 				&rsv.ID,
 				&rsv.UserID,
 				&rsv.EventID,
+				&tierID,
 				&rsv.Tickets,
 				&rsv.CreatedAt,
 			); err != nil {
 				continue
 			}
+			if tierID.Valid {
+				id := int(tierID.Int64)
+				rsv.TierID = &id
+			}
 			out = append(out, rsv)
 		}
+		rows.Close()
+
+		// Dołącz oczekujące przekazania, aby właściciel mógł je wycofać
+		transfers, err := pendingTransfers(db, "t.from_user_id=$1", userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for i := range transfers {
+			for k := range out {
+				if out[k].ID == transfers[i].ReservationID {
+					out[k].Transfer = &transfers[i]
+				}
+			}
+		}
 
 		// Zwróć JSON
 		json.NewEncoder(w).Encode(out)
@@ -56,10 +77,12 @@ func ListReservations(db *sql.DB) http.HandlerFunc {
 }
 
 // CreateReservation tworzy nową rezerwację dla zalogowanego użytkownika.
+// Na wydarzenie z pulami biletów rezerwacja wskazuje pulę (tier_id).
 func CreateReservation(db *sql.DB) http.HandlerFunc {
 	type request struct {
-		EventID int `json:"event_id"`
-		Tickets int `json:"tickets"`
+		EventID int  `json:"event_id" validate:"min=1"`
+		TierID  *int `json:"tier_id" validate:"min=1"`
+		Tickets int  `json:"tickets" validate:"min=1,max=50"`
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -75,22 +98,187 @@ func CreateReservation(db *sql.DB) http.HandlerFunc {
 
 		// 2) Dekoduj body
 		var req request
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
-		// 3) Wstaw do bazy
-		_, err := db.Exec(
-			"INSERT INTO reservations(user_id, event_id, tickets) VALUES($1,$2,$3)",
-			userID, req.EventID, req.Tickets,
-		)
+		tx, err := db.Begin()
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		defer tx.Rollback()
+
+		// 3) Rezerwować można tylko opublikowane wydarzenia. Pusty UPDATE
+		// blokuje wiersz wydarzenia do końca transakcji, więc równoległe
+		// rezerwacje sprawdzają limity po kolei, a nie na tym samym stanie.
+		if _, err := tx.Exec("UPDATE events SET capacity=capacity WHERE id=$1", req.EventID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var status string
+		var capacity int
+		if err := tx.QueryRow(
+			"SELECT status, capacity FROM events WHERE id=$1 AND deleted_at IS NULL", req.EventID,
+		).Scan(&status, &capacity); err != nil {
+			http.Error(w, "Event not found", http.StatusNotFound)
+			return
+		}
+		if status != models.EventPublished {
+			http.Error(w, "Event is not open for reservations", http.StatusConflict)
+			return
+		}
+
+		// 4) Wolne miejsca i limity organizatora
+		var booked int
+		if err := tx.QueryRow(
+			"SELECT COALESCE(SUM(tickets), 0) FROM reservations WHERE event_id=$1", req.EventID,
+		).Scan(&booked); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if booked+req.Tickets > capacity {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":        "Not enough tickets left",
+				"tickets_left": max(capacity-booked, 0),
+			})
+			return
+		}
+		if !checkTier(w, tx, req.EventID, req.TierID, req.Tickets) ||
+			!checkBookingRules(w, tx, req.EventID, userID, req.Tickets) ||
+			!checkTierLimit(w, tx, req.TierID, userID, req.Tickets) {
+			return
+		}
+
+		// 5) Wstaw do bazy
+		if _, err := tx.Exec(
+			"INSERT INTO reservations(user_id, event_id, tier_id, tickets) VALUES($1,$2,$3,$4)",
+			userID, req.EventID, req.TierID, req.Tickets,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
 
 		w.WriteHeader(http.StatusCreated)
 		json.NewEncoder(w).Encode(map[string]string{"status": "created"})
 	}
 }
+
+// checkBookingRules sprawdza reguły organizatora dla użytkownika, który ma
+// otrzymać kolejne miejsca na wydarzenie (rezerwacja lub przyjęcie
+// przekazania): potwierdzony e-mail i limit miejsc na konto liczony łącznie
+// ze wszystkimi jego rezerwacjami. Przy naruszeniu wysyła odpowiedź i zwraca
+// false. Wywoływać w transakcji, w której miejsca są przydzielane.
+func checkBookingRules(w http.ResponseWriter, q queryRower, eventID, userID, tickets int) bool {
+	var limit sql.NullInt64
+	var requireVerified bool
+	if err := q.QueryRow(
+		"SELECT max_tickets_per_user, require_verified_email FROM events WHERE id=$1", eventID,
+	).Scan(&limit, &requireVerified); err != nil {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return false
+	}
+
+	if requireVerified {
+		var verifiedAt sql.NullTime
+		err := q.QueryRow("SELECT email_verified_at FROM users WHERE id=$1", userID).Scan(&verifiedAt)
+		if err != nil && err != sql.ErrNoRows {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return false
+		}
+		if !verifiedAt.Valid {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": "Email address must be verified to book this event",
+				"code":  "email_not_verified",
+			})
+			return false
+		}
+	}
+
+	if limit.Valid {
+		var held int
+		if err := q.QueryRow(
+			"SELECT COALESCE(SUM(tickets), 0) FROM reservations WHERE event_id=$1 AND user_id=$2", eventID, userID,
+		).Scan(&held); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return false
+		}
+		if held+tickets > int(limit.Int64) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":  "Ticket limit per user exceeded",
+				"code":   "ticket_limit",
+				"limit":  limit.Int64,
+				"booked": held,
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// SetEventLimits ustawia reguły rezerwacji wydarzenia (właściciel lub admin):
+// limit miejsc na konto (null – bez limitu) i wymóg potwierdzonego e-maila.
+// Dotyczą nowych rezerwacji i przekazań; istniejących rezerwacji nie zmieniają.
+// Zmiana trafia do historii wydarzenia; If-Match jest opcjonalny.
+func SetEventLimits(db *sql.DB) http.HandlerFunc {
+	type request struct {
+		MaxTicketsPerUser    *int `json:"max_tickets_per_user" validate:"min=1,max=100000"`
+		RequireVerifiedEmail bool `json:"require_verified_email"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var req request
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		// 1) Uprawnienia i wersja, którą widział klient
+		current, actorID, ok := loadOwnedEvent(w, r, tx)
+		if !ok {
+			return
+		}
+		if !checkIfMatch(w, r, current.Version, false) {
+			return
+		}
+
+		// 2) Zapis zmian jako rewizja
+		updated := current
+		updated.MaxTicketsPerUser = req.MaxTicketsPerUser
+		updated.RequireVerifiedEmail = req.RequireVerifiedEmail
+		changes := diffSnapshots(eventSnapshot(current), eventSnapshot(updated))
+		version, err := saveEventChanges(tx, current.ID, actorID, current.Version, changes, nil)
+		if err != nil {
+			writeSaveError(w, err)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("ETag", eventETag(version))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":                     current.ID,
+			"max_tickets_per_user":   req.MaxTicketsPerUser,
+			"require_verified_email": req.RequireVerifiedEmail,
+		})
+	}
+}
@@ -6,46 +6,37 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/bartbaranski/eventhub/internal/auth"
 	"github.com/bartbaranski/eventhub/internal/handlers"
 	"github.com/bartbaranski/eventhub/internal/models"
-	"github.com/bartbaranski/eventhub/internal/storage"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
 )
 
-func newResDB(t *testing.T) *sql.DB {
-	db := storage.NewTestDB()
-	stmts := []string{
-		`CREATE TABLE events (
-            id INTEGER PRIMARY KEY AUTOINCREMENT,
-            title TEXT NOT NULL,
-            description TEXT,
-            date DATETIME NOT NULL,
-            capacity INTEGER NOT NULL,
-            organizer_id INTEGER NOT NULL
-        );`,
-		`CREATE TABLE reservations (
-            id INTEGER PRIMARY KEY AUTOINCREMENT,
-            user_id INTEGER NOT NULL,
-            event_id INTEGER NOT NULL,
-            tickets INTEGER NOT NULL,
-            created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-        );`,
-	}
-	for _, s := range stmts {
-		if _, err := db.Exec(s); err != nil {
-			t.Fatalf("failed to exec schema: %v", err)
-		}
+// insertEvent wstawia wydarzenie organizatora 1 o podanym statusie i zwraca jego ID.
+func insertEvent(t *testing.T, db *sql.DB, status string, capacity int) int {
+	t.Helper()
+	res, err := db.Exec(
+		`INSERT INTO events(title, description, date, capacity, organizer_id, image_url, status)
+		 VALUES('Event', '', $1, $2, 1, '', $3)`,
+		time.Now().AddDate(0, 1, 0).UTC(), capacity, status,
+	)
+	if err != nil {
+		t.Fatalf("insert event: %v", err)
 	}
-	return db
+	id, _ := res.LastInsertId()
+	return int(id)
 }
 
 func TestCreateAndListReservations(t *testing.T) {
-	db := newResDB(t)
+	db := newEventDB(t)
 	hCreate := handlers.CreateReservation(db)
 
 	// zakładając user.id = 7
@@ -53,7 +44,8 @@ func TestCreateAndListReservations(t *testing.T) {
 	// <-- tutaj wstrzykujemy przez auth.NewContext
 	ctx := auth.NewContext(context.Background(), claims)
 
-	reqBody := `{"event_id":99,"tickets":3}`
+	eventID := insertEvent(t, db, "published", 100)
+	reqBody := fmt.Sprintf(`{"event_id":%d,"tickets":3}`, eventID)
 	req := httptest.NewRequest("POST", "/reservations", bytes.NewBufferString(reqBody)).WithContext(ctx)
 	w := httptest.NewRecorder()
 	hCreate(w, req)
@@ -75,7 +67,158 @@ func TestCreateAndListReservations(t *testing.T) {
 	if len(rs) != 1 {
 		t.Fatalf("expected 1 reservation, got %d", len(rs))
 	}
-	if rs[0].UserID != 7 || rs[0].EventID != 99 || rs[0].Tickets != 3 {
+	if rs[0].UserID != 7 || rs[0].EventID != eventID || rs[0].Tickets != 3 {
 		t.Errorf("unexpected reservation: %+v", rs[0])
 	}
 }
+
+func TestCreateReservation_InvalidTickets(t *testing.T) {
+	db := newEventDB(t)
+	h := handlers.CreateReservation(db)
+	ctx := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(7)})
+
+	req := httptest.NewRequest("POST", "/reservations", bytes.NewBufferString(`{"event_id":1,"tickets":0}`)).WithContext(ctx)
+	w := httptest.NewRecorder()
+	h(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestCreateReservation_RequiresPublishedEvent(t *testing.T) {
+	db := newEventDB(t)
+	h := handlers.CreateReservation(db)
+	ctx := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(7)})
+
+	cases := map[string]int{
+		"draft":     http.StatusConflict,
+		"cancelled": http.StatusConflict,
+		"postponed": http.StatusConflict,
+	}
+	for status, want := range cases {
+		id := insertEvent(t, db, status, 10)
+		body := fmt.Sprintf(`{"event_id":%d,"tickets":1}`, id)
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest("POST", "/reservations", bytes.NewBufferString(body)).WithContext(ctx))
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d", status, want, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest("POST", "/reservations", bytes.NewBufferString(`{"event_id":999,"tickets":1}`)).WithContext(ctx))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing event: expected 404, got %d", w.Code)
+	}
+}
+
+func TestCreateReservation_Capacity(t *testing.T) {
+	db := newEventDB(t)
+	h := handlers.CreateReservation(db)
+	eventID := insertEvent(t, db, "published", 5)
+
+	for i, c := range []struct {
+		user, tickets, want, left int
+	}{
+		{7, 3, http.StatusCreated, 0},
+		{8, 3, http.StatusConflict, 2},
+		{8, 2, http.StatusCreated, 0},
+		{9, 1, http.StatusConflict, 0},
+	} {
+		ctx := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(c.user)})
+		w := call(h, "POST", "/reservations", ctx, 0, fmt.Sprintf(`{"event_id":%d,"tickets":%d}`, eventID, c.tickets))
+		if w.Code != c.want {
+			t.Fatalf("case %d: expected %d, got %d %s", i, c.want, w.Code, w.Body.String())
+		}
+		if c.want == http.StatusConflict {
+			var body struct {
+				TicketsLeft int `json:"tickets_left"`
+			}
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if body.TicketsLeft != c.left {
+				t.Errorf("case %d: expected %d tickets left, got %d", i, c.left, body.TicketsLeft)
+			}
+		}
+	}
+}
+
+func TestReservationLimits(t *testing.T) {
+	db, eventID := seedTransfer(t) // rezerwacja 1: użytkownik 5, 2 miejsca
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	ola := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(5), "role": "participant"})
+	jan := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(6), "role": "participant"})
+
+	limits := handlers.SetEventLimits(db)
+	if w := call(limits, "PUT", "/events/x/limits", ola, eventID, `{"max_tickets_per_user":2}`); w.Code != http.StatusForbidden {
+		t.Fatalf("participant: expected 403, got %d", w.Code)
+	}
+	if w := call(limits, "PUT", "/events/x/limits", owner, eventID, `{"max_tickets_per_user":0}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("zero limit: expected 400, got %d", w.Code)
+	}
+	if w := call(limits, "PUT", "/events/x/limits", owner, eventID, `{"max_tickets_per_user":3}`); w.Code != http.StatusOK || w.Header().Get("ETag") != `"2"` {
+		t.Fatalf("set limit: expected 200 with ETag \"2\", got %d %s", w.Code, w.Body.String())
+	}
+
+	// Zmiana reguł jest rewizją i respektuje If-Match
+	revs := history(t, handlers.EventHistory(db), owner, eventID)
+	if len(revs) != 1 || revs[0].Changes["max_tickets_per_user"].New != float64(3) {
+		t.Fatalf("history: unexpected %+v", revs)
+	}
+	req := mux.SetURLVars(
+		httptest.NewRequest("PUT", "/events/x/limits", bytes.NewBufferString(`{"max_tickets_per_user":1}`)).WithContext(owner),
+		map[string]string{"id": strconv.Itoa(eventID)},
+	)
+	req.Header.Set("If-Match", `"1"`)
+	stale := httptest.NewRecorder()
+	limits(stale, req)
+	if stale.Code != http.StatusPreconditionFailed {
+		t.Fatalf("stale If-Match: expected 412, got %d", stale.Code)
+	}
+
+	// Limit liczy się łącznie ze wszystkimi rezerwacjami konta
+	create := handlers.CreateReservation(db)
+	reserve := func(ctx context.Context, tickets int) *httptest.ResponseRecorder {
+		return call(create, "POST", "/reservations", ctx, 0, fmt.Sprintf(`{"event_id":%d,"tickets":%d}`, eventID, tickets))
+	}
+	w := reserve(ola, 2)
+	var body struct {
+		Code   string `json:"code"`
+		Limit  int    `json:"limit"`
+		Booked int    `json:"booked"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusConflict || body.Code != "ticket_limit" || body.Limit != 3 || body.Booked != 2 {
+		t.Fatalf("over limit: unexpected %d %s", w.Code, w.Body.String())
+	}
+	if w := reserve(ola, 1); w.Code != http.StatusCreated {
+		t.Fatalf("within limit: expected 201, got %d", w.Code)
+	}
+	if w := reserve(jan, 2); w.Code != http.StatusCreated {
+		t.Fatalf("other user: expected 201, got %d", w.Code)
+	}
+
+	// Przekazanie też nie może przekroczyć limitu adresata (2 + 2 > 3)
+	verifyEmails(t, db, 6)
+	w = call(handlers.CreateTransfer(db), "POST", "/reservations/x/transfer", ola, 1, `{"email":"jan@example.com"}`)
+	var tr models.ReservationTransfer
+	json.Unmarshal(w.Body.Bytes(), &tr)
+	if w := callToken(handlers.AcceptTransfer(db), "POST", jan, tr.Token); w.Code != http.StatusConflict {
+		t.Fatalf("transfer over limit: expected 409, got %d %s", w.Code, w.Body.String())
+	}
+	db.Exec("UPDATE users SET email_verified_at=NULL WHERE id=6")
+
+	// Wymóg potwierdzonego adresu
+	if w := call(limits, "PUT", "/events/x/limits", owner, eventID, `{"require_verified_email":true}`); w.Code != http.StatusOK {
+		t.Fatalf("require verified: expected 200, got %d", w.Code)
+	}
+	w = reserve(jan, 1)
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusForbidden || body.Code != "email_not_verified" {
+		t.Fatalf("unverified: unexpected %d %s", w.Code, w.Body.String())
+	}
+	db.Exec("UPDATE users SET email_verified_at=CURRENT_TIMESTAMP WHERE id=6")
+	if w := reserve(jan, 5); w.Code != http.StatusCreated {
+		t.Fatalf("verified, limit cleared: expected 201, got %d %s", w.Code, w.Body.String())
+	}
+}
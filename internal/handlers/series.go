@@ -0,0 +1,660 @@
+// File: internal/handlers/series.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/bartbaranski/eventhub/internal/recurrence"
+	"github.com/bartbaranski/eventhub/internal/validate"
+	"github.com/gorilla/mux"
+)
+
+// maxSeriesOccurrences ogranicza liczbę wystąpień materializowanych dla jednej serii.
+const maxSeriesOccurrences = 366
+
+// Zakresy zmian wystąpienia serii (?scope= przy PUT/PATCH/DELETE /events/{id}).
+const (
+	scopeThis      = "this"
+	scopeFollowing = "following"
+	scopeAll       = "all"
+)
+
+// errSeriesDateShift: zmiana terminu dla wielu wystąpień może przesunąć tylko
+// godzinę – zmiana dnia rozjechałaby się z regułą (do tego służy PUT /series/{id}).
+var errSeriesDateShift = errors.New("only the time of day can be changed for following or all occurrences")
+
+// seriesTemplateColumns mapuje pola rewizji na kolumny szablonu w event_series.
+var seriesTemplateColumns = map[string]string{
+	"title":       "title",
+	"timezone":    "timezone",
+	"description": "description",
+	"capacity":    "capacity",
+	"image_url":   "image_url",
+	"venue_id":    "venue_id",
+	"category":    "category",
+	"tags":        "tags", // etykiety rozdzielone przecinkami
+}
+
+// seriesColumns to kolumny serii w kolejności oczekiwanej przez scanSeries.
+const seriesColumns = `id, organizer_id, rrule, dtstart, exdates, title, COALESCE(description, ''),
+	capacity, COALESCE(image_url, ''), status, COALESCE(timezone, 'UTC'), duration, venue_id,
+	COALESCE(category, ''), COALESCE(tags, '')`
+
+// seriesRequest to body tworzenia serii: szablon wystąpienia, reguła i wyjątki.
+type seriesRequest struct {
+	Title       string   `json:"title" validate:"required,max=255"`
+	Description string   `json:"description" validate:"max=5000"`
+	DateTime    string   `json:"date_time" validate:"required,datetime,future"` // pierwsze wystąpienie (DTSTART)
+	EndDateTime string   `json:"end_date_time" validate:"datetime"`             // koniec pierwszego wystąpienia
+	Timezone    string   `json:"timezone" validate:"max=64,timezone"`           // strefa, w której rozwijamy regułę
+	Capacity    int      `json:"capacity" validate:"min=1,max=100000"`
+	ImageURL    string   `json:"image_url" validate:"max=2048,url=http|https"`
+	Status      string   `json:"status" validate:"oneof=draft|published"`
+	VenueID     *int     `json:"venue_id" validate:"min=1"`
+	Category    string   `json:"category" validate:"oneof=concert|workshop|conference|meetup|sport|theatre|exhibition|festival|other"`
+	Tags        []string `json:"tags" validate:"max=10"`
+	RRule       string   `json:"rrule" validate:"required,max=500"`
+	ExDates     []string `json:"exdates" validate:"max=366"` // terminy pominięte (jak date_time)
+}
+
+// seriesRuleRequest to body zmiany reguły istniejącej serii.
+type seriesRuleRequest struct {
+	RRule   string   `json:"rrule" validate:"required,max=500"`
+	ExDates []string `json:"exdates" validate:"max=366"`
+}
+
+func scanSeries(row rowScanner, s *models.EventSeries) error {
+	var exdates, tags string
+	var duration, venueID sql.NullInt64
+	if err := row.Scan(
+		&s.ID, &s.OrganizerID, &s.RRule, &s.Start, &exdates,
+		&s.Title, &s.Description, &s.Capacity, &s.ImageURL, &s.Status,
+		&s.Timezone, &duration, &venueID, &s.Category, &tags,
+	); err != nil {
+		return err
+	}
+	s.Tags = []string{}
+	if tags != "" {
+		s.Tags = strings.Split(tags, ",")
+	}
+	if venueID.Valid {
+		id := int(venueID.Int64)
+		s.VenueID = &id
+	}
+	s.Start = s.Start.UTC()
+	s.Duration = int(duration.Int64)
+	var err error
+	s.ExDates, err = recurrence.ParseDates(exdates)
+	if s.ExDates == nil {
+		s.ExDates = []time.Time{}
+	}
+	return err
+}
+
+// expandSeries parsuje regułę i wyjątki i zwraca terminy wystąpień (w UTC).
+// Reguła jest rozwijana w strefie start, więc godzina lokalna wystąpień
+// jest stała także po zmianie czasu. W razie błędu sam wysyła 400
+// (z nazwą pola) i zwraca false.
+func expandSeries(w http.ResponseWriter, rrule string, start time.Time, exdateStrs []string) (recurrence.Rule, []time.Time, []time.Time, bool) {
+	rule, err := recurrence.Parse(rrule)
+	if err != nil {
+		writeFieldError(w, "rrule", err.Error())
+		return rule, nil, nil, false
+	}
+	if !rule.Bounded() {
+		writeFieldError(w, "rrule", "must end (use COUNT or UNTIL)")
+		return rule, nil, nil, false
+	}
+	exdates := make([]time.Time, 0, len(exdateStrs))
+	for _, s := range exdateStrs {
+		t, err := validate.ParseDateTime(s, start.Location())
+		if err != nil {
+			writeFieldError(w, "exdates", "must be RFC 3339 date-times or use format YYYY-MM-DDTHH:MM")
+			return rule, nil, nil, false
+		}
+		exdates = append(exdates, t)
+	}
+	slots, err := rule.Expand(start, exdates, maxSeriesOccurrences)
+	if err != nil {
+		writeFieldError(w, "rrule", "must produce at most "+strconv.Itoa(maxSeriesOccurrences)+" occurrences")
+		return rule, nil, nil, false
+	}
+	if len(slots) == 0 {
+		writeFieldError(w, "rrule", "produces no occurrences")
+		return rule, nil, nil, false
+	}
+	for i := range slots {
+		slots[i] = slots[i].UTC()
+	}
+	return rule, exdates, slots, true
+}
+
+// insertOccurrence tworzy wystąpienie serii s w terminie at (według szablonu).
+func insertOccurrence(tx *sql.Tx, s models.EventSeries, at time.Time) (int, error) {
+	var end interface{}
+	if s.Duration > 0 {
+		end = at.Add(time.Duration(s.Duration) * time.Second)
+	}
+	var id int
+	err := tx.QueryRow(
+		`INSERT INTO events(title, description, date, end_date, timezone, capacity, organizer_id, image_url, status, series_id, recurrence_id, venue_id, category)
+		 VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $3, $11, $12) RETURNING id`,
+		s.Title, s.Description, at, end, s.Timezone, s.Capacity, s.OrganizerID, s.ImageURL, s.Status, s.ID, s.VenueID, s.Category,
+	).Scan(&id)
+	if err != nil {
+		return id, err
+	}
+	return id, setEventTags(tx, id, s.Tags)
+}
+
+// insertSeries zapisuje serię s i ustawia jej ID.
+func insertSeries(tx *sql.Tx, s *models.EventSeries) error {
+	var duration interface{}
+	if s.Duration > 0 {
+		duration = s.Duration
+	}
+	return tx.QueryRow(
+		`INSERT INTO event_series(organizer_id, rrule, dtstart, exdates, title, description, capacity, image_url, status, timezone, duration, venue_id, category, tags)
+		 VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id`,
+		s.OrganizerID, s.RRule, s.Start, recurrence.FormatDates(s.ExDates),
+		s.Title, s.Description, s.Capacity, s.ImageURL, s.Status, s.Timezone, duration, s.VenueID,
+		s.Category, strings.Join(s.Tags, ","),
+	).Scan(&s.ID)
+}
+
+// CreateSeries tworzy serię wydarzeń cyklicznych i od razu materializuje
+// jej wystąpienia jako zwykłe wydarzenia (tylko organizator).
+func CreateSeries(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// 1) Uwierzytelnienie
+		claims, ok := auth.FromContext(r.Context())
+		if !ok || claims["role"] != "organizer" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		// 2) Dekodowanie requestu, reguła i wyjątki
+		var req seriesRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		tz := req.Timezone
+		if tz == "" {
+			tz = defaultTimezone
+		}
+		start, end, ok := eventTimes(w, req.DateTime, req.EndDateTime, tz, time.Time{})
+		if !ok {
+			return
+		}
+		rule, exdates, slots, ok := expandSeries(w, req.RRule, start.In(location(tz)), req.ExDates)
+		if !ok || !checkVenue(w, db, req.VenueID, req.Capacity) {
+			return
+		}
+		tags, ok := normalizeTags(w, req.Tags)
+		if !ok {
+			return
+		}
+
+		s := models.EventSeries{
+			OrganizerID: int(claims["id"].(float64)),
+			RRule:       rule.String(),
+			Start:       start,
+			ExDates:     exdates,
+			Title:       req.Title,
+			Description: req.Description,
+			Capacity:    req.Capacity,
+			ImageURL:    req.ImageURL,
+			Status:      req.Status,
+			Timezone:    tz,
+			VenueID:     req.VenueID,
+			Category:    req.Category,
+			Tags:        tags,
+		}
+		if end != nil {
+			s.Duration = int(end.Sub(start) / time.Second)
+		}
+		if s.Status == "" {
+			s.Status = models.EventDraft
+		}
+
+		// 3) Seria i wszystkie wystąpienia w jednej transakcji
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		if err := insertSeries(tx, &s); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		ids := make([]int, 0, len(slots))
+		for _, at := range slots {
+			id, err := insertOccurrence(tx, s, at)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			ids = append(ids, id)
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": s.ID, "rrule": s.RRule, "occurrences": ids})
+	}
+}
+
+// GetSeries zwraca serię z jej (nieusuniętymi) wystąpieniami.
+// Szkice widzi tylko właściciel.
+func GetSeries(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		var s models.EventSeries
+		if err := scanSeries(db.QueryRow(
+			"SELECT "+seriesColumns+" FROM event_series WHERE id=$1", id,
+		), &s); err != nil {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		owner := isOwner(r, s.OrganizerID)
+		if s.Status == models.EventDraft && !owner {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+
+		rows, err := db.Query(
+			"SELECT "+eventColumns+" FROM events WHERE series_id=$1 AND deleted_at IS NULL ORDER BY date", id,
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+		s.Occurrences = []models.Event{}
+		for rows.Next() {
+			var e models.Event
+			if err := scanEvent(rows, &e); err != nil {
+				continue
+			}
+			if e.Status != models.EventDraft || owner {
+				s.Occurrences = append(s.Occurrences, e)
+			}
+		}
+		rows.Close()
+		if err := attachTags(db, s.Occurrences,
+			"SELECT event_id, tag FROM event_tags WHERE event_id IN (SELECT id FROM events WHERE series_id=$1) ORDER BY tag", id,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s)
+	}
+}
+
+// UpdateSeries zmienia regułę i wyjątki serii (tylko właściciel). Przyszłe
+// wystąpienia są generowane na nowo: brakujące terminy dochodzą, a te, które
+// wypadły z reguły, są usuwane – albo odwoływane, jeśli mają już rezerwacje.
+// Minione wystąpienia pozostają bez zmian.
+func UpdateSeries(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// 1) Uwierzytelnienie
+		claims, ok := auth.FromContext(r.Context())
+		if !ok || claims["role"] != "organizer" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		organizerID := int(claims["id"].(float64))
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		// 2) Dekodowanie requestu
+		var req seriesRuleRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		// 3) Seria i nowe terminy
+		var s models.EventSeries
+		if err := scanSeries(tx.QueryRow(
+			"SELECT "+seriesColumns+" FROM event_series WHERE id=$1", id,
+		), &s); err != nil || s.OrganizerID != organizerID {
+			http.Error(w, "Forbidden or not found", http.StatusForbidden)
+			return
+		}
+		rule, exdates, slots, ok := expandSeries(w, req.RRule, s.Start.In(location(s.Timezone)), req.ExDates)
+		if !ok {
+			return
+		}
+		wanted := map[int64]bool{}
+		for _, at := range slots {
+			wanted[at.Unix()] = true
+		}
+
+		// 4) Wystąpienia, które wypadły z reguły
+		occurrences, err := seriesOccurrences(tx, id, time.Time{})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		now := time.Now().UTC()
+		existing := map[int64]bool{}
+		removed, cancelled := 0, 0
+		for _, e := range occurrences {
+			existing[e.RecurrenceID.Unix()] = true
+			if wanted[e.RecurrenceID.Unix()] || !e.Date.After(now) {
+				continue
+			}
+			var reservations int
+			if err := tx.QueryRow(
+				"SELECT COUNT(*) FROM reservations WHERE event_id=$1", e.ID,
+			).Scan(&reservations); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			switch {
+			case reservations == 0:
+				_, err = tx.Exec(
+					"UPDATE events SET deleted_at=$1, version=version+1, updated_at=$1 WHERE id=$2", now, e.ID,
+				)
+				removed++
+			case models.CanTransition(e.Status, models.EventCancelled):
+				// Uczestnicy muszą się dowiedzieć – odwołujemy zamiast usuwać.
+				_, err = tx.Exec(
+					"UPDATE events SET status=$1, version=version+1, sequence=sequence+1, updated_at=$2 WHERE id=$3",
+					models.EventCancelled, now, e.ID,
+				)
+				cancelled++
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// 5) Nowe terminy (tylko przyszłe)
+		created := 0
+		for _, at := range slots {
+			if existing[at.Unix()] || !at.After(now) {
+				continue
+			}
+			if _, err := insertOccurrence(tx, s, at); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			created++
+		}
+
+		if _, err := tx.Exec(
+			"UPDATE event_series SET rrule=$1, exdates=$2 WHERE id=$3",
+			rule.String(), recurrence.FormatDates(exdates), id,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": id, "rrule": rule.String(), "created": created, "removed": removed, "cancelled": cancelled,
+		})
+	}
+}
+
+// seriesOccurrences zwraca nieusunięte wystąpienia serii od terminu from
+// (według recurrence_id; zerowy from – wszystkie).
+func seriesOccurrences(tx *sql.Tx, seriesID int, from time.Time) ([]models.Event, error) {
+	rows, err := tx.Query(
+		"SELECT "+eventColumns+" FROM events WHERE series_id=$1 AND deleted_at IS NULL AND recurrence_id>=$2 ORDER BY recurrence_id",
+		seriesID, from,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.Event
+	for rows.Next() {
+		var e models.Event
+		if err := scanEvent(rows, &e); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	err = attachTags(tx, out,
+		"SELECT event_id, tag FROM event_tags WHERE event_id IN (SELECT id FROM events WHERE series_id=$1) ORDER BY tag", seriesID,
+	)
+	return out, err
+}
+
+// seriesScope odczytuje ?scope= (domyślnie "this") i sprawdza, czy zakres
+// pasuje do wydarzenia. W razie błędu sam wysyła 400 i zwraca false.
+func seriesScope(w http.ResponseWriter, r *http.Request, e models.Event) (string, bool) {
+	scope := r.URL.Query().Get("scope")
+	switch scope {
+	case "":
+		return scopeThis, true
+	case scopeThis, scopeFollowing, scopeAll:
+	default:
+		http.Error(w, "scope must be one of: this, following, all", http.StatusBadRequest)
+		return "", false
+	}
+	if scope != scopeThis && e.SeriesID == nil {
+		http.Error(w, "Event is not part of a series", http.StatusBadRequest)
+		return "", false
+	}
+	return scope, true
+}
+
+// endSeriesBefore kończy regułę serii s przed terminem at (UNTIL) i zostawia
+// jej tylko wcześniejsze wyjątki. Zwraca resztę reguły od at (z pozostałym
+// COUNT) i późniejsze wyjątki – to, co przejmie ewentualna nowa seria.
+func endSeriesBefore(tx *sql.Tx, s models.EventSeries, at time.Time) (recurrence.Rule, []time.Time, error) {
+	rule, err := recurrence.Parse(s.RRule)
+	if err != nil {
+		return rule, nil, err
+	}
+	tail := rule
+	if rule.Count > 0 {
+		// COUNT liczy wszystkie terminy, także wykluczone
+		all, err := rule.Expand(s.Start.In(location(s.Timezone)), nil, rule.Count)
+		if err != nil {
+			return rule, nil, err
+		}
+		before := sort.Search(len(all), func(i int) bool { return !all[i].Before(at) })
+		tail.Count = rule.Count - before
+	}
+	rule.Count = 0
+	rule.Until = at.Add(-time.Second)
+
+	var headEx, tailEx []time.Time
+	for _, t := range s.ExDates {
+		if t.Before(at) {
+			headEx = append(headEx, t)
+		} else {
+			tailEx = append(tailEx, t)
+		}
+	}
+	_, err = tx.Exec(
+		"UPDATE event_series SET rrule=$1, exdates=$2 WHERE id=$3",
+		rule.String(), recurrence.FormatDates(headEx), s.ID,
+	)
+	return tail, tailEx, err
+}
+
+// splitSeries dzieli serię s w terminie at: stara seria kończy się przed at,
+// a wystąpienia od at przechodzą do nowej serii. Zwraca nową serię.
+func splitSeries(tx *sql.Tx, s models.EventSeries, at time.Time) (models.EventSeries, error) {
+	tail, tailEx, err := endSeriesBefore(tx, s, at)
+	if err != nil {
+		return s, err
+	}
+	oldID := s.ID
+	s.RRule, s.Start, s.ExDates = tail.String(), at, tailEx
+	if err := insertSeries(tx, &s); err != nil {
+		return s, err
+	}
+	_, err = tx.Exec(
+		"UPDATE events SET series_id=$1 WHERE series_id=$2 AND recurrence_id>=$3", s.ID, oldID, at,
+	)
+	return s, err
+}
+
+// applySeriesChanges rozszerza zmiany zapisane w wystąpieniu target na serię:
+//
+//	this      – wystąpienie zostaje oznaczone jako zmienione osobno (detached),
+//	following – seria jest dzielona, a zmiany trafiają do tego i kolejnych wystąpień,
+//	all       – zmiany trafiają do wszystkich wystąpień i szablonu serii.
+//
+// Nowa godzina początku i długość wystąpienia są przenoszone na pozostałe
+// wystąpienia (każde zostaje w swoim dniu). Wystąpienia zmienione wcześniej
+// osobno zachowują swoje wartości. Każde zmienione wystąpienie dostaje
+// własną rewizję i nową wersję.
+func applySeriesChanges(tx *sql.Tx, target models.Event, changes map[string]models.FieldChange, scope string, actorID int) error {
+	if target.SeriesID == nil || len(changes) == 0 {
+		return nil
+	}
+	if scope == scopeThis {
+		_, err := tx.Exec("UPDATE events SET detached=TRUE WHERE id=$1", target.ID)
+		return err
+	}
+
+	// 1) Nowe wartości wystąpienia; termin można przesunąć tylko w obrębie dnia
+	tz := target.Timezone
+	if ch, ok := changes["timezone"]; ok {
+		tz, _ = ch.New.(string)
+	}
+	loc := location(tz)
+	start, end := target.Date, target.EndDate
+	_, retime := changes["date"]
+	_, reEnd := changes["end_date"]
+	if retime {
+		v, _ := columnValue("date", changes["date"].New)
+		start = v.(time.Time)
+		if !withClock(target.Date, start, loc).Equal(start) {
+			return errSeriesDateShift
+		}
+	}
+	if reEnd {
+		end = nil
+		if v, _ := columnValue("end_date", changes["end_date"].New); v != nil {
+			t := v.(time.Time)
+			end = &t
+		}
+	}
+	var duration interface{}
+	if end != nil {
+		duration = int(end.Sub(start) / time.Second)
+	}
+
+	// 2) Seria, której dotyczy zmiana (przy "following" – nowa, odcięta część)
+	var s models.EventSeries
+	if err := scanSeries(tx.QueryRow(
+		"SELECT "+seriesColumns+" FROM event_series WHERE id=$1", *target.SeriesID,
+	), &s); err != nil {
+		return err
+	}
+	if scope == scopeFollowing && target.RecurrenceID.After(s.Start) {
+		var err error
+		if s, err = splitSeries(tx, s, *target.RecurrenceID); err != nil {
+			return err
+		}
+	}
+
+	// 3) Szablon serii: godzina początku (także wyjątków), długość i pola
+	if retime {
+		s.Start = withClock(s.Start, start, loc)
+		for i := range s.ExDates {
+			s.ExDates[i] = withClock(s.ExDates[i], start, loc)
+		}
+	}
+	query := "UPDATE event_series SET dtstart=$1, exdates=$2, duration=$3"
+	args := []interface{}{s.Start, recurrence.FormatDates(s.ExDates), duration}
+	for field, ch := range changes {
+		col, ok := seriesTemplateColumns[field]
+		if !ok {
+			continue
+		}
+		v, err := columnValue(field, ch.New)
+		if field == "tags" {
+			v = strings.Join(tagList(ch.New), ",")
+		}
+		if err != nil {
+			return err
+		}
+		args = append(args, v)
+		query += ", " + col + "=$" + strconv.Itoa(len(args))
+	}
+	args = append(args, s.ID)
+	if _, err := tx.Exec(query+" WHERE id=$"+strconv.Itoa(len(args)), args...); err != nil {
+		return err
+	}
+
+	// 4) Pozostałe wystąpienia (bez zmienionych osobno)
+	occurrences, err := seriesOccurrences(tx, s.ID, time.Time{})
+	if err != nil {
+		return err
+	}
+	for _, e := range occurrences {
+		if e.ID != target.ID {
+			if e.Detached {
+				continue
+			}
+			snapshot := eventSnapshot(e)
+			updated := eventSnapshot(e)
+			for field, ch := range changes {
+				updated[field] = ch.New
+			}
+			occStart := e.Date
+			if retime {
+				occStart = withClock(e.Date, start, loc)
+			}
+			updated["date"] = occStart.Format(time.RFC3339)
+			if retime || reEnd {
+				updated["end_date"] = nil
+				if end != nil {
+					updated["end_date"] = occStart.Add(end.Sub(start)).Format(time.RFC3339)
+				}
+			}
+			if _, err := saveEventChanges(tx, e.ID, actorID, e.Version, diffSnapshots(snapshot, updated), nil); err != nil {
+				return err
+			}
+		}
+		if retime {
+			if _, err := tx.Exec(
+				"UPDATE events SET recurrence_id=$1 WHERE id=$2", withClock(*e.RecurrenceID, start, loc), e.ID,
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+// File: internal/handlers/series_test.go
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+)
+
+// seriesStart zwraca pierwszy termin testowej serii: za rok o 18:00.
+func seriesStart() time.Time {
+	y, m, d := time.Now().AddDate(1, 0, 0).Date()
+	return time.Date(y, m, d, 18, 0, 0, 0, time.UTC)
+}
+
+func call(h http.HandlerFunc, method, target string, ctx context.Context, id int, body string) *httptest.ResponseRecorder {
+	req := mux.SetURLVars(
+		httptest.NewRequest(method, target, bytes.NewBufferString(body)).WithContext(ctx),
+		map[string]string{"id": strconv.Itoa(id)},
+	)
+	w := httptest.NewRecorder()
+	h(w, req)
+	return w
+}
+
+// createSeries tworzy codzienną serię 4 terminów z wyjątkiem w drugim dniu
+// i zwraca ID serii oraz jej trzech wystąpień.
+func createSeries(t *testing.T, db *sql.DB, ctx context.Context) (int, []int) {
+	t.Helper()
+	start := seriesStart()
+	body := fmt.Sprintf(
+		`{"title":"Warsztaty","date_time":%q,"capacity":10,"status":"published","rrule":"FREQ=DAILY;COUNT=4","exdates":[%q]}`,
+		start.Format("2006-01-02T15:04"), start.AddDate(0, 0, 1).Format("2006-01-02T15:04"),
+	)
+	w := call(handlers.CreateSeries(db), "POST", "/series", ctx, 0, body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create series: expected 201, got %d (%s)", w.Code, w.Body.String())
+	}
+	var resp struct {
+		ID          int   `json:"id"`
+		Occurrences []int `json:"occurrences"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Occurrences) != 3 {
+		t.Fatalf("expected 3 occurrences, got %v", resp.Occurrences)
+	}
+	return resp.ID, resp.Occurrences
+}
+
+func getSeries(t *testing.T, db *sql.DB, ctx context.Context, id int) models.EventSeries {
+	t.Helper()
+	w := call(handlers.GetSeries(db), "GET", "/series/x", ctx, id, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("get series: expected 200, got %d", w.Code)
+	}
+	var s models.EventSeries
+	json.Unmarshal(w.Body.Bytes(), &s)
+	return s
+}
+
+func getEvent(t *testing.T, db *sql.DB, ctx context.Context, id int) models.Event {
+	t.Helper()
+	w := call(handlers.GetEvent(db), "GET", "/events/x", ctx, id, "")
+	var e models.Event
+	json.Unmarshal(w.Body.Bytes(), &e)
+	return e
+}
+
+func TestCreateSeries(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	id, occ := createSeries(t, db, owner)
+
+	s := getSeries(t, db, owner, id)
+	if len(s.Occurrences) != 3 || len(s.ExDates) != 1 {
+		t.Fatalf("unexpected series: %+v", s)
+	}
+	start := seriesStart()
+	for i, want := range []time.Time{start, start.AddDate(0, 0, 2), start.AddDate(0, 0, 3)} {
+		e := s.Occurrences[i]
+		if e.ID != occ[i] || !e.Date.Equal(want) || e.SeriesID == nil || *e.SeriesID != id {
+			t.Errorf("occurrence %d: unexpected %+v", i, e)
+		}
+	}
+
+	// Reguła bez końca jest odrzucana
+	w := call(handlers.CreateSeries(db), "POST", "/series", owner, 0, fmt.Sprintf(
+		`{"title":"X","date_time":%q,"capacity":10,"rrule":"FREQ=WEEKLY"}`, start.Format("2006-01-02T15:04"),
+	))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"rrule"`) {
+		t.Fatalf("unbounded rule: expected 400 for rrule, got %d (%s)", w.Code, w.Body.String())
+	}
+}
+
+func TestSeriesEditScopes(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	seriesID, occ := createSeries(t, db, owner)
+	patch := handlers.PatchEvent(db)
+
+	// "tylko to": pierwsze wystąpienie zmienione osobno
+	if w := call(patch, "PATCH", "/events/x?scope=this", owner, occ[0], `{"title":"Solo"}`); w.Code != http.StatusOK {
+		t.Fatalf("patch this: expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	if e := getEvent(t, db, owner, occ[1]); e.Title != "Warsztaty" {
+		t.Fatalf("scope=this changed another occurrence: %+v", e)
+	}
+
+	// "to i kolejne": seria dzielona na dwie
+	if w := call(patch, "PATCH", "/events/x?scope=following", owner, occ[1], `{"title":"Nowe","capacity":30}`); w.Code != http.StatusOK {
+		t.Fatalf("patch following: expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	for _, id := range occ[1:] {
+		if e := getEvent(t, db, owner, id); e.Title != "Nowe" || e.Capacity != 30 {
+			t.Errorf("occurrence %d not updated: %+v", id, e)
+		}
+	}
+	head := getSeries(t, db, owner, seriesID)
+	if len(head.Occurrences) != 1 || !strings.Contains(head.RRule, "UNTIL=") {
+		t.Fatalf("series was not split: %+v", head)
+	}
+	tail := getEvent(t, db, owner, occ[2])
+	if *tail.SeriesID == seriesID {
+		t.Fatalf("following occurrences stayed in the old series")
+	}
+
+	// "wszystkie": przesunięcie godziny w nowej serii
+	later := seriesStart().AddDate(0, 0, 2).Add(90 * time.Minute).Format("2006-01-02T15:04")
+	if w := call(patch, "PATCH", "/events/x?scope=all", owner, occ[1], fmt.Sprintf(`{"date_time":%q}`, later)); w.Code != http.StatusOK {
+		t.Fatalf("patch all: expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	if e := getEvent(t, db, owner, occ[2]); e.Date.Hour() != 19 || e.Date.Minute() != 30 || e.RecurrenceID.Hour() != 19 {
+		t.Fatalf("time of day not shifted: %+v", e)
+	}
+	if e := getEvent(t, db, owner, occ[0]); e.Title != "Solo" || e.Date.Hour() != 18 {
+		t.Fatalf("detached occurrence changed: %+v", e)
+	}
+
+	// Zmiana dnia dla wielu wystąpień jest odrzucana
+	nextDay := seriesStart().AddDate(0, 0, 5).Format("2006-01-02T15:04")
+	if w := call(patch, "PATCH", "/events/x?scope=all", owner, occ[1], fmt.Sprintf(`{"date_time":%q}`, nextDay)); w.Code != http.StatusBadRequest {
+		t.Fatalf("day shift: expected 400, got %d", w.Code)
+	}
+	// Zakres serii dla zwykłego wydarzenia
+	single := insertEvent(t, db, "published", 10)
+	if w := call(patch, "PATCH", "/events/x?scope=all", owner, single, `{"title":"X"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("scope on single event: expected 400, got %d", w.Code)
+	}
+}
+
+func TestSeriesDeleteAndRegenerate(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	seriesID, occ := createSeries(t, db, owner)
+
+	// Usunięcie jednego wystąpienia dodaje wyjątek do reguły
+	req := mux.SetURLVars(
+		httptest.NewRequest("DELETE", "/events/x?scope=this", nil).WithContext(owner),
+		map[string]string{"id": strconv.Itoa(occ[1])},
+	)
+	req.Header.Set("If-Match", `"1"`)
+	w := httptest.NewRecorder()
+	handlers.DeleteEvent(db)(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d (%s)", w.Code, w.Body.String())
+	}
+	if s := getSeries(t, db, owner, seriesID); len(s.Occurrences) != 2 || len(s.ExDates) != 2 {
+		t.Fatalf("unexpected series after delete: %+v", s)
+	}
+
+	// Nowa reguła: 5 dni bez wyjątków – dochodzą brakujące terminy,
+	// a wystąpienie z rezerwacją, które wypada z reguły, jest odwoływane
+	if _, err := db.Exec("INSERT INTO reservations(user_id, event_id, tickets) VALUES(7, $1, 1)", occ[2]); err != nil {
+		t.Fatal(err)
+	}
+	ex := seriesStart().AddDate(0, 0, 3).Format("2006-01-02T15:04")
+	w = call(handlers.UpdateSeries(db), "PUT", "/series/x", owner, seriesID,
+		fmt.Sprintf(`{"rrule":"FREQ=DAILY;COUNT=5","exdates":[%q]}`, ex))
+	if w.Code != http.StatusOK {
+		t.Fatalf("update series: expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["created"] != float64(3) || resp["cancelled"] != float64(1) || resp["removed"] != float64(0) {
+		t.Fatalf("unexpected regeneration result: %v", resp)
+	}
+	if e := getEvent(t, db, owner, occ[2]); e.Status != models.EventCancelled {
+		t.Fatalf("expected cancelled occurrence, got %+v", e)
+	}
+}
+
+func TestSeries_KeepsLocalTimeAcrossDST(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	year := time.Now().Year() + 1
+
+	// Zmiana czasu w Europie wypada w ostatnią niedzielę marca
+	body := fmt.Sprintf(
+		`{"title":"Joga","date_time":"%d-03-20T18:00","timezone":"Europe/Warsaw","capacity":10,"rrule":"FREQ=WEEKLY;COUNT=3"}`, year,
+	)
+	w := call(handlers.CreateSeries(db), "POST", "/series", owner, 0, body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create series: expected 201, got %d (%s)", w.Code, w.Body.String())
+	}
+	var resp struct {
+		ID int `json:"id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	s := getSeries(t, db, owner, resp.ID)
+	if len(s.Occurrences) != 3 {
+		t.Fatalf("expected 3 occurrences, got %d", len(s.Occurrences))
+	}
+	first, last := s.Occurrences[0], s.Occurrences[2]
+	if first.Date.Hour() != 17 || last.Date.Hour() != 16 {
+		t.Fatalf("unexpected UTC hours: %v, %v", first.Date, last.Date)
+	}
+	for _, e := range s.Occurrences {
+		if !strings.Contains(e.LocalDate, "T18:00:00") {
+			t.Errorf("occurrence not at 18:00 local time: %q", e.LocalDate)
+		}
+	}
+}
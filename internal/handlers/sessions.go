@@ -0,0 +1,516 @@
+// File: internal/handlers/sessions.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/gorilla/mux"
+)
+
+// sessionRequest to body tworzenia i aktualizacji sesji wydarzenia. Daty
+// bez strefy są czasem lokalnym w strefie wydarzenia.
+type sessionRequest struct {
+	Title       string `json:"title" validate:"required,max=255"`
+	Speaker     string `json:"speaker" validate:"max=255"`
+	Room        string `json:"room" validate:"max=100"`
+	DateTime    string `json:"date_time" validate:"required,datetime,future"`
+	EndDateTime string `json:"end_date_time" validate:"required,datetime"`
+	// Capacity pominięte – bez osobnego limitu miejsc na sesji.
+	Capacity *int `json:"capacity" validate:"min=1,max=100000"`
+}
+
+// sessionSelect wybiera sesje razem ze strefą i tytułem wydarzenia,
+// w kolejności oczekiwanej przez scanSession.
+const sessionSelect = `SELECT s.id, s.event_id, s.title, COALESCE(s.speaker, ''), COALESCE(s.room, ''),
+	s.starts_at, s.ends_at, s.capacity, s.signed_up, COALESCE(e.timezone, 'UTC'), e.title
+	FROM event_sessions s JOIN events e ON e.id = s.event_id`
+
+// scanSession czyta wiersz sessionSelect i uzupełnia daty lokalne oraz wolne miejsca.
+func scanSession(row rowScanner, s *models.EventSession) error {
+	var capacity sql.NullInt64
+	var tz string
+	if err := row.Scan(
+		&s.ID, &s.EventID, &s.Title, &s.Speaker, &s.Room,
+		&s.StartsAt, &s.EndsAt, &capacity, &s.SignedUp, &tz, &s.EventTitle,
+	); err != nil {
+		return err
+	}
+	loc := location(tz)
+	s.StartsAt, s.EndsAt = s.StartsAt.UTC(), s.EndsAt.UTC()
+	s.LocalStartsAt = s.StartsAt.In(loc).Format(time.RFC3339)
+	s.LocalEndsAt = s.EndsAt.In(loc).Format(time.RFC3339)
+	if capacity.Valid {
+		c := int(capacity.Int64)
+		left := max(c-s.SignedUp, 0)
+		s.Capacity, s.SeatsLeft = &c, &left
+	}
+	return nil
+}
+
+// querySessions zwraca sesje wybrane zapytaniem zaczynającym się od sessionSelect.
+func querySessions(q interface {
+	Query(string, ...interface{}) (*sql.Rows, error)
+}, query string, args ...interface{}) ([]models.EventSession, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.EventSession{}
+	for rows.Next() {
+		var s models.EventSession
+		if err := scanSession(rows, &s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// checkSession sprawdza termin i limit sesji względem wydarzenia e oraz
+// zajętość sali przez inne sesje (poza sesją excludeID). Zwraca początek
+// i koniec w UTC; w razie błędu sam wysyła odpowiedź i zwraca false.
+func checkSession(w http.ResponseWriter, q queryRower, e models.Event, req sessionRequest, excludeID int) (time.Time, time.Time, bool) {
+	start, end, ok := eventTimes(w, req.DateTime, req.EndDateTime, e.Timezone, time.Time{})
+	if !ok {
+		return start, time.Time{}, false
+	}
+	if start.Before(e.Date) {
+		writeFieldError(w, "date_time", "must not be before the event start ("+e.LocalDate+")")
+		return start, *end, false
+	}
+	if e.EndDate != nil && end.After(*e.EndDate) {
+		writeFieldError(w, "end_date_time", "must not be after the event end ("+e.LocalEndDate+")")
+		return start, *end, false
+	}
+	if req.Capacity != nil && *req.Capacity > e.Capacity {
+		writeFieldError(w, "capacity", "must not exceed event capacity ("+strconv.Itoa(e.Capacity)+")")
+		return start, *end, false
+	}
+
+	// Jedna sala nie mieści dwóch sesji naraz
+	if room := strings.TrimSpace(req.Room); room != "" {
+		var otherID int
+		var otherTitle string
+		err := q.QueryRow(
+			`SELECT id, title FROM event_sessions
+			 WHERE event_id=$1 AND id<>$2 AND LOWER(room)=LOWER($3) AND starts_at<$4 AND ends_at>$5`,
+			e.ID, excludeID, room, *end, start,
+		).Scan(&otherID, &otherTitle)
+		if err == nil {
+			http.Error(w, fmt.Sprintf("Room %q is already booked at this time by session %d (%q)", room, otherID, otherTitle), http.StatusConflict)
+			return start, *end, false
+		}
+		if err != sql.ErrNoRows {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return start, *end, false
+		}
+	}
+	return start, *end, true
+}
+
+// loadOwnedEvent łączy ownedEvent z odczytem wydarzenia (do sprawdzania sesji).
+func loadOwnedEvent(w http.ResponseWriter, r *http.Request, q interface {
+	QueryRow(string, ...interface{}) *sql.Row
+}) (models.Event, int, bool) {
+	var e models.Event
+	eventID, actorID, ok := ownedEvent(w, r, q)
+	if !ok {
+		return e, 0, false
+	}
+	if err := scanEvent(q.QueryRow("SELECT "+eventColumns+" FROM events WHERE id=$1", eventID), &e); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return e, 0, false
+	}
+	return e, actorID, true
+}
+
+// ListSessions zwraca agendę wydarzenia – sesje według początku i sali.
+// Opcjonalne ?room= zawęża ją do jednej sali. Zalogowany użytkownik widzi
+// przy sesjach, na które jest zapisany, joined=true.
+func ListSessions(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		eventID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		// 1) Agenda jest widoczna tak jak samo wydarzenie
+		var organizerID int
+		var status string
+		if err := db.QueryRow(
+			"SELECT organizer_id, status FROM events WHERE id=$1 AND deleted_at IS NULL", eventID,
+		).Scan(&organizerID, &status); err != nil || (status == models.EventDraft && !isOwner(r, organizerID)) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+
+		// 2) Sesje
+		query := sessionSelect + " WHERE s.event_id=$1"
+		args := []interface{}{eventID}
+		if room := strings.TrimSpace(r.URL.Query().Get("room")); room != "" {
+			args = append(args, room)
+			query += " AND LOWER(s.room)=LOWER($2)"
+		}
+		sessions, err := querySessions(db, query+" ORDER BY s.starts_at, s.room, s.id", args...)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// 3) Zapisy zalogowanego użytkownika
+		if claims, ok := auth.FromContext(r.Context()); ok {
+			rows, err := db.Query(
+				"SELECT session_id FROM session_signups WHERE event_id=$1 AND user_id=$2",
+				eventID, int(claims["id"].(float64)),
+			)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			joined := map[int]bool{}
+			for rows.Next() {
+				var id int
+				if rows.Scan(&id) == nil {
+					joined[id] = true
+				}
+			}
+			rows.Close()
+			for i := range sessions {
+				sessions[i].Joined = joined[sessions[i].ID]
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(sessions)
+	}
+}
+
+// CreateSession dodaje sesję do agendy wydarzenia (właściciel lub admin).
+func CreateSession(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// 1) Uprawnienia
+		e, _, ok := loadOwnedEvent(w, r, db)
+		if !ok {
+			return
+		}
+
+		// 2) Dekodowanie i sprawdzenie terminu, limitu i sali
+		var req sessionRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		start, end, ok := checkSession(w, db, e, req, 0)
+		if !ok {
+			return
+		}
+
+		// 3) Wstawienie do bazy
+		var newID int
+		err := db.QueryRow(
+			`INSERT INTO event_sessions(event_id, title, speaker, room, starts_at, ends_at, capacity)
+			 VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+			e.ID, req.Title, req.Speaker, strings.TrimSpace(req.Room), start, end, req.Capacity,
+		).Scan(&newID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]int{"id": newID})
+	}
+}
+
+// UpdateSession zmienia sesję (właściciel lub admin). Limitu nie można
+// zmniejszyć poniżej liczby zapisanych uczestników.
+func UpdateSession(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		sessionID, _ := strconv.Atoi(mux.Vars(r)["sid"])
+
+		var req sessionRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		// 1) Uprawnienia i istnienie sesji w tym wydarzeniu
+		e, _, ok := loadOwnedEvent(w, r, tx)
+		if !ok {
+			return
+		}
+		var signedUp int
+		if err := tx.QueryRow(
+			"SELECT signed_up FROM event_sessions WHERE id=$1 AND event_id=$2", sessionID, e.ID,
+		).Scan(&signedUp); err != nil {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+
+		// 2) Termin, sala i limit
+		start, end, ok := checkSession(w, tx, e, req, sessionID)
+		if !ok {
+			return
+		}
+		if req.Capacity != nil && *req.Capacity < signedUp {
+			http.Error(w, fmt.Sprintf("Capacity lower than the number of signed up participants (%d)", signedUp), http.StatusConflict)
+			return
+		}
+
+		// 3) Zapis
+		if _, err := tx.Exec(
+			`UPDATE event_sessions SET title=$1, speaker=$2, room=$3, starts_at=$4, ends_at=$5, capacity=$6
+			 WHERE id=$7`,
+			req.Title, req.Speaker, strings.TrimSpace(req.Room), start, end, req.Capacity, sessionID,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"status": "updated"})
+	}
+}
+
+// DeleteSession usuwa sesję razem z zapisami na nią (właściciel lub admin).
+func DeleteSession(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID, _ := strconv.Atoi(mux.Vars(r)["sid"])
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		eventID, _, ok := ownedEvent(w, r, tx)
+		if !ok {
+			return
+		}
+		if _, err := tx.Exec(
+			"DELETE FROM session_signups WHERE session_id=$1 AND event_id=$2", sessionID, eventID,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res, err := tx.Exec("DELETE FROM event_sessions WHERE id=$1 AND event_id=$2", sessionID, eventID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// SignUpSession zapisuje zalogowanego użytkownika na sesję. Wymaga
+// rezerwacji na wydarzenie, wolnego miejsca i braku kolizji z sesjami,
+// na które użytkownik jest już zapisany (także na innych wydarzeniach).
+func SignUpSession(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// 1) Uwierzytelnienie
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		userID := int(claims["id"].(float64))
+		sessionID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		// 2) Sesja i jej wydarzenie
+		var s models.EventSession
+		if err := scanSession(tx.QueryRow(
+			sessionSelect+" WHERE s.id=$1 AND e.deleted_at IS NULL", sessionID,
+		), &s); err != nil {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		var status string
+		if err := tx.QueryRow("SELECT status FROM events WHERE id=$1", s.EventID).Scan(&status); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if status != models.EventPublished {
+			http.Error(w, "Event is not open for sign-ups", http.StatusConflict)
+			return
+		}
+		if !s.StartsAt.After(time.Now()) {
+			http.Error(w, "Session has already started", http.StatusConflict)
+			return
+		}
+
+		// 3) Rezerwacja na wydarzenie i brak wcześniejszego zapisu
+		var reservations, existing int
+		if err := tx.QueryRow(
+			"SELECT COUNT(*) FROM reservations WHERE user_id=$1 AND event_id=$2", userID, s.EventID,
+		).Scan(&reservations); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if reservations == 0 {
+			http.Error(w, "A reservation for the event is required", http.StatusForbidden)
+			return
+		}
+		if err := tx.QueryRow(
+			"SELECT COUNT(*) FROM session_signups WHERE session_id=$1 AND user_id=$2", sessionID, userID,
+		).Scan(&existing); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if existing > 0 {
+			http.Error(w, "Already signed up for this session", http.StatusConflict)
+			return
+		}
+
+		// 4) Kolizje w planie użytkownika
+		clashes, err := querySessions(tx, sessionSelect+
+			` JOIN session_signups su ON su.session_id = s.id
+			 WHERE su.user_id=$1 AND s.starts_at<$2 AND s.ends_at>$3
+			   AND e.deleted_at IS NULL AND e.status <> $4
+			 ORDER BY s.starts_at`,
+			userID, s.EndsAt, s.StartsAt, models.EventCancelled,
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if len(clashes) > 0 {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "Session clashes with sessions you are signed up for",
+				"clashes": clashes,
+			})
+			return
+		}
+
+		// 5) Zajęcie miejsca – warunkowy UPDATE, więc równoległe zapisy nie przekroczą limitu
+		res, err := tx.Exec(
+			`UPDATE event_sessions SET signed_up = signed_up + 1
+			 WHERE id=$1 AND (capacity IS NULL OR signed_up < capacity)`,
+			sessionID,
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			http.Error(w, "Session is full", http.StatusConflict)
+			return
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO session_signups(session_id, event_id, user_id, created_at) VALUES($1, $2, $3, $4)",
+			sessionID, s.EventID, userID, time.Now().UTC(),
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"status": "signed_up"})
+	}
+}
+
+// CancelSessionSignup wypisuje zalogowanego użytkownika z sesji.
+func CancelSessionSignup(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		userID := int(claims["id"].(float64))
+		sessionID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		res, err := tx.Exec("DELETE FROM session_signups WHERE session_id=$1 AND user_id=$2", sessionID, userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			http.Error(w, "Not signed up for this session", http.StatusNotFound)
+			return
+		}
+		if _, err := tx.Exec(
+			"UPDATE event_sessions SET signed_up = signed_up - 1 WHERE id=$1 AND signed_up > 0", sessionID,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// MyAgenda zwraca sesje, na które zapisany jest zalogowany użytkownik
+// (ze wszystkich wydarzeń), w kolejności rozpoczęcia.
+func MyAgenda(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		sessions, err := querySessions(db, sessionSelect+
+			` JOIN session_signups su ON su.session_id = s.id
+			 WHERE su.user_id=$1 AND e.deleted_at IS NULL
+			 ORDER BY s.starts_at, s.id`,
+			int(claims["id"].(float64)),
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for i := range sessions {
+			sessions[i].Joined = true
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(sessions)
+	}
+}
@@ -0,0 +1,207 @@
+// File: internal/handlers/sessions_test.go
+package handlers_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+)
+
+// createConference tworzy opublikowane 8-godzinne wydarzenie (UTC) i zwraca
+// jego ID oraz początek.
+func createConference(t *testing.T, db *sql.DB, owner context.Context) (int, time.Time) {
+	t.Helper()
+	start := time.Now().AddDate(0, 1, 0).UTC().Truncate(time.Hour)
+	w := call(handlers.CreateEvent(db), "POST", "/events", owner, 0, fmt.Sprintf(
+		`{"title":"Konferencja","date_time":%q,"end_date_time":%q,"capacity":100,"status":"published"}`,
+		start.Format(time.RFC3339), start.Add(8*time.Hour).Format(time.RFC3339),
+	))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create event: expected 201, got %d (%s)", w.Code, w.Body.String())
+	}
+	var resp struct {
+		ID int `json:"id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.ID, start
+}
+
+// sessionBody zwraca body sesji od start+fromH do start+toH godzin.
+func sessionBody(title, room string, start time.Time, fromH, toH int, capacity string) string {
+	return fmt.Sprintf(`{"title":%q,"speaker":"Anna Nowak","room":%q,"date_time":%q,"end_date_time":%q%s}`,
+		title, room,
+		start.Add(time.Duration(fromH)*time.Hour).Format(time.RFC3339),
+		start.Add(time.Duration(toH)*time.Hour).Format(time.RFC3339),
+		capacity,
+	)
+}
+
+func createSession(t *testing.T, db *sql.DB, owner context.Context, eventID int, body string) int {
+	t.Helper()
+	w := call(handlers.CreateSession(db), "POST", "/events/x/sessions", owner, eventID, body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create session: expected 201, got %d (%s)", w.Code, w.Body.String())
+	}
+	var resp struct {
+		ID int `json:"id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.ID
+}
+
+func listSessions(t *testing.T, db *sql.DB, ctx context.Context, eventID int, query string) []models.EventSession {
+	t.Helper()
+	w := call(handlers.ListSessions(db), "GET", "/events/x/sessions"+query, ctx, eventID, "")
+	var sessions []models.EventSession
+	json.Unmarshal(w.Body.Bytes(), &sessions)
+	return sessions
+}
+
+func TestSessions_AgendaAndValidation(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	eventID, start := createConference(t, db, owner)
+
+	keynote := createSession(t, db, owner, eventID, sessionBody("Keynote", "Sala 1", start, 0, 1, ""))
+	createSession(t, db, owner, eventID, sessionBody("Warsztat Go", "Sala 2", start, 0, 2, `,"capacity":20`))
+
+	for name, tc := range map[string]struct {
+		body string
+		code int
+	}{
+		"room clash (case-insensitive)": {sessionBody("Inny", "sala 1", start, 0, 1, ""), http.StatusConflict},
+		"after event end":               {sessionBody("Późno", "Sala 3", start, 7, 9, ""), http.StatusBadRequest},
+		"end before start":              {sessionBody("Odwrotnie", "Sala 3", start, 2, 1, ""), http.StatusBadRequest},
+		"over event capacity":           {sessionBody("Tłum", "Sala 3", start, 1, 2, `,"capacity":500`), http.StatusBadRequest},
+	} {
+		if w := call(handlers.CreateSession(db), "POST", "/", owner, eventID, tc.body); w.Code != tc.code {
+			t.Errorf("%s: expected %d, got %d (%s)", name, tc.code, w.Code, w.Body.String())
+		}
+	}
+	// Inny organizator nie zmienia agendy
+	other := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(2), "role": "organizer"})
+	if w := call(handlers.CreateSession(db), "POST", "/", other, eventID, sessionBody("X", "", start, 3, 4, "")); w.Code != http.StatusForbidden {
+		t.Fatalf("other organizer: expected 403, got %d", w.Code)
+	}
+
+	agenda := listSessions(t, db, context.Background(), eventID, "")
+	if len(agenda) != 2 || agenda[0].Title != "Keynote" || agenda[1].Capacity == nil || *agenda[1].SeatsLeft != 20 {
+		t.Fatalf("unexpected agenda: %+v", agenda)
+	}
+	if !agenda[0].StartsAt.Equal(start) || agenda[0].LocalStartsAt != start.Format(time.RFC3339) {
+		t.Fatalf("unexpected session times: %+v", agenda[0])
+	}
+	if rooms := listSessions(t, db, context.Background(), eventID, "?room=sala%202"); len(rooms) != 1 || rooms[0].Room != "Sala 2" {
+		t.Fatalf("room filter: unexpected %+v", rooms)
+	}
+
+	// Przesunięcie sesji na własny termin nie koliduje z samą sobą
+	w := callSession(handlers.UpdateSession(db), "PUT", owner, eventID, keynote, sessionBody("Keynote (nowa nazwa)", "Sala 1", start, 0, 1, ""))
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	if w := callSession(handlers.DeleteSession(db), "DELETE", owner, eventID, keynote, ""); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d", w.Code)
+	}
+	if agenda := listSessions(t, db, context.Background(), eventID, ""); len(agenda) != 1 {
+		t.Fatalf("expected 1 session after delete, got %d", len(agenda))
+	}
+}
+
+// callSession wywołuje handler z trasą /events/{id}/sessions/{sid}.
+func callSession(h http.HandlerFunc, method string, ctx context.Context, eventID, sessionID int, body string) *httptest.ResponseRecorder {
+	req := mux.SetURLVars(
+		httptest.NewRequest(method, "/events/x/sessions/x", strings.NewReader(body)).WithContext(ctx),
+		map[string]string{"id": strconv.Itoa(eventID), "sid": strconv.Itoa(sessionID)},
+	)
+	w := httptest.NewRecorder()
+	h(w, req)
+	return w
+}
+
+func TestSessionSignups(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	eventID, start := createConference(t, db, owner)
+	talk := createSession(t, db, owner, eventID, sessionBody("Wykład", "Sala 1", start, 1, 2, `,"capacity":2`))
+	parallel := createSession(t, db, owner, eventID, sessionBody("Równolegle", "Sala 2", start, 1, 3, ""))
+
+	user := func(id int) context.Context {
+		return auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(id), "role": "participant"})
+	}
+	signup := func(ctx context.Context, sessionID int) *httptest.ResponseRecorder {
+		return call(handlers.SignUpSession(db), "POST", "/sessions/x/signup", ctx, sessionID, "")
+	}
+
+	// Bez rezerwacji na wydarzenie nie można się zapisać
+	if w := signup(user(7), talk); w.Code != http.StatusForbidden {
+		t.Fatalf("without reservation: expected 403, got %d", w.Code)
+	}
+	for _, id := range []int{7, 8, 9} {
+		db.Exec("INSERT INTO reservations(user_id, event_id, tickets) VALUES($1, $2, 1)", id, eventID)
+	}
+	if w := signup(user(7), talk); w.Code != http.StatusCreated {
+		t.Fatalf("signup: expected 201, got %d (%s)", w.Code, w.Body.String())
+	}
+	if w := signup(user(7), talk); w.Code != http.StatusConflict {
+		t.Fatalf("duplicate signup: expected 409, got %d", w.Code)
+	}
+
+	// Kolizja z już wybraną sesją
+	w := signup(user(7), parallel)
+	var clash struct {
+		Clashes []models.EventSession `json:"clashes"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &clash)
+	if w.Code != http.StatusConflict || len(clash.Clashes) != 1 || clash.Clashes[0].ID != talk {
+		t.Fatalf("clash: expected 409 with session %d, got %d (%s)", talk, w.Code, w.Body.String())
+	}
+
+	// Limit miejsc
+	if w := signup(user(8), talk); w.Code != http.StatusCreated {
+		t.Fatalf("second signup: expected 201, got %d", w.Code)
+	}
+	if w := signup(user(9), talk); w.Code != http.StatusConflict {
+		t.Fatalf("full session: expected 409, got %d", w.Code)
+	}
+	if w := callSession(handlers.UpdateSession(db), "PUT", owner, eventID, talk,
+		sessionBody("Wykład", "Sala 1", start, 1, 2, `,"capacity":1`)); w.Code != http.StatusConflict {
+		t.Fatalf("shrink below signups: expected 409, got %d", w.Code)
+	}
+
+	// Wypisanie zwalnia miejsce
+	if w := call(handlers.CancelSessionSignup(db), "DELETE", "/", user(7), talk, ""); w.Code != http.StatusNoContent {
+		t.Fatalf("cancel: expected 204, got %d", w.Code)
+	}
+	if w := signup(user(9), talk); w.Code != http.StatusCreated {
+		t.Fatalf("signup after cancel: expected 201, got %d", w.Code)
+	}
+	if w := signup(user(7), parallel); w.Code != http.StatusCreated {
+		t.Fatalf("signup without clash: expected 201, got %d (%s)", w.Code, w.Body.String())
+	}
+
+	// Agenda z perspektywy uczestnika i jego osobisty plan
+	agenda := listSessions(t, db, user(9), eventID, "")
+	if len(agenda) != 2 || !agenda[0].Joined || agenda[1].Joined || agenda[0].SignedUp != 2 || *agenda[0].SeatsLeft != 0 {
+		t.Fatalf("unexpected agenda for user 9: %+v", agenda)
+	}
+	w = call(handlers.MyAgenda(db), "GET", "/agenda", user(7), 0, "")
+	var mine []models.EventSession
+	json.Unmarshal(w.Body.Bytes(), &mine)
+	if len(mine) != 1 || mine[0].ID != parallel || mine[0].EventTitle != "Konferencja" {
+		t.Fatalf("unexpected personal agenda: %+v", mine)
+	}
+}
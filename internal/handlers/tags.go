@@ -0,0 +1,198 @@
+// File: internal/handlers/tags.go
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/bartbaranski/eventhub/internal/validate"
+)
+
+// Limity etykiet wydarzenia.
+const (
+	maxEventTags = 10
+	maxTagLength = 30
+)
+
+// normalizeTag sprowadza etykietę do postaci kanonicznej: małe litery,
+// spacje zamienione na "-". Dozwolone są litery, cyfry i "-".
+func normalizeTag(s string) (string, bool) {
+	tag := strings.ToLower(strings.Join(strings.Fields(s), "-"))
+	if tag == "" || utf8.RuneCountInString(tag) > maxTagLength || tag[0] == '-' {
+		return "", false
+	}
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' {
+			return "", false
+		}
+	}
+	return tag, true
+}
+
+// normalizeTags normalizuje etykiety z żądania, usuwa duplikaty i sortuje je.
+// W razie błędu sam wysyła 400 (pole tags) i zwraca false.
+func normalizeTags(w http.ResponseWriter, tags []string) ([]string, bool) {
+	out, fe := parseTags(tags)
+	if fe != nil {
+		writeFieldError(w, fe.Field, fe.Message)
+		return nil, false
+	}
+	return out, true
+}
+
+// parseTags to normalizeTags zwracające błąd pola zamiast odpowiedzi.
+func parseTags(tags []string) ([]string, *validate.FieldError) {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, t := range tags {
+		tag, ok := normalizeTag(t)
+		if !ok {
+			return nil, &validate.FieldError{Field: "tags", Message: "tags must have 1-" + strconv.Itoa(maxTagLength) + " characters: letters, digits, spaces or dashes"}
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			out = append(out, tag)
+		}
+	}
+	if len(out) > maxEventTags {
+		return nil, &validate.FieldError{Field: "tags", Message: "must have at most " + strconv.Itoa(maxEventTags) + " items"}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// tagList zamienia wartość "tags" ze snapshotu (lub z rewizji zapisanej
+// w JSON) na listę etykiet.
+func tagList(v interface{}) []string {
+	out := []string{}
+	switch tags := v.(type) {
+	case []string:
+		out = append(out, tags...)
+	case []interface{}:
+		for _, t := range tags {
+			if s, ok := t.(string); ok {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// setEventTags zastępuje etykiety wydarzenia.
+func setEventTags(tx *sql.Tx, eventID int, tags []string) error {
+	if _, err := tx.Exec("DELETE FROM event_tags WHERE event_id=$1", eventID); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := tx.Exec("INSERT INTO event_tags(event_id, tag) VALUES($1, $2)", eventID, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachTags uzupełnia Tags wydarzeń wynikami zapytania zwracającego
+// pary (event_id, tag) – posortowane po tagu.
+func attachTags(q interface {
+	Query(string, ...interface{}) (*sql.Rows, error)
+}, events []models.Event, query string, args ...interface{}) error {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	tags := map[int][]string{}
+	for rows.Next() {
+		var id int
+		var tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return err
+		}
+		tags[id] = append(tags[id], tag)
+	}
+	for i := range events {
+		if t, ok := tags[events[i].ID]; ok {
+			events[i].Tags = t
+		}
+	}
+	return rows.Err()
+}
+
+// loadEventTags uzupełnia etykiety pojedynczego wydarzenia.
+func loadEventTags(q interface {
+	Query(string, ...interface{}) (*sql.Rows, error)
+}, e *models.Event) error {
+	events := []models.Event{*e}
+	if err := attachTags(q, events, "SELECT event_id, tag FROM event_tags WHERE event_id=$1 ORDER BY tag", e.ID); err != nil {
+		return err
+	}
+	e.Tags = events[0].Tags
+	return nil
+}
+
+// hasTags mówi, czy wydarzenie ma wszystkie podane etykiety.
+func hasTags(e models.Event, tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, t := range e.Tags {
+			if t == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// filterEvents zostawia wydarzenia z kategorią category (pusta – dowolna)
+// i wszystkimi etykietami tags.
+func filterEvents(events []models.Event, category string, tags []string) []models.Event {
+	out := []models.Event{}
+	for _, e := range events {
+		if (category == "" || e.Category == category) && hasTags(e, tags) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// eventFacets liczy wydarzenia według kategorii i etykiet – od najczęstszych
+// (przy remisie alfabetycznie). Facet kategorii pomija filtr kategorii,
+// a facet etykiet – filtr etykiet, tak by było widać, jak zmienić wybór.
+func eventFacets(events []models.Event, category string, tags []string) models.EventFacets {
+	categories, tagCounts := map[string]int{}, map[string]int{}
+	for _, e := range events {
+		if e.Category != "" && hasTags(e, tags) {
+			categories[e.Category]++
+		}
+		if category == "" || e.Category == category {
+			for _, t := range e.Tags {
+				tagCounts[t]++
+			}
+		}
+	}
+	return models.EventFacets{Categories: facetCounts(categories), Tags: facetCounts(tagCounts)}
+}
+
+func facetCounts(counts map[string]int) []models.FacetCount {
+	out := make([]models.FacetCount, 0, len(counts))
+	for v, n := range counts {
+		out = append(out, models.FacetCount{Value: v, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Value < out[j].Value
+	})
+	return out
+}
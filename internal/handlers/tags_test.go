@@ -0,0 +1,112 @@
+// File: internal/handlers/tags_test.go
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestListEvents_CategoriesTagsAndFacets(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	for _, ev := range []struct{ title, category, tags string }{
+		{"Jazz", "concert", `["Jazz","na żywo"]`},
+		{"Rock", "concert", `["rock","na żywo"]`},
+		{"Go", "workshop", `["programowanie"]`},
+	} {
+		w := call(handlers.CreateEvent(db), "POST", "/events", owner, 0, fmt.Sprintf(
+			`{"title":%q,"date_time":%q,"capacity":10,"status":"published","category":%q,"tags":%s}`,
+			ev.title, futureDateTime(), ev.category, ev.tags,
+		))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create %s: expected 201, got %d (%s)", ev.title, w.Code, w.Body.String())
+		}
+	}
+
+	list := func(query string) []models.Event {
+		w := httptest.NewRecorder()
+		handlers.ListEvents(db)(w, httptest.NewRequest("GET", "/events?"+query, nil))
+		var events []models.Event
+		json.Unmarshal(w.Body.Bytes(), &events)
+		return events
+	}
+	if events := list("category=concert"); len(events) != 2 {
+		t.Fatalf("category filter: expected 2 events, got %d", len(events))
+	}
+	events := list("tag=na-żywo&tag=JAZZ")
+	if len(events) != 1 || events[0].Title != "Jazz" || strings.Join(events[0].Tags, ",") != "jazz,na-żywo" {
+		t.Fatalf("tag filter: unexpected %+v", events)
+	}
+
+	// Każdy facet liczony z pozostałymi filtrami, ale bez własnego
+	type facetsResponse struct {
+		Events []models.Event     `json:"events"`
+		Facets models.EventFacets `json:"facets"`
+	}
+	facets := func(query string) facetsResponse {
+		w := httptest.NewRecorder()
+		handlers.ListEvents(db)(w, httptest.NewRequest("GET", "/events?facets=1&"+query, nil))
+		var resp facetsResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+	resp := facets("category=concert")
+	if len(resp.Events) != 2 || len(resp.Facets.Categories) != 2 ||
+		resp.Facets.Categories[0] != (models.FacetCount{Value: "concert", Count: 2}) ||
+		resp.Facets.Categories[1] != (models.FacetCount{Value: "workshop", Count: 1}) {
+		t.Fatalf("unexpected facets response: %+v", resp)
+	}
+	if tags := resp.Facets.Tags; len(tags) != 3 || tags[0] != (models.FacetCount{Value: "na-żywo", Count: 2}) {
+		t.Fatalf("unexpected tag facets: %+v", tags)
+	}
+	resp = facets("tag=jazz")
+	if len(resp.Events) != 1 || len(resp.Facets.Categories) != 1 || resp.Facets.Categories[0] != (models.FacetCount{Value: "concert", Count: 1}) {
+		t.Fatalf("tag filter: unexpected category facets %+v", resp.Facets.Categories)
+	}
+	if tags := resp.Facets.Tags; len(tags) != 4 {
+		t.Fatalf("tag filter: expected all 4 tags in facets, got %+v", tags)
+	}
+
+	// Niepoprawne etykiety i kategorie są odrzucane
+	for _, body := range []string{`"category":"opera"`, `"tags":["a,b"]`} {
+		w := call(handlers.CreateEvent(db), "POST", "/events", owner, 0, fmt.Sprintf(
+			`{"title":"X","date_time":%q,"capacity":10,%s}`, futureDateTime(), body,
+		))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestPatchEvent_TagsAreVersioned(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	id := insertEvent(t, db, "published", 10)
+
+	w := call(handlers.PatchEvent(db), "PATCH", "/events/x", owner, id, `{"tags":["Warsztaty","go"],"category":"workshop"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("patch: expected 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	e := getEvent(t, db, owner, id)
+	if e.Version != 2 || e.Category != "workshop" || strings.Join(e.Tags, ",") != "go,warsztaty" {
+		t.Fatalf("unexpected event after patch: %+v", e)
+	}
+
+	// Zmiana etykiet trafia do historii
+	w = call(handlers.EventHistory(db), "GET", "/events/x/history", owner, id, "")
+	var revisions []models.EventRevision
+	json.Unmarshal(w.Body.Bytes(), &revisions)
+	if len(revisions) != 1 || revisions[0].Changes["tags"].New == nil || revisions[0].Changes["category"].New != "workshop" {
+		t.Fatalf("expected tags and category in history, got %+v", revisions)
+	}
+}
@@ -0,0 +1,108 @@
+// File: internal/handlers/tickets.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/bartbaranski/eventhub/internal/qr"
+	"github.com/bartbaranski/eventhub/internal/ticket"
+	"github.com/gorilla/mux"
+)
+
+// ticketScale to liczba pikseli na moduł kodu QR w obrazie PNG (i SVG).
+const ticketScale = 8
+
+// ticketKey zwraca klucz podpisu biletów wyprowadzony z sekretu JWT.
+func ticketKey() []byte {
+	return ticket.Key(auth.GetSecret())
+}
+
+// ReservationTicket zwraca bilety rezerwacji zalogowanego użytkownika: każde
+// miejsce (tickets > 1) ma osobny podpisany kod, aby przy wejściu można je
+// było skasować niezależnie. ?format=json (domyślnie) zwraca listę kodów,
+// ?format=png|svg – kod QR miejsca ?seat= (wymagane, gdy miejsc jest kilka).
+func ReservationTicket(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 1) Rezerwacja musi należeć do użytkownika
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		userID := int(claims["id"].(float64))
+		reservationID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var owner, eventID, tickets, generation int
+		var status string
+		if err := db.QueryRow(
+			`SELECT r.user_id, r.event_id, r.tickets, r.ticket_generation, e.status
+			 FROM reservations r JOIN events e ON e.id = r.event_id
+			 WHERE r.id=$1 AND e.deleted_at IS NULL`,
+			reservationID,
+		).Scan(&owner, &eventID, &tickets, &generation, &status); err != nil || (owner != userID && claims["role"] != "admin") {
+			http.Error(w, "Reservation not found", http.StatusNotFound)
+			return
+		}
+		if status == models.EventCancelled {
+			http.Error(w, "Event is cancelled", http.StatusConflict)
+			return
+		}
+
+		// 2) Format
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "json"
+		}
+		if format != "json" && format != "png" && format != "svg" {
+			writeFieldError(w, "format", "must be one of: json, png, svg")
+			return
+		}
+		key := ticketKey()
+		w.Header().Set("Cache-Control", "private, no-store")
+
+		if format == "json" {
+			out := make([]models.Ticket, 0, tickets)
+			for seat := 1; seat <= tickets; seat++ {
+				out = append(out, models.Ticket{
+					Seat: seat,
+					Code: ticket.Sign(key, ticket.Ticket{Reservation: reservationID, Event: eventID, Seat: seat, Generation: generation}),
+				})
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"reservation_id": reservationID,
+				"event_id":       eventID,
+				"tickets":        out,
+			})
+			return
+		}
+
+		// 3) Kod QR jednego miejsca
+		if r.URL.Query().Get("seat") == "" && tickets > 1 {
+			writeFieldError(w, "seat", "is required when the reservation has more than one ticket")
+			return
+		}
+		seat, ok := pageParam(w, r, "seat", 1, tickets)
+		if !ok {
+			return
+		}
+		t := ticket.Ticket{Reservation: reservationID, Event: eventID, Seat: seat, Generation: generation}
+		code, err := qr.Encode([]byte(ticket.Sign(key, t)), qr.M)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if format == "png" {
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(code.PNG(ticketScale))
+			return
+		}
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Write(code.SVG(ticketScale))
+	}
+}
@@ -0,0 +1,72 @@
+// File: internal/handlers/tickets_test.go
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/bartbaranski/eventhub/internal/ticket"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestReservationTicket(t *testing.T) {
+	db := newEventDB(t)
+	auth.Init("test-secret")
+	holder := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(5), "role": "participant"})
+	eventID := insertEvent(t, db, "published", 100)
+	res, err := db.Exec("INSERT INTO reservations(user_id, event_id, tickets) VALUES(5, $1, 3)", eventID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id64, _ := res.LastInsertId()
+	id := int(id64)
+
+	// JSON – osobny, poprawnie podpisany kod dla każdego miejsca
+	w := call(handlers.ReservationTicket(db), "GET", "/reservations/x/ticket", holder, id, "")
+	var resp struct {
+		Tickets []models.Ticket `json:"tickets"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || len(resp.Tickets) != 3 || w.Header().Get("Cache-Control") != "private, no-store" {
+		t.Fatalf("json: unexpected %d %s", w.Code, w.Body.String())
+	}
+	for i, tk := range resp.Tickets {
+		got, err := ticket.Verify(ticket.Key([]byte("test-secret")), tk.Code)
+		if err != nil || got != (ticket.Ticket{Reservation: id, Event: eventID, Seat: i + 1}) || tk.Seat != i+1 {
+			t.Fatalf("seat %d: unexpected %+v %+v (%v)", i+1, tk, got, err)
+		}
+	}
+
+	// PNG i SVG jednego miejsca
+	w = call(handlers.ReservationTicket(db), "GET", "/reservations/x/ticket?format=png&seat=2", holder, id, "")
+	if _, err := png.Decode(bytes.NewReader(w.Body.Bytes())); err != nil || w.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("png: unexpected %d %v (%v)", w.Code, w.Header(), err)
+	}
+	w = call(handlers.ReservationTicket(db), "GET", "/reservations/x/ticket?format=svg&seat=3", holder, id, "")
+	if w.Header().Get("Content-Type") != "image/svg+xml" || !strings.HasPrefix(w.Body.String(), "<svg") {
+		t.Fatalf("svg: unexpected %d %s", w.Code, w.Body.String())
+	}
+	for _, q := range []string{"?format=png", "?format=png&seat=4", "?format=gif&seat=1"} {
+		if w := call(handlers.ReservationTicket(db), "GET", "/reservations/x/ticket"+q, holder, id, ""); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", q, w.Code)
+		}
+	}
+
+	// Cudza rezerwacja i odwołane wydarzenie
+	stranger := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(6), "role": "participant"})
+	if w := call(handlers.ReservationTicket(db), "GET", "/reservations/x/ticket", stranger, id, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("stranger: expected 404, got %d", w.Code)
+	}
+	db.Exec("UPDATE events SET status='cancelled' WHERE id=$1", eventID)
+	if w := call(handlers.ReservationTicket(db), "GET", "/reservations/x/ticket", holder, id, ""); w.Code != http.StatusConflict {
+		t.Fatalf("cancelled event: expected 409, got %d", w.Code)
+	}
+}
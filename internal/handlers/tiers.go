@@ -0,0 +1,265 @@
+// File: internal/handlers/tiers.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/gorilla/mux"
+)
+
+// tierRequest to body tworzenia puli biletów.
+type tierRequest struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	Capacity int    `json:"capacity" validate:"min=1,max=100000"`
+	// MaxTicketsPerUser pominięte – bez limitu na konto w tej puli.
+	MaxTicketsPerUser *int `json:"max_tickets_per_user" validate:"min=1,max=100000"`
+}
+
+// tierSelect wybiera pule razem z liczbą zarezerwowanych miejsc,
+// w kolejności oczekiwanej przez scanTier.
+const tierSelect = `SELECT t.id, t.event_id, t.name, t.capacity, t.max_tickets_per_user,
+	(SELECT COALESCE(SUM(r.tickets), 0) FROM reservations r WHERE r.tier_id = t.id)
+	FROM event_tiers t`
+
+// scanTier czyta wiersz tierSelect i uzupełnia wolne miejsca.
+func scanTier(row rowScanner, t *models.EventTier) error {
+	var limit sql.NullInt64
+	if err := row.Scan(&t.ID, &t.EventID, &t.Name, &t.Capacity, &limit, &t.Booked); err != nil {
+		return err
+	}
+	if limit.Valid {
+		l := int(limit.Int64)
+		t.MaxTicketsPerUser = &l
+	}
+	t.TicketsLeft = max(t.Capacity-t.Booked, 0)
+	return nil
+}
+
+// ListTiers zwraca pule biletów wydarzenia z liczbą wolnych miejsc.
+// Są widoczne tak jak samo wydarzenie.
+func ListTiers(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		eventID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		var organizerID int
+		var status string
+		if err := db.QueryRow(
+			"SELECT organizer_id, status FROM events WHERE id=$1 AND deleted_at IS NULL", eventID,
+		).Scan(&organizerID, &status); err != nil || (status == models.EventDraft && !isOwner(r, organizerID)) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+
+		rows, err := db.Query(tierSelect+" WHERE t.event_id=$1 ORDER BY t.id", eventID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+		tiers := []models.EventTier{}
+		for rows.Next() {
+			var t models.EventTier
+			if err := scanTier(rows, &t); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			tiers = append(tiers, t)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tiers)
+	}
+}
+
+// CreateTier dodaje pulę biletów (właściciel lub admin). Pule łącznie nie
+// mogą przekraczać pojemności wydarzenia. Gdy wydarzenie ma pule, każda
+// nowa rezerwacja musi wskazać jedną z nich.
+func CreateTier(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var req tierRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		req.Name = strings.TrimSpace(req.Name)
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		// 1) Uprawnienia
+		e, _, ok := loadOwnedEvent(w, r, tx)
+		if !ok {
+			return
+		}
+
+		// 2) Nazwa i pojemność względem pozostałych pul
+		var taken, allocated int
+		if err := tx.QueryRow(
+			`SELECT COUNT(*) FROM event_tiers WHERE event_id=$1 AND LOWER(name)=LOWER($2)`, e.ID, req.Name,
+		).Scan(&taken); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if taken > 0 {
+			writeFieldError(w, "name", "is already used by another tier")
+			return
+		}
+		if err := tx.QueryRow(
+			"SELECT COALESCE(SUM(capacity), 0) FROM event_tiers WHERE event_id=$1", e.ID,
+		).Scan(&allocated); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if allocated+req.Capacity > e.Capacity {
+			writeFieldError(w, "capacity", "tiers must not exceed event capacity ("+strconv.Itoa(e.Capacity-allocated)+" left)")
+			return
+		}
+
+		// 3) Wstawienie do bazy
+		var newID int
+		if err := tx.QueryRow(
+			`INSERT INTO event_tiers(event_id, name, capacity, max_tickets_per_user)
+			 VALUES($1, $2, $3, $4) RETURNING id`,
+			e.ID, req.Name, req.Capacity, req.MaxTicketsPerUser,
+		).Scan(&newID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]int{"id": newID})
+	}
+}
+
+// DeleteTier usuwa pulę biletów (właściciel lub admin), o ile nie ma na nią
+// rezerwacji.
+func DeleteTier(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tierID, _ := strconv.Atoi(mux.Vars(r)["tid"])
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		eventID, _, ok := ownedEvent(w, r, tx)
+		if !ok {
+			return
+		}
+		var reservations int
+		if err := tx.QueryRow(
+			"SELECT COUNT(*) FROM reservations WHERE tier_id=$1 AND event_id=$2", tierID, eventID,
+		).Scan(&reservations); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if reservations > 0 {
+			http.Error(w, "Tier has reservations", http.StatusConflict)
+			return
+		}
+		res, err := tx.Exec("DELETE FROM event_tiers WHERE id=$1 AND event_id=$2", tierID, eventID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			http.Error(w, "Tier not found", http.StatusNotFound)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// checkTier sprawdza pulę nowej rezerwacji: wydarzenie z pulami wymaga
+// tier_id, a w puli musi zostać dość miejsc. Przy naruszeniu wysyła
+// odpowiedź i zwraca false. Wywoływać w transakcji rezerwacji, po
+// zablokowaniu wiersza wydarzenia.
+func checkTier(w http.ResponseWriter, q queryRower, eventID int, tierID *int, tickets int) bool {
+	if tierID == nil {
+		var tiers int
+		if err := q.QueryRow("SELECT COUNT(*) FROM event_tiers WHERE event_id=$1", eventID).Scan(&tiers); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return false
+		}
+		if tiers > 0 {
+			writeFieldError(w, "tier_id", "is required for this event")
+			return false
+		}
+		return true
+	}
+
+	var t models.EventTier
+	err := scanTier(q.QueryRow(tierSelect+" WHERE t.id=$1 AND t.event_id=$2", *tierID, eventID), &t)
+	if err == sql.ErrNoRows {
+		writeFieldError(w, "tier_id", "is not a tier of this event")
+		return false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if tickets > t.TicketsLeft {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":        "Not enough tickets left in this tier",
+			"tier_id":      t.ID,
+			"tickets_left": t.TicketsLeft,
+		})
+		return false
+	}
+	return true
+}
+
+// checkTierLimit sprawdza limit miejsc na konto w puli tierID (nil – bez
+// puli) dla użytkownika, który ma otrzymać kolejne miejsca, podobnie jak
+// checkBookingRules dla całego wydarzenia.
+func checkTierLimit(w http.ResponseWriter, q queryRower, tierID *int, userID, tickets int) bool {
+	if tierID == nil {
+		return true
+	}
+	var limit sql.NullInt64
+	var held int
+	if err := q.QueryRow(
+		`SELECT t.max_tickets_per_user,
+		 (SELECT COALESCE(SUM(r.tickets), 0) FROM reservations r WHERE r.tier_id = t.id AND r.user_id=$2)
+		 FROM event_tiers t WHERE t.id=$1`,
+		*tierID, userID,
+	).Scan(&limit, &held); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if limit.Valid && held+tickets > int(limit.Int64) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Ticket limit per user exceeded for this tier",
+			"code":    "ticket_limit",
+			"tier_id": *tierID,
+			"limit":   limit.Int64,
+			"booked":  held,
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+// File: internal/handlers/tiers_test.go
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+)
+
+// callTier wywołuje handler z trasą /events/{id}/tiers/{tid}.
+func callTier(h http.HandlerFunc, ctx context.Context, eventID, tierID int) *httptest.ResponseRecorder {
+	req := mux.SetURLVars(
+		httptest.NewRequest("DELETE", "/events/x/tiers/y", nil).WithContext(ctx),
+		map[string]string{"id": strconv.Itoa(eventID), "tid": strconv.Itoa(tierID)},
+	)
+	w := httptest.NewRecorder()
+	h(w, req)
+	return w
+}
+
+func TestTiers(t *testing.T) {
+	db, eventID := seedTransfer(t) // pojemność 100, rezerwacja 1: użytkownik 5
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	ola := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(5), "role": "participant"})
+	jan := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(6), "role": "participant"})
+
+	// Pule tworzy organizator, łącznie w granicach pojemności wydarzenia
+	create := handlers.CreateTier(db)
+	if w := call(create, "POST", "/events/x/tiers", ola, eventID, `{"name":"VIP","capacity":3}`); w.Code != http.StatusForbidden {
+		t.Fatalf("participant: expected 403, got %d", w.Code)
+	}
+	newTier := func(body string) int {
+		t.Helper()
+		w := call(create, "POST", "/events/x/tiers", owner, eventID, body)
+		var out map[string]int
+		json.Unmarshal(w.Body.Bytes(), &out)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create tier %s: expected 201, got %d %s", body, w.Code, w.Body.String())
+		}
+		return out["id"]
+	}
+	vip := newTier(`{"name":"VIP","capacity":3,"max_tickets_per_user":2}`)
+	general := newTier(`{"name":"General","capacity":90}`)
+	for body, field := range map[string]string{
+		`{"name":"vip","capacity":1}`:   "name",
+		`{"name":"Late","capacity":11}`: "capacity",
+		`{"name":"","capacity":1}`:      "name",
+	} {
+		w := call(create, "POST", "/events/x/tiers", owner, eventID, body)
+		var out struct {
+			Errors []struct{ Field string } `json:"errors"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &out)
+		if w.Code != http.StatusBadRequest || len(out.Errors) != 1 || out.Errors[0].Field != field {
+			t.Fatalf("%s: expected 400 on %s, got %d %s", body, field, w.Code, w.Body.String())
+		}
+	}
+
+	// Rezerwacja wskazuje pulę, a jej limity liczą się osobno
+	reserve := func(ctx context.Context, tier, tickets int) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"event_id":%d,"tier_id":%d,"tickets":%d}`, eventID, tier, tickets)
+		if tier == 0 {
+			body = fmt.Sprintf(`{"event_id":%d,"tickets":%d}`, eventID, tickets)
+		}
+		return call(handlers.CreateReservation(db), "POST", "/reservations", ctx, 0, body)
+	}
+	if w := reserve(ola, 0, 1); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing tier: expected 400, got %d", w.Code)
+	}
+	if w := reserve(ola, 999, 1); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown tier: expected 400, got %d", w.Code)
+	}
+	if w := reserve(ola, vip, 3); w.Code != http.StatusConflict {
+		t.Fatalf("tier limit per user: expected 409, got %d", w.Code)
+	}
+	if w := reserve(ola, vip, 2); w.Code != http.StatusCreated {
+		t.Fatalf("vip: expected 201, got %d %s", w.Code, w.Body.String())
+	}
+	w := reserve(jan, vip, 2)
+	var left struct {
+		TicketsLeft int `json:"tickets_left"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &left)
+	if w.Code != http.StatusConflict || left.TicketsLeft != 1 {
+		t.Fatalf("tier capacity: unexpected %d %s", w.Code, w.Body.String())
+	}
+	if w := reserve(jan, vip, 1); w.Code != http.StatusCreated {
+		t.Fatalf("last vip ticket: expected 201, got %d", w.Code)
+	}
+	if w := reserve(jan, general, 5); w.Code != http.StatusCreated {
+		t.Fatalf("general: expected 201, got %d", w.Code)
+	}
+
+	// Lista pul i rezerwacji
+	w = call(handlers.ListTiers(db), "GET", "/events/x/tiers", context.Background(), eventID, "")
+	var tiers []models.EventTier
+	json.Unmarshal(w.Body.Bytes(), &tiers)
+	if len(tiers) != 2 || tiers[0].Booked != 3 || tiers[0].TicketsLeft != 0 || *tiers[0].MaxTicketsPerUser != 2 ||
+		tiers[1].TicketsLeft != 85 || tiers[1].MaxTicketsPerUser != nil {
+		t.Fatalf("list: unexpected %s", w.Body.String())
+	}
+	w = call(handlers.ListReservations(db), "GET", "/reservations", jan, 0, "")
+	var list []models.Reservation
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 2 || list[0].TierID == nil || *list[0].TierID != vip {
+		t.Fatalf("reservations: unexpected %s", w.Body.String())
+	}
+
+	// Przekazanie nie omija limitu puli adresata (2 + 1 > 2)
+	verifyEmails(t, db, 5)
+	w = call(handlers.CreateTransfer(db), "POST", "/reservations/x/transfer", jan, list[0].ID, `{"email":"ola@example.com"}`)
+	var tr models.ReservationTransfer
+	json.Unmarshal(w.Body.Bytes(), &tr)
+	if w := callToken(handlers.AcceptTransfer(db), "POST", ola, tr.Token); w.Code != http.StatusConflict {
+		t.Fatalf("transfer over tier limit: expected 409, got %d %s", w.Code, w.Body.String())
+	}
+
+	// Pulę z rezerwacjami można usunąć dopiero po ich zniknięciu
+	del := handlers.DeleteTier(db)
+	if w := callTier(del, owner, eventID, vip); w.Code != http.StatusConflict {
+		t.Fatalf("delete used tier: expected 409, got %d", w.Code)
+	}
+	db.Exec("DELETE FROM reservations WHERE tier_id=$1", general)
+	if w := callTier(del, owner, eventID, general); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d %s", w.Code, w.Body.String())
+	}
+	if w := callTier(del, owner, eventID, general); w.Code != http.StatusNotFound {
+		t.Fatalf("second delete: expected 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,96 @@
+// File: internal/handlers/timezone.go
+package handlers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/bartbaranski/eventhub/internal/validate"
+)
+
+// defaultTimezone to strefa wydarzeń, dla których jej nie podano.
+const defaultTimezone = "UTC"
+
+// locations cache'uje wczytane strefy (LoadLocation czyta bazę tzdata za każdym razem).
+var locations sync.Map
+
+// location zwraca strefę o danej nazwie IANA; nieznana nazwa daje UTC.
+func location(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	locations.Store(name, loc)
+	return loc
+}
+
+// localize uzupełnia reprezentacje lokalne wydarzenia (w jego strefie).
+// Daty z bazy są w UTC.
+func localize(e *models.Event) {
+	if e.Timezone == "" {
+		e.Timezone = defaultTimezone
+	}
+	loc := location(e.Timezone)
+	e.Date = e.Date.UTC()
+	e.LocalDate = e.Date.In(loc).Format(time.RFC3339)
+	e.LocalEndDate = ""
+	if e.EndDate != nil {
+		end := e.EndDate.UTC()
+		e.EndDate = &end
+		e.LocalEndDate = end.In(loc).Format(time.RFC3339)
+	}
+}
+
+// eventTimes parsuje początek i (opcjonalny) koniec wydarzenia w strefie tz.
+// Daty bez strefy są czasem lokalnym w tz, daty RFC 3339 – konkretną chwilą.
+// Początek musi być w przyszłości, chyba że równa się unchanged – bieżącemu
+// terminowi edytowanego wydarzenia (przy tworzeniu: zero), żeby dało się
+// poprawiać inne pola wydarzeń, które już się zaczęły.
+// W razie błędu sam wysyła 400 (z nazwą pola) i zwraca false.
+func eventTimes(w http.ResponseWriter, dateTime, endDateTime, tz string, unchanged time.Time) (time.Time, *time.Time, bool) {
+	start, end, fe := parseEventTimes(dateTime, endDateTime, tz, unchanged)
+	if fe != nil {
+		writeFieldError(w, fe.Field, fe.Message)
+		return start, nil, false
+	}
+	return start, end, true
+}
+
+// parseEventTimes to eventTimes zwracające błąd pola zamiast odpowiedzi.
+func parseEventTimes(dateTime, endDateTime, tz string, unchanged time.Time) (time.Time, *time.Time, *validate.FieldError) {
+	loc := location(tz)
+	start, err := validate.ParseDateTime(dateTime, loc)
+	if err != nil {
+		return start, nil, &validate.FieldError{Field: "date_time", Message: "must be an RFC 3339 date-time or use format YYYY-MM-DDTHH:MM"}
+	}
+	if !start.Equal(unchanged) && !start.After(time.Now()) {
+		return start, nil, &validate.FieldError{Field: "date_time", Message: "must be in the future"}
+	}
+	if endDateTime == "" {
+		return start, nil, nil
+	}
+	end, err := validate.ParseDateTime(endDateTime, loc)
+	if err != nil {
+		return start, nil, &validate.FieldError{Field: "end_date_time", Message: "must be an RFC 3339 date-time or use format YYYY-MM-DDTHH:MM"}
+	}
+	if !end.After(start) {
+		return start, nil, &validate.FieldError{Field: "end_date_time", Message: "must be after date_time"}
+	}
+	return start, &end, nil
+}
+
+// withClock zwraca dzień t (w strefie loc) z godziną wziętą z clock – tak
+// przesuwamy godzinę wystąpień serii bez rozjeżdżania się przy zmianie czasu.
+func withClock(t, clock time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	c := clock.In(loc)
+	return time.Date(y, m, d, c.Hour(), c.Minute(), c.Second(), 0, loc).UTC()
+}
@@ -0,0 +1,483 @@
+// File: internal/handlers/transfers.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/gorilla/mux"
+)
+
+// transferTTL to czas, w jakim adresat może przyjąć przekazanie.
+const transferTTL = 7 * 24 * time.Hour
+
+const transferSelect = `SELECT t.id, t.reservation_id, r.event_id, e.title, e.date, r.tickets,
+	u.email, t.to_email, t.token, t.status, t.created_at, t.expires_at
+	FROM reservation_transfers t
+	JOIN reservations r ON r.id = t.reservation_id
+	JOIN events e ON e.id = r.event_id
+	JOIN users u ON u.id = t.from_user_id`
+
+func scanTransfer(row rowScanner, t *models.ReservationTransfer) error {
+	if err := row.Scan(
+		&t.ID, &t.ReservationID, &t.EventID, &t.EventTitle, &t.EventDate, &t.Tickets,
+		&t.FromEmail, &t.ToEmail, &t.Token, &t.Status, &t.CreatedAt, &t.ExpiresAt,
+	); err != nil {
+		return err
+	}
+	t.EventDate, t.CreatedAt, t.ExpiresAt = t.EventDate.UTC(), t.CreatedAt.UTC(), t.ExpiresAt.UTC()
+	return nil
+}
+
+// transferBlocked zwraca powód, dla którego rezerwacji nie można teraz
+// przekazać (pusty – można): wydarzenie musi trwać lub czekać na nowy
+// termin, mieć włączone przekazania, a żaden bilet nie może być skasowany.
+func transferBlocked(q queryRower, reservationID int) (string, error) {
+	var status string
+	var enabled bool
+	var checkins int
+	err := q.QueryRow(
+		`SELECT e.status, e.transfers_enabled FROM reservations r JOIN events e ON e.id = r.event_id
+		 WHERE r.id=$1 AND e.deleted_at IS NULL`,
+		reservationID,
+	).Scan(&status, &enabled)
+	if err == sql.ErrNoRows {
+		return "Event not found", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if status != models.EventPublished && status != models.EventPostponed {
+		return "Event is not open for transfers", nil
+	}
+	if !enabled {
+		return "Transfers are disabled for this event", nil
+	}
+	if err := q.QueryRow("SELECT COUNT(*) FROM checkins WHERE reservation_id=$1", reservationID).Scan(&checkins); err != nil {
+		return "", err
+	}
+	if checkins > 0 {
+		return "Tickets have already been used", nil
+	}
+	return "", nil
+}
+
+// moveSessionSignups przenosi zapisy nadawcy na sesje wydarzenia na
+// adresata, jeśli nadawca nie ma już innej rezerwacji na to wydarzenie.
+// Sesje, na które adresat jest już zapisany, zwalniają miejsce nadawcy.
+func moveSessionSignups(tx *sql.Tx, eventID, fromUserID, toUserID int) error {
+	var other int
+	if err := tx.QueryRow(
+		"SELECT COUNT(*) FROM reservations WHERE user_id=$1 AND event_id=$2", fromUserID, eventID,
+	).Scan(&other); err != nil {
+		return err
+	}
+	if other > 0 {
+		return nil
+	}
+
+	const duplicates = `SELECT session_id FROM session_signups WHERE event_id=$1 AND user_id=$3`
+	if _, err := tx.Exec(
+		`UPDATE event_sessions SET signed_up = signed_up - 1
+		 WHERE id IN (SELECT session_id FROM session_signups WHERE event_id=$1 AND user_id=$2)
+		 AND id IN (`+duplicates+`) AND signed_up > 0`,
+		eventID, fromUserID, toUserID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`DELETE FROM session_signups WHERE event_id=$1 AND user_id=$2 AND session_id IN (`+duplicates+`)`,
+		eventID, fromUserID, toUserID,
+	); err != nil {
+		return err
+	}
+	_, err := tx.Exec(
+		"UPDATE session_signups SET user_id=$1 WHERE event_id=$2 AND user_id=$3",
+		toUserID, eventID, fromUserID,
+	)
+	return err
+}
+
+// pendingTransfers zwraca oczekujące (i nieprzeterminowane) przekazania
+// spełniające warunek where.
+func pendingTransfers(db *sql.DB, where string, args ...interface{}) ([]models.ReservationTransfer, error) {
+	args = append(args, models.TransferPending, time.Now().UTC())
+	n := strconv.Itoa(len(args))
+	rows, err := db.Query(
+		transferSelect+" WHERE "+where+" AND t.status=$"+strconv.Itoa(len(args)-1)+" AND t.expires_at > $"+n+" ORDER BY t.id",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.ReservationTransfer{}
+	for rows.Next() {
+		var t models.ReservationTransfer
+		if err := scanTransfer(rows, &t); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// CreateTransfer rozpoczyna przekazanie rezerwacji zalogowanego użytkownika
+// osobie o podanym adresie e-mail. Adresat przyjmuje je (po zalogowaniu lub
+// rejestracji na ten adres) przez POST /transfers/{token}/accept.
+func CreateTransfer(db *sql.DB) http.HandlerFunc {
+	type request struct {
+		Email string `json:"email" validate:"required,email,max=255"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// 1) Rezerwacja musi należeć do użytkownika
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		userID := int(claims["id"].(float64))
+		reservationID, _ := strconv.Atoi(mux.Vars(r)["id"])
+		var req request
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		toEmail := strings.ToLower(strings.TrimSpace(req.Email))
+
+		var owner, eventID int
+		var ownEmail string
+		if err := db.QueryRow(
+			"SELECT r.user_id, r.event_id, u.email FROM reservations r JOIN users u ON u.id = r.user_id WHERE r.id=$1", reservationID,
+		).Scan(&owner, &eventID, &ownEmail); err != nil || owner != userID {
+			http.Error(w, "Reservation not found", http.StatusNotFound)
+			return
+		}
+		if strings.EqualFold(ownEmail, toEmail) {
+			writeFieldError(w, "email", "cannot transfer a reservation to yourself")
+			return
+		}
+
+		// 2) Warunki wydarzenia i brak innego oczekującego przekazania
+		if reason, err := transferBlocked(db, reservationID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		} else if reason != "" {
+			http.Error(w, reason, http.StatusConflict)
+			return
+		}
+		if pending, err := pendingTransfers(db, "t.reservation_id=$1", reservationID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		} else if len(pending) > 0 {
+			http.Error(w, "Reservation already has a pending transfer", http.StatusConflict)
+			return
+		}
+
+		// 3) Zapis z sekretnym tokenem do linku (przeterminowane przekazanie
+		// zwalnia miejsce w indeksie unikalnym oczekujących)
+		now := time.Now().UTC()
+		if _, err := db.Exec(
+			"UPDATE reservation_transfers SET status=$1 WHERE reservation_id=$2 AND status=$3 AND expires_at <= $4",
+			models.TransferCancelled, reservationID, models.TransferPending, now,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		token, err := newSecretToken()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if _, err := db.Exec(
+			`INSERT INTO reservation_transfers(reservation_id, event_id, from_user_id, to_email, token, status, created_at, expires_at)
+			 VALUES($1, $2, $3, $4, $5, $6, $7, $8)`,
+			reservationID, eventID, userID, toEmail, token, models.TransferPending, now, now.Add(transferTTL),
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		var t models.ReservationTransfer
+		if err := scanTransfer(db.QueryRow(transferSelect+" WHERE t.token=$1", token), &t); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(t)
+	}
+}
+
+// CancelTransfer wycofuje oczekujące przekazanie rezerwacji.
+func CancelTransfer(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		userID := int(claims["id"].(float64))
+		reservationID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		res, err := db.Exec(
+			`UPDATE reservation_transfers SET status=$1
+			 WHERE reservation_id=$2 AND from_user_id=$3 AND status=$4`,
+			models.TransferCancelled, reservationID, userID, models.TransferPending,
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			http.Error(w, "No pending transfer", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// IncomingTransfers zwraca oczekujące przekazania na adres zalogowanego
+// użytkownika – tylko potwierdzony, bo samo konto na dany adres nie dowodzi,
+// że należy on do użytkownika. Tokenów nie zwracamy (jak GetTransfer); z listy
+// przekazanie przyjmuje się po ID (AcceptIncomingTransfer).
+func IncomingTransfers(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		email, ok := verifiedEmail(w, db, int(claims["id"].(float64)))
+		if !ok {
+			return
+		}
+		out, err := pendingTransfers(db, "t.to_email=$1", strings.ToLower(email))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for i := range out {
+			out[i].Token = ""
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(out)
+	}
+}
+
+// verifiedEmail zwraca adres użytkownika, o ile go potwierdził. W przeciwnym
+// razie sam wysyła odpowiedź (403 z code "email_not_verified") i zwraca false.
+func verifiedEmail(w http.ResponseWriter, q queryRower, userID int) (string, bool) {
+	var email string
+	var verifiedAt sql.NullTime
+	err := q.QueryRow("SELECT email, email_verified_at FROM users WHERE id=$1", userID).Scan(&email, &verifiedAt)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return "", false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return "", false
+	}
+	if !verifiedAt.Valid {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Email address must be verified to receive transfers",
+			"code":  "email_not_verified",
+		})
+		return "", false
+	}
+	return email, true
+}
+
+// GetTransfer zwraca przekazanie po tokenie z linku – bez logowania, aby
+// adresat bez konta wiedział, na jaki adres się zarejestrować.
+func GetTransfer(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var t models.ReservationTransfer
+		if err := scanTransfer(db.QueryRow(transferSelect+" WHERE t.token=$1", mux.Vars(r)["token"]), &t); err != nil {
+			http.Error(w, "Transfer not found", http.StatusNotFound)
+			return
+		}
+		t.Token = ""
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t)
+	}
+}
+
+// AcceptTransfer przyjmuje przekazanie z linku (po tokenie): rezerwacja
+// atomowo zmienia właściciela, a generacja biletów rośnie – dotychczasowe
+// kody przestają działać, a nowy właściciel pobiera nowe
+// (GET /reservations/{id}/ticket). Zapisy nadawcy na sesje przechodzą razem
+// z rezerwacją (moveSessionSignups). Adresat musi mieć potwierdzony e-mail.
+func AcceptTransfer(db *sql.DB) http.HandlerFunc {
+	return acceptTransfer(db, "t.token=$1", func(r *http.Request) interface{} {
+		return mux.Vars(r)["token"]
+	})
+}
+
+// AcceptIncomingTransfer to AcceptTransfer dla przekazania z listy
+// IncomingTransfers – po ID zamiast tokenu.
+func AcceptIncomingTransfer(db *sql.DB) http.HandlerFunc {
+	return acceptTransfer(db, "t.id=$1", func(r *http.Request) interface{} {
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		return id
+	})
+}
+
+// acceptTransfer przyjmuje przekazanie wskazane warunkiem where z kluczem z żądania.
+func acceptTransfer(db *sql.DB, where string, key func(*http.Request) interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// 1) Adresat
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		userID := int(claims["id"].(float64))
+		email, ok := verifiedEmail(w, db, userID)
+		if !ok {
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		// 2) Przekazanie – oczekujące, aktualne i skierowane do tego użytkownika
+		var t models.ReservationTransfer
+		var rsv models.Reservation
+		var fromUserID int
+		if err := tx.QueryRow(
+			`SELECT t.id, t.reservation_id, r.event_id, r.tickets, t.from_user_id, t.to_email, t.status, t.expires_at
+			 FROM reservation_transfers t JOIN reservations r ON r.id = t.reservation_id WHERE `+where,
+			key(r),
+		).Scan(&t.ID, &t.ReservationID, &t.EventID, &t.Tickets, &fromUserID, &t.ToEmail, &t.Status, &t.ExpiresAt); err != nil {
+			http.Error(w, "Transfer not found", http.StatusNotFound)
+			return
+		}
+		if !strings.EqualFold(t.ToEmail, email) {
+			http.Error(w, "Transfer is addressed to another user", http.StatusForbidden)
+			return
+		}
+		if t.Status != models.TransferPending {
+			http.Error(w, "Transfer is "+t.Status, http.StatusConflict)
+			return
+		}
+		now := time.Now().UTC()
+		if !t.ExpiresAt.After(now) {
+			http.Error(w, "Transfer has expired", http.StatusGone)
+			return
+		}
+		if reason, err := transferBlocked(tx, t.ReservationID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		} else if reason != "" {
+			http.Error(w, reason, http.StatusConflict)
+			return
+		}
+		// Przekazanie nie może omijać limitów miejsc na konto (także w puli)
+		var tierID sql.NullInt64
+		if err := tx.QueryRow("SELECT tier_id FROM reservations WHERE id=$1", t.ReservationID).Scan(&tierID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if tierID.Valid {
+			id := int(tierID.Int64)
+			rsv.TierID = &id
+		}
+		if !checkBookingRules(w, tx, t.EventID, userID, t.Tickets) ||
+			!checkTierLimit(w, tx, rsv.TierID, userID, t.Tickets) {
+			return
+		}
+
+		// 3) Zmiana właściciela i nowa generacja biletów – oba UPDATE-y
+		// z warunkiem, więc równoległe przyjęcie lub wycofanie nic nie zepsuje
+		res, err := tx.Exec(
+			"UPDATE reservation_transfers SET status=$1, accepted_by=$2, accepted_at=$3 WHERE id=$4 AND status=$5",
+			models.TransferAccepted, userID, now, t.ID, models.TransferPending,
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			http.Error(w, "Transfer changed concurrently", http.StatusConflict)
+			return
+		}
+		res, err = tx.Exec(
+			"UPDATE reservations SET user_id=$1, ticket_generation=ticket_generation+1 WHERE id=$2 AND user_id=$3",
+			userID, t.ReservationID, fromUserID,
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			http.Error(w, "Reservation changed concurrently", http.StatusConflict)
+			return
+		}
+		if err := moveSessionSignups(tx, t.EventID, fromUserID, userID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := tx.QueryRow(
+			"SELECT id, user_id, event_id, tickets, created_at FROM reservations WHERE id=$1", t.ReservationID,
+		).Scan(&rsv.ID, &rsv.UserID, &rsv.EventID, &rsv.Tickets, &rsv.CreatedAt); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(rsv)
+	}
+}
+
+// SetEventTransfers włącza lub wyłącza przekazywanie rezerwacji na
+// wydarzenie (właściciel lub admin). Oczekujące przekazania nie znikają, ale
+// nie da się ich przyjąć, dopóki przekazania są wyłączone.
+func SetEventTransfers(db *sql.DB) http.HandlerFunc {
+	type request struct {
+		Enabled *bool `json:"enabled" validate:"required"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		eventID, _, ok := ownedEvent(w, r, db)
+		if !ok {
+			return
+		}
+		var req request
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		if _, err := db.Exec(
+			"UPDATE events SET transfers_enabled=$1, version=version+1, updated_at=$2 WHERE id=$3",
+			*req.Enabled, time.Now().UTC(), eventID,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":                eventID,
+			"transfers_enabled": *req.Enabled,
+		})
+	}
+}
@@ -0,0 +1,263 @@
+// File: internal/handlers/transfers_test.go
+package handlers_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+)
+
+// callToken wywołuje handler z parametrem {token} w ścieżce.
+func callToken(h http.HandlerFunc, method string, ctx context.Context, token string) *httptest.ResponseRecorder {
+	req := mux.SetURLVars(
+		httptest.NewRequest(method, "/transfers/x", nil).WithContext(ctx),
+		map[string]string{"token": token},
+	)
+	w := httptest.NewRecorder()
+	h(w, req)
+	return w
+}
+
+// seedTransfer tworzy wydarzenie z rezerwacją (2 miejsca) użytkownika 5
+// oraz drugiego użytkownika 6 (jan@example.com). Adresy nie są potwierdzone –
+// przed przyjęciem przekazania potwierdza je verifyEmails.
+func seedTransfer(t *testing.T) (*sql.DB, int) {
+	t.Helper()
+	db := newEventDB(t)
+	auth.Init("test-secret")
+	eventID := insertEvent(t, db, "published", 100)
+	db.Exec("INSERT INTO users(id, email, password_hash, role) VALUES(5, 'ola@example.com', 'x', 'participant')")
+	db.Exec("INSERT INTO users(id, email, password_hash, role) VALUES(6, 'jan@example.com', 'x', 'participant')")
+	db.Exec("INSERT INTO reservations(id, user_id, event_id, tickets, created_at) VALUES(1, 5, $1, 2, CURRENT_TIMESTAMP)", eventID)
+	return db, eventID
+}
+
+// verifyEmails oznacza adresy użytkowników jako potwierdzone.
+func verifyEmails(t *testing.T, db *sql.DB, ids ...int) {
+	t.Helper()
+	for _, id := range ids {
+		if _, err := db.Exec("UPDATE users SET email_verified_at=CURRENT_TIMESTAMP WHERE id=$1", id); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func ticketCodes(t *testing.T, db *sql.DB, ctx context.Context) []models.Ticket {
+	t.Helper()
+	w := call(handlers.ReservationTicket(db), "GET", "/reservations/x/ticket", ctx, 1, "")
+	var body struct {
+		Tickets []models.Ticket `json:"tickets"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusOK || len(body.Tickets) != 2 {
+		t.Fatalf("tickets: unexpected %d %s", w.Code, w.Body.String())
+	}
+	return body.Tickets
+}
+
+func TestTransferReservation(t *testing.T) {
+	db, eventID := seedTransfer(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	ola := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(5), "role": "participant"})
+	jan := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(6), "role": "participant"})
+	oldCodes := ticketCodes(t, db, ola)
+
+	// Przekazać może tylko właściciel, i nie sobie
+	create := handlers.CreateTransfer(db)
+	if w := call(create, "POST", "/reservations/x/transfer", jan, 1, `{"email":"ola@example.com"}`); w.Code != http.StatusNotFound {
+		t.Fatalf("foreign reservation: expected 404, got %d", w.Code)
+	}
+	if w := call(create, "POST", "/reservations/x/transfer", ola, 1, `{"email":"OLA@example.com"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("self transfer: expected 400, got %d", w.Code)
+	}
+	w := call(create, "POST", "/reservations/x/transfer", ola, 1, `{"email":"Jan@Example.com"}`)
+	var tr models.ReservationTransfer
+	json.Unmarshal(w.Body.Bytes(), &tr)
+	if w.Code != http.StatusCreated || tr.Token == "" || tr.ToEmail != "jan@example.com" || tr.FromEmail != "ola@example.com" || tr.Tickets != 2 {
+		t.Fatalf("create: unexpected %d %s", w.Code, w.Body.String())
+	}
+	if w := call(create, "POST", "/reservations/x/transfer", ola, 1, `{"email":"ewa@example.com"}`); w.Code != http.StatusConflict {
+		t.Fatalf("second pending transfer: expected 409, got %d", w.Code)
+	}
+
+	// Nadawca widzi przekazanie przy rezerwacji, a podgląd z linku nie ujawnia tokenu
+	w = call(handlers.ListReservations(db), "GET", "/reservations", ola, 0, "")
+	var list []models.Reservation
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 1 || list[0].Transfer == nil || list[0].Transfer.Token != tr.Token {
+		t.Fatalf("list: expected pending transfer, got %s", w.Body.String())
+	}
+	w = callToken(handlers.GetTransfer(db), "GET", context.Background(), tr.Token)
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), tr.Token) {
+		t.Fatalf("preview: unexpected %d %s", w.Code, w.Body.String())
+	}
+
+	// Niepotwierdzony adres nie wystarcza ani do listy, ani do przyjęcia
+	if w := call(handlers.IncomingTransfers(db), "GET", "/transfers", jan, 0, ""); w.Code != http.StatusForbidden {
+		t.Fatalf("unverified incoming: expected 403, got %d", w.Code)
+	}
+	accept := handlers.AcceptTransfer(db)
+	if w := callToken(accept, "POST", jan, tr.Token); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "email_not_verified") {
+		t.Fatalf("unverified accept: expected 403, got %d %s", w.Code, w.Body.String())
+	}
+
+	// Po potwierdzeniu adresat widzi przekazanie na swojej liście – też bez tokenu
+	verifyEmails(t, db, 5, 6)
+	w = call(handlers.IncomingTransfers(db), "GET", "/transfers", jan, 0, "")
+	var incoming []models.ReservationTransfer
+	json.Unmarshal(w.Body.Bytes(), &incoming)
+	if len(incoming) != 1 || incoming[0].ReservationID != 1 || incoming[0].Token != "" {
+		t.Fatalf("incoming: unexpected %s", w.Body.String())
+	}
+
+	// Przyjąć może tylko adresat, i tylko raz
+	if w := callToken(accept, "POST", ola, tr.Token); w.Code != http.StatusForbidden {
+		t.Fatalf("wrong recipient: expected 403, got %d", w.Code)
+	}
+	w = callToken(accept, "POST", jan, tr.Token)
+	var rsv models.Reservation
+	json.Unmarshal(w.Body.Bytes(), &rsv)
+	if w.Code != http.StatusOK || rsv.UserID != 6 || rsv.Tickets != 2 {
+		t.Fatalf("accept: unexpected %d %s", w.Code, w.Body.String())
+	}
+	if w := callToken(accept, "POST", jan, tr.Token); w.Code != http.StatusConflict {
+		t.Fatalf("double accept: expected 409, got %d", w.Code)
+	}
+
+	// Stare kody nie działają, nowe – tak
+	if w := call(handlers.ReservationTicket(db), "GET", "/reservations/x/ticket", ola, 1, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("previous owner tickets: expected 404, got %d", w.Code)
+	}
+	w = call(handlers.CheckIn(db), "POST", "/events/x/checkin", owner, eventID, fmt.Sprintf(`{"code":%q}`, oldCodes[0].Code))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "no longer valid") {
+		t.Fatalf("old code: expected 400, got %d %s", w.Code, w.Body.String())
+	}
+	newCodes := ticketCodes(t, db, jan)
+	if newCodes[0].Code == oldCodes[0].Code {
+		t.Fatal("expected reissued ticket codes")
+	}
+	if w := call(handlers.CheckIn(db), "POST", "/events/x/checkin", owner, eventID, fmt.Sprintf(`{"code":%q}`, newCodes[0].Code)); w.Code != http.StatusCreated {
+		t.Fatalf("new code: expected 201, got %d %s", w.Code, w.Body.String())
+	}
+
+	// Po wejściu rezerwacji nie da się już przekazać
+	if w := call(create, "POST", "/reservations/x/transfer", jan, 1, `{"email":"ola@example.com"}`); w.Code != http.StatusConflict {
+		t.Fatalf("used tickets: expected 409, got %d", w.Code)
+	}
+}
+
+func TestEventTransfersDisabled(t *testing.T) {
+	db, eventID := seedTransfer(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	ola := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(5), "role": "participant"})
+	jan := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(6), "role": "participant"})
+
+	verifyEmails(t, db, 6)
+	w := call(handlers.CreateTransfer(db), "POST", "/reservations/x/transfer", ola, 1, `{"email":"jan@example.com"}`)
+	var tr models.ReservationTransfer
+	json.Unmarshal(w.Body.Bytes(), &tr)
+
+	// Wyłączyć przekazania może tylko organizator wydarzenia
+	set := handlers.SetEventTransfers(db)
+	if w := call(set, "PUT", "/events/x/transfers", ola, eventID, `{"enabled":false}`); w.Code != http.StatusForbidden {
+		t.Fatalf("participant toggle: expected 403, got %d", w.Code)
+	}
+	if w := call(set, "PUT", "/events/x/transfers", owner, eventID, `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing enabled: expected 400, got %d", w.Code)
+	}
+	if w := call(set, "PUT", "/events/x/transfers", owner, eventID, `{"enabled":false}`); w.Code != http.StatusOK {
+		t.Fatalf("toggle: expected 200, got %d %s", w.Code, w.Body.String())
+	}
+
+	// Oczekującego przekazania nie da się przyjąć, nowego – utworzyć
+	if w := callToken(handlers.AcceptTransfer(db), "POST", jan, tr.Token); w.Code != http.StatusConflict {
+		t.Fatalf("accept when disabled: expected 409, got %d", w.Code)
+	}
+	var userID int
+	db.QueryRow("SELECT user_id FROM reservations WHERE id=1").Scan(&userID)
+	if userID != 5 {
+		t.Fatalf("reservation must stay with its owner, got user %d", userID)
+	}
+	cancel := handlers.CancelTransfer(db)
+	if w := call(cancel, "DELETE", "/reservations/x/transfer", ola, 1, ""); w.Code != http.StatusNoContent {
+		t.Fatalf("cancel: expected 204, got %d", w.Code)
+	}
+	if w := call(cancel, "DELETE", "/reservations/x/transfer", ola, 1, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("second cancel: expected 404, got %d", w.Code)
+	}
+	if w := call(handlers.CreateTransfer(db), "POST", "/reservations/x/transfer", ola, 1, `{"email":"jan@example.com"}`); w.Code != http.StatusConflict {
+		t.Fatalf("create when disabled: expected 409, got %d", w.Code)
+	}
+}
+
+func TestTransferMovesSessionSignups(t *testing.T) {
+	db, eventID := seedTransfer(t)
+	ola := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(5), "role": "participant"})
+	jan := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(6), "role": "participant"})
+
+	verifyEmails(t, db, 6)
+
+	// Ola ma dwie rezerwacje i zapisy na obie sesje; Jan jest już na sesji 2
+	db.Exec("INSERT INTO reservations(id, user_id, event_id, tickets, created_at) VALUES(2, 5, $1, 1, CURRENT_TIMESTAMP)", eventID)
+	for _, id := range []int{1, 2} {
+		db.Exec(`INSERT INTO event_sessions(id, event_id, title, starts_at, ends_at, signed_up)
+			VALUES($1, $2, 'Sesja', '2030-01-01 10:00:00', '2030-01-01 11:00:00', $3)`, id, eventID, id)
+	}
+	db.Exec(`INSERT INTO session_signups(session_id, event_id, user_id, created_at) VALUES
+		(1, $1, 5, CURRENT_TIMESTAMP), (2, $1, 5, CURRENT_TIMESTAMP), (2, $1, 6, CURRENT_TIMESTAMP)`, eventID)
+
+	// Adresat przyjmuje przekazanie z listy oczekujących (po ID)
+	transfer := func(reservationID int) {
+		t.Helper()
+		call(handlers.CreateTransfer(db), "POST", "/reservations/x/transfer", ola, reservationID, `{"email":"jan@example.com"}`)
+		var incoming []models.ReservationTransfer
+		json.Unmarshal(call(handlers.IncomingTransfers(db), "GET", "/transfers", jan, 0, "").Body.Bytes(), &incoming)
+		if len(incoming) != 1 {
+			t.Fatalf("reservation %d: expected one incoming transfer, got %d", reservationID, len(incoming))
+		}
+		if w := call(handlers.AcceptIncomingTransfer(db), "POST", "/transfers/incoming/x/accept", jan, incoming[0].ID, ""); w.Code != http.StatusOK {
+			t.Fatalf("accept reservation %d: expected 200, got %d %s", reservationID, w.Code, w.Body.String())
+		}
+	}
+	signups := func() string {
+		t.Helper()
+		rows, err := db.Query(`SELECT s.id, s.signed_up, COALESCE(GROUP_CONCAT(u.user_id), '')
+			FROM event_sessions s LEFT JOIN session_signups u ON u.session_id = s.id
+			GROUP BY s.id, s.signed_up ORDER BY s.id`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var out []string
+		for rows.Next() {
+			var id, signedUp int
+			var users string
+			rows.Scan(&id, &signedUp, &users)
+			out = append(out, fmt.Sprintf("%d:%d[%s]", id, signedUp, users))
+		}
+		return strings.Join(out, " ")
+	}
+
+	// Nadawca z inną rezerwacją zachowuje swoje zapisy
+	transfer(1)
+	if got := signups(); got != "1:1[5] 2:2[5,6]" && got != "1:1[5] 2:2[6,5]" {
+		t.Fatalf("sender still attends: unexpected signups %s", got)
+	}
+
+	// Po ostatniej rezerwacji zapisy przechodzą na adresata, a podwójny zwalnia miejsce
+	transfer(2)
+	if got := signups(); got != "1:1[6] 2:1[6]" {
+		t.Fatalf("last reservation: unexpected signups %s", got)
+	}
+}
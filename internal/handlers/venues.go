@@ -0,0 +1,323 @@
+// File: internal/handlers/venues.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/geo"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/bartbaranski/eventhub/internal/validate"
+	"github.com/gorilla/mux"
+)
+
+// Promień wyszukiwania w pobliżu (?near=) – domyślny i maksymalny, w km.
+const (
+	defaultNearRadiusKm = 10
+	maxNearRadiusKm     = 500
+)
+
+// venueRequest to body tworzenia i aktualizacji miejsca.
+type venueRequest struct {
+	Name      string   `json:"name" validate:"required,max=255"`
+	Address   string   `json:"address" validate:"required,max=500"`
+	Latitude  *float64 `json:"latitude" validate:"required,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" validate:"required,min=-180,max=180"`
+	// Capacity 0 (lub pominięte) oznacza brak limitu miejsca.
+	Capacity      int                       `json:"capacity" validate:"min=0,max=1000000"`
+	Accessibility models.VenueAccessibility `json:"accessibility"`
+}
+
+// maxAccessibilityNotes ogranicza długość opisu dostępności (w znakach).
+const maxAccessibilityNotes = 2000
+
+// venueColumns to kolumny miejsca w kolejności oczekiwanej przez scanVenue.
+const venueColumns = `id, organizer_id, name, address, latitude, longitude, capacity, accessibility`
+
+func scanVenue(row rowScanner, v *models.Venue) error {
+	var accessibility string
+	if err := row.Scan(
+		&v.ID, &v.OrganizerID, &v.Name, &v.Address, &v.Latitude, &v.Longitude, &v.Capacity, &accessibility,
+	); err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(accessibility), &v.Accessibility)
+}
+
+// queryRower to wspólny interfejs *sql.DB i *sql.Tx dla pojedynczych odczytów.
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// checkVenue sprawdza, czy wskazane miejsce istnieje i mieści capacity osób.
+// W razie błędu sam wysyła 400 (z nazwą pola) i zwraca false.
+func checkVenue(w http.ResponseWriter, q queryRower, venueID *int, capacity int) bool {
+	if fe := venueError(q, venueID, capacity); fe != nil {
+		writeFieldError(w, fe.Field, fe.Message)
+		return false
+	}
+	return true
+}
+
+// venueError to checkVenue zwracające błąd pola zamiast odpowiedzi.
+func venueError(q queryRower, venueID *int, capacity int) *validate.FieldError {
+	if venueID == nil {
+		return nil
+	}
+	var limit int
+	if err := q.QueryRow("SELECT capacity FROM venues WHERE id=$1", *venueID).Scan(&limit); err != nil {
+		return &validate.FieldError{Field: "venue_id", Message: "venue does not exist"}
+	}
+	if limit > 0 && capacity > limit {
+		return &validate.FieldError{Field: "capacity", Message: "must not exceed venue capacity (" + strconv.Itoa(limit) + ")"}
+	}
+	return nil
+}
+
+// decodeVenue dekoduje i waliduje body miejsca.
+func decodeVenue(w http.ResponseWriter, r *http.Request) (venueRequest, bool) {
+	var req venueRequest
+	if !decodeJSON(w, r, &req) {
+		return req, false
+	}
+	if utf8.RuneCountInString(req.Accessibility.Notes) > maxAccessibilityNotes {
+		writeFieldError(w, "accessibility.notes", "must have at most "+strconv.Itoa(maxAccessibilityNotes)+" characters")
+		return req, false
+	}
+	return req, true
+}
+
+// CreateVenue tworzy miejsce (tylko organizator). Z miejsca mogą potem
+// korzystać wydarzenia wszystkich organizatorów.
+func CreateVenue(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// 1) Uwierzytelnienie
+		claims, ok := auth.FromContext(r.Context())
+		if !ok || claims["role"] != "organizer" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		// 2) Dekodowanie requestu
+		req, ok := decodeVenue(w, r)
+		if !ok {
+			return
+		}
+		accessibility, _ := json.Marshal(req.Accessibility)
+
+		// 3) Wstawienie do bazy
+		var newID int
+		err := db.QueryRow(
+			`INSERT INTO venues(organizer_id, name, address, latitude, longitude, capacity, accessibility)
+			 VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+			int(claims["id"].(float64)), req.Name, req.Address, *req.Latitude, *req.Longitude, req.Capacity, string(accessibility),
+		).Scan(&newID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]int{"id": newID})
+	}
+}
+
+// ListVenues zwraca miejsca posortowane po nazwie. Opcjonalne ?q= zawęża
+// listę do miejsc, których nazwa lub adres zawiera podany tekst.
+func ListVenues(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := "SELECT " + venueColumns + " FROM venues"
+		var args []interface{}
+		if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" {
+			query += " WHERE LOWER(name) LIKE $1 OR LOWER(address) LIKE $1"
+			args = append(args, "%"+strings.ToLower(q)+"%")
+		}
+		rows, err := db.Query(query+" ORDER BY name, id", args...)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		venues := []models.Venue{}
+		for rows.Next() {
+			var v models.Venue
+			if err := scanVenue(rows, &v); err != nil {
+				continue
+			}
+			venues = append(venues, v)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(venues)
+	}
+}
+
+// GetVenue zwraca pojedyncze miejsce po ID.
+func GetVenue(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		var v models.Venue
+		if err := scanVenue(db.QueryRow("SELECT "+venueColumns+" FROM venues WHERE id=$1", id), &v); err != nil {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+// UpdateVenue aktualizuje miejsce (tylko organizator, który je utworzył).
+// Pojemności nie można zmniejszyć poniżej pojemności nadchodzących wydarzeń.
+func UpdateVenue(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// 1) Uwierzytelnienie
+		claims, ok := auth.FromContext(r.Context())
+		if !ok || claims["role"] != "organizer" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		// 2) Dekodowanie requestu
+		req, ok := decodeVenue(w, r)
+		if !ok {
+			return
+		}
+		accessibility, _ := json.Marshal(req.Accessibility)
+
+		// 3) Sprawdź właściciela
+		var owner int
+		if err := db.QueryRow("SELECT organizer_id FROM venues WHERE id=$1", id).Scan(&owner); err != nil ||
+			owner != int(claims["id"].(float64)) {
+			http.Error(w, "Forbidden or not found", http.StatusForbidden)
+			return
+		}
+
+		// 4) Nowa pojemność musi pomieścić zaplanowane wydarzenia
+		if req.Capacity > 0 {
+			var largest sql.NullInt64
+			if err := db.QueryRow(
+				`SELECT MAX(capacity) FROM events
+				 WHERE venue_id=$1 AND deleted_at IS NULL AND status NOT IN ($2, $3)`,
+				id, models.EventCancelled, models.EventCompleted,
+			).Scan(&largest); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if largest.Valid && int(largest.Int64) > req.Capacity {
+				http.Error(w, "Venue capacity is lower than capacity of its events", http.StatusConflict)
+				return
+			}
+		}
+
+		// 5) Wykonaj UPDATE
+		if _, err := db.Exec(
+			`UPDATE venues SET name=$1, address=$2, latitude=$3, longitude=$4, capacity=$5, accessibility=$6
+			 WHERE id=$7`,
+			req.Name, req.Address, *req.Latitude, *req.Longitude, req.Capacity, string(accessibility), id,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// nearQuery to parametry wyszukiwania w pobliżu: ?near=lat,lon&radius_km=.
+type nearQuery struct {
+	Lat, Lon, RadiusKm float64
+	box                geo.Box
+}
+
+// parseNear czyta ?near= i ?radius_km=. Zwraca nil, jeśli nie podano near;
+// przy błędnych parametrach sam wysyła 400 i zwraca false.
+func parseNear(w http.ResponseWriter, r *http.Request) (*nearQuery, bool) {
+	q := r.URL.Query()
+	if q.Get("near") == "" {
+		return nil, true
+	}
+	lat, lon, err := geo.ParsePoint(q.Get("near"))
+	if err != nil {
+		http.Error(w, "near: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	radius := float64(defaultNearRadiusKm)
+	if s := q.Get("radius_km"); s != "" {
+		radius, err = strconv.ParseFloat(s, 64)
+		if err != nil || !(radius > 0 && radius <= maxNearRadiusKm) {
+			http.Error(w, "radius_km must be a number in (0, "+strconv.Itoa(maxNearRadiusKm)+"]", http.StatusBadRequest)
+			return nil, false
+		}
+	}
+	return &nearQuery{Lat: lat, Lon: lon, RadiusKm: radius, box: geo.BoundingBox(lat, lon, radius)}, true
+}
+
+// boxFilter zwraca warunek SQL na venues (latitude/longitude) dla prostokąta
+// n.box, z parametrami numerowanymi od len(args)+1. Zwykłe porównania
+// działają na każdej bazie i korzystają z indeksu venues_location_idx.
+func (n *nearQuery) boxFilter(args []interface{}) (string, []interface{}) {
+	b := n.box
+	args = append(args, b.MinLat, b.MaxLat, b.MinLon, b.MaxLon)
+	p := func(i int) string { return "$" + strconv.Itoa(len(args)-4+i) }
+	cond := "latitude BETWEEN " + p(1) + " AND " + p(2)
+	if b.WrapsAntimeridian() {
+		return cond + " AND (longitude >= " + p(3) + " OR longitude <= " + p(4) + ")", args
+	}
+	return cond + " AND longitude BETWEEN " + p(3) + " AND " + p(4), args
+}
+
+// venuesInBox zwraca miejsca z prostokąta n.box (po ID).
+func venuesInBox(db *sql.DB, n *nearQuery) (map[int]models.Venue, error) {
+	cond, args := n.boxFilter(nil)
+	rows, err := db.Query("SELECT "+venueColumns+" FROM venues WHERE "+cond, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	venues := map[int]models.Venue{}
+	for rows.Next() {
+		var v models.Venue
+		if err := scanVenue(rows, &v); err != nil {
+			return nil, err
+		}
+		venues[v.ID] = v
+	}
+	return venues, rows.Err()
+}
+
+// filterNear zostawia wydarzenia, których miejsce leży w promieniu wyszukiwania
+// (dokładnie, wzorem haversine), uzupełnia odległość i sortuje od najbliższych.
+// Przy równej odległości zostaje dotychczasowa kolejność (po dacie).
+func filterNear(events []models.Event, venues map[int]models.Venue, n *nearQuery) []models.Event {
+	out := events[:0]
+	for _, e := range events {
+		if e.VenueID == nil {
+			continue
+		}
+		v, ok := venues[*e.VenueID]
+		if !ok {
+			continue
+		}
+		d := geo.Distance(n.Lat, n.Lon, v.Latitude, v.Longitude)
+		if d > n.RadiusKm {
+			continue
+		}
+		d = math.Round(d*1000) / 1000
+		e.DistanceKm = &d
+		out = append(out, e)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return *out[i].DistanceKm < *out[j].DistanceKm })
+	return out
+}
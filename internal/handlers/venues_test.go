@@ -0,0 +1,126 @@
+// File: internal/handlers/venues_test.go
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/bartbaranski/eventhub/internal/models"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func createVenue(t *testing.T, h http.HandlerFunc, ctx context.Context, name string, lat, lon float64, capacity int) int {
+	t.Helper()
+	body := fmt.Sprintf(
+		`{"name":%q,"address":"ul. Testowa 1","latitude":%v,"longitude":%v,"capacity":%d,"accessibility":{"wheelchair_accessible":true}}`,
+		name, lat, lon, capacity,
+	)
+	w := call(h, "POST", "/venues", ctx, 0, body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create venue %s: expected 201, got %d (%s)", name, w.Code, w.Body.String())
+	}
+	var resp struct {
+		ID int `json:"id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.ID
+}
+
+func TestVenues_EventCapacityAndDetails(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	venue := createVenue(t, handlers.CreateVenue(db), owner, "Hala", 0, 0, 50)
+
+	// Wydarzenie większe niż miejsce jest odrzucane
+	body := `{"title":"Koncert","date_time":%q,"capacity":%d,"venue_id":%d}`
+	w := call(handlers.CreateEvent(db), "POST", "/events", owner, 0, fmt.Sprintf(body, futureDateTime(), 80, venue))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"capacity"`) {
+		t.Fatalf("over capacity: expected 400 for capacity, got %d (%s)", w.Code, w.Body.String())
+	}
+	w = call(handlers.CreateEvent(db), "POST", "/events", owner, 0, fmt.Sprintf(body, futureDateTime(), 10, 999))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"venue_id"`) {
+		t.Fatalf("unknown venue: expected 400 for venue_id, got %d (%s)", w.Code, w.Body.String())
+	}
+	w = call(handlers.CreateEvent(db), "POST", "/events", owner, 0, fmt.Sprintf(body, futureDateTime(), 40, venue))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create event: expected 201, got %d (%s)", w.Code, w.Body.String())
+	}
+	var created struct {
+		ID int `json:"id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+
+	// Szczegóły wydarzenia zawierają miejsce
+	e := getEvent(t, db, owner, created.ID)
+	if e.Venue == nil || e.Venue.Name != "Hala" || !e.Venue.Accessibility.WheelchairAccessible {
+		t.Fatalf("expected venue in event details, got %+v", e.Venue)
+	}
+
+	// Miejsca nie można zmniejszyć poniżej pojemności wydarzenia
+	update := `{"name":"Hala","address":"ul. Testowa 1","latitude":0,"longitude":0,"capacity":%d}`
+	if w := call(handlers.UpdateVenue(db), "PUT", "/venues/x", owner, venue, fmt.Sprintf(update, 30)); w.Code != http.StatusConflict {
+		t.Fatalf("shrink venue: expected 409, got %d (%s)", w.Code, w.Body.String())
+	}
+	other := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(2), "role": "organizer"})
+	if w := call(handlers.UpdateVenue(db), "PUT", "/venues/x", other, venue, fmt.Sprintf(update, 100)); w.Code != http.StatusForbidden {
+		t.Fatalf("foreign venue: expected 403, got %d", w.Code)
+	}
+}
+
+func TestListEvents_Near(t *testing.T) {
+	db := newEventDB(t)
+	owner := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(1), "role": "organizer"})
+	create := handlers.CreateVenue(db)
+	venues := map[string]int{
+		"Warszawa":  createVenue(t, create, owner, "Warszawa", 52.2297, 21.0122, 0),
+		"Piaseczno": createVenue(t, create, owner, "Piaseczno", 52.0811, 21.0238, 0),
+		"Kraków":    createVenue(t, create, owner, "Kraków", 50.0647, 19.9450, 0),
+	}
+	for name, venue := range venues {
+		w := call(handlers.CreateEvent(db), "POST", "/events", owner, 0, fmt.Sprintf(
+			`{"title":%q,"date_time":%q,"capacity":10,"status":"published","venue_id":%d}`, name, futureDateTime(), venue,
+		))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create event: expected 201, got %d (%s)", w.Code, w.Body.String())
+		}
+	}
+	insertEvent(t, db, "published", 10) // bez miejsca – nigdy nie jest "w pobliżu"
+
+	search := func(query string) (int, []models.Event) {
+		w := httptest.NewRecorder()
+		handlers.ListEvents(db)(w, httptest.NewRequest("GET", "/events?"+query, nil))
+		var events []models.Event
+		json.Unmarshal(w.Body.Bytes(), &events)
+		return w.Code, events
+	}
+
+	// Punkt w Piasecznie: najbliżej Piaseczno, potem Warszawa (~17 km); Kraków poza promieniem
+	code, events := search("near=52.08,21.02&radius_km=50")
+	if code != http.StatusOK || len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d: %+v", code, events)
+	}
+	if events[0].Title != "Piaseczno" || events[1].Title != "Warszawa" {
+		t.Fatalf("unexpected order: %s, %s", events[0].Title, events[1].Title)
+	}
+	if d := *events[1].DistanceKm; d < 15 || d > 19 {
+		t.Fatalf("unexpected distance to Warszawa: %v", d)
+	}
+
+	// Domyślny promień (10 km) obejmuje tylko Piaseczno
+	if _, events := search("near=52.08,21.02"); len(events) != 1 || events[0].Title != "Piaseczno" {
+		t.Fatalf("default radius: unexpected %+v", events)
+	}
+
+	for _, bad := range []string{"near=52.08", "near=95,21", "near=52,21&radius_km=0", "near=52,21&radius_km=abc"} {
+		if code, _ := search(bad); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", bad, code)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// File: internal/handlers/verification.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/mail"
+	"github.com/gorilla/mux"
+)
+
+// verificationTTL to czas ważności linku potwierdzającego adres e-mail.
+const verificationTTL = 24 * time.Hour
+
+// RequestEmailVerification wysyła zalogowanemu użytkownikowi jednorazowy
+// link potwierdzający adres e-mail (wymagany przez wydarzenia z
+// require_verified_email), ważny verificationTTL. Link prowadzi do frontendu
+// pod siteURL (pusty – adres, pod którym przyszło żądanie). Bez skonfigurowanej
+// wysyłki (mailer nil) zwraca 503.
+func RequestEmailVerification(db *sql.DB, mailer mail.Mailer, siteURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if mailer == nil {
+			http.Error(w, "Email delivery is not configured", http.StatusServiceUnavailable)
+			return
+		}
+
+		claims, ok := auth.FromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		userID := int(claims["id"].(float64))
+
+		var email string
+		var verifiedAt sql.NullTime
+		if err := db.QueryRow(
+			"SELECT email, email_verified_at FROM users WHERE id=$1", userID,
+		).Scan(&email, &verifiedAt); err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if verifiedAt.Valid {
+			http.Error(w, "Email address is already verified", http.StatusConflict)
+			return
+		}
+
+		// Nowy token unieważnia poprzedni link
+		token, err := newSecretToken()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		expiresAt := time.Now().UTC().Add(verificationTTL)
+		if _, err := db.Exec(
+			"UPDATE users SET email_verification_token=$1, email_verification_expires_at=$2 WHERE id=$3",
+			token, expiresAt, userID,
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		site := strings.TrimSuffix(siteURL, "/")
+		if site == "" {
+			site = requestOrigin(r)
+		}
+		if err := mailer.Send(r.Context(), mail.Message{
+			To:      email,
+			Subject: "Confirm your EventHub email address",
+			Body: "Open this link to confirm your email address:\n\n" +
+				site + "/verify-email/" + token + "\n\n" +
+				"The link expires in " + strconv.Itoa(int(verificationTTL.Hours())) + " hours. If you did not ask for it, ignore this message.\n",
+		}); err != nil {
+			log.Printf("send verification email to user %d: %v", userID, err)
+			http.Error(w, "Unable to send verification email", http.StatusBadGateway)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{"status": "sent"})
+	}
+}
+
+// VerifyEmail potwierdza adres e-mail tokenem z linku (bez logowania).
+// Przeterminowany link daje 410 – trzeba poprosić o nowy.
+func VerifyEmail(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := mux.Vars(r)["token"]
+		var expiresAt sql.NullTime
+		err := db.QueryRow(
+			"SELECT email_verification_expires_at FROM users WHERE email_verification_token=$1", token,
+		).Scan(&expiresAt)
+		if err == sql.ErrNoRows {
+			http.Error(w, "Verification link is invalid or already used", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		now := time.Now().UTC()
+		if !expiresAt.Valid || !expiresAt.Time.After(now) {
+			http.Error(w, "Verification link has expired", http.StatusGone)
+			return
+		}
+
+		res, err := db.Exec(
+			`UPDATE users SET email_verified_at=$1, email_verification_token=NULL, email_verification_expires_at=NULL
+			 WHERE email_verification_token=$2`,
+			now, token,
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			http.Error(w, "Verification link is invalid or already used", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "verified"})
+	}
+}
@@ -0,0 +1,70 @@
+// File: internal/handlers/verification_test.go
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/handlers"
+	"github.com/bartbaranski/eventhub/internal/mail"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// outbox to mailer zapamiętujący wysłane wiadomości.
+type outbox []mail.Message
+
+func (o *outbox) Send(_ context.Context, m mail.Message) error {
+	*o = append(*o, m)
+	return nil
+}
+
+func TestEmailVerification(t *testing.T) {
+	db, _ := seedTransfer(t)
+	ola := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(5), "role": "participant"})
+
+	// Bez skonfigurowanej wysyłki linku nie da się dostarczyć
+	if w := call(handlers.RequestEmailVerification(db, nil, ""), "POST", "/auth/verify-email", ola, 0, ""); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("no mailer: expected 503, got %d", w.Code)
+	}
+
+	sent := &outbox{}
+	request := handlers.RequestEmailVerification(db, sent, "https://eventhub.example.com/")
+	if w := call(request, "POST", "/auth/verify-email", ola, 0, ""); w.Code != http.StatusAccepted {
+		t.Fatalf("request: expected 202, got %d", w.Code)
+	}
+	var token string
+	db.QueryRow("SELECT email_verification_token FROM users WHERE id=5").Scan(&token)
+	if token == "" {
+		t.Fatal("expected a stored verification token")
+	}
+	if len(*sent) != 1 || (*sent)[0].To != "ola@example.com" ||
+		!strings.Contains((*sent)[0].Body, "https://eventhub.example.com/verify-email/"+token) {
+		t.Fatalf("expected a verification email with the link, got %+v", *sent)
+	}
+
+	verify := handlers.VerifyEmail(db)
+	if w := callToken(verify, "POST", context.Background(), "abc123"); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown token: expected 404, got %d", w.Code)
+	}
+
+	// Przeterminowany link nie działa
+	db.Exec("UPDATE users SET email_verification_expires_at=$1 WHERE id=5", time.Now().UTC().Add(-time.Minute))
+	if w := callToken(verify, "POST", context.Background(), token); w.Code != http.StatusGone {
+		t.Fatalf("expired token: expected 410, got %d", w.Code)
+	}
+	db.Exec("UPDATE users SET email_verification_expires_at=$1 WHERE id=5", time.Now().UTC().Add(time.Hour))
+
+	if w := callToken(verify, "POST", context.Background(), token); w.Code != http.StatusOK {
+		t.Fatalf("verify: expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	if w := callToken(verify, "POST", context.Background(), token); w.Code != http.StatusNotFound {
+		t.Fatalf("reused token: expected 404, got %d", w.Code)
+	}
+	if w := call(request, "POST", "/auth/verify-email", ola, 0, ""); w.Code != http.StatusConflict {
+		t.Fatalf("already verified: expected 409, got %d", w.Code)
+	}
+}
@@ -0,0 +1,271 @@
+// File: internal/ical/ical.go
+//
+// Package ical zapisuje kalendarze w formacie iCalendar (RFC 5545) – do
+// importu pojedynczych wydarzeń i subskrypcji w Google/Outlook/Apple Calendar.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Statusy VEVENT.
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusTentative = "TENTATIVE"
+	StatusCancelled = "CANCELLED"
+)
+
+// Calendar to obiekt VCALENDAR.
+type Calendar struct {
+	ProdID string // np. "-//EventHub//EventHub//PL"
+	Name   string // X-WR-CALNAME – nazwa subskrypcji w kliencie
+	Events []Event
+}
+
+// Event to jeden VEVENT.
+type Event struct {
+	// UID musi być stały dla wydarzenia – klient po nim rozpoznaje aktualizacje,
+	// a po SEQUENCE, która z wersji jest nowsza.
+	UID      string
+	Sequence int
+	Stamp    time.Time // DTSTAMP
+	Modified time.Time // LAST-MODIFIED (opcjonalne)
+	// Start i End (opcjonalny) to chwile; Location to strefa, w której są
+	// zapisywane (TZID z VTIMEZONE); nil lub UTC – zapis w UTC.
+	Start       time.Time
+	End         *time.Time
+	Location    *time.Location
+	Summary     string
+	Description string
+	Place       string // LOCATION
+	Geo         *[2]float64
+	Categories  []string
+	Status      string
+}
+
+// Bytes zwraca kalendarz w formacie iCalendar (linie CRLF, zawijane co 75 bajtów).
+func (c *Calendar) Bytes() []byte {
+	var w writer
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:" + c.ProdID)
+	w.line("CALSCALE:GREGORIAN")
+	w.line("METHOD:PUBLISH")
+	if c.Name != "" {
+		w.line("X-WR-CALNAME:" + Escape(c.Name))
+	}
+	for _, tz := range c.timezones() {
+		w.vtimezone(tz.loc, tz.from, tz.to)
+	}
+	for _, e := range c.Events {
+		w.vevent(e)
+	}
+	w.line("END:VCALENDAR")
+	return w.buf.Bytes()
+}
+
+type tzRange struct {
+	loc      *time.Location
+	from, to time.Time
+}
+
+// timezones zbiera strefy wydarzeń (bez UTC) z zakresem dat, dla którego
+// trzeba opisać ich przejścia – od początku roku pierwszego wydarzenia do
+// końca roku ostatniego (stały zakres daje stabilny wynik dla całego roku).
+func (c *Calendar) timezones() []tzRange {
+	byName := map[string]*tzRange{}
+	for _, e := range c.Events {
+		if isUTC(e.Location) {
+			continue
+		}
+		end := e.Start
+		if e.End != nil {
+			end = *e.End
+		}
+		r, ok := byName[e.Location.String()]
+		if !ok {
+			r = &tzRange{loc: e.Location, from: e.Start, to: end}
+			byName[e.Location.String()] = r
+		}
+		if e.Start.Before(r.from) {
+			r.from = e.Start
+		}
+		if end.After(r.to) {
+			r.to = end
+		}
+	}
+	out := make([]tzRange, 0, len(byName))
+	for _, r := range byName {
+		from := r.from.In(r.loc)
+		to := r.to.In(r.loc)
+		r.from = time.Date(from.Year(), 1, 1, 0, 0, 0, 0, r.loc)
+		r.to = time.Date(to.Year()+1, 1, 1, 0, 0, 0, 0, r.loc)
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].loc.String() < out[j].loc.String() })
+	return out
+}
+
+func isUTC(loc *time.Location) bool {
+	return loc == nil || loc == time.UTC || loc.String() == "UTC"
+}
+
+// Transition to zmiana przesunięcia strefy (np. przejście na czas letni).
+type Transition struct {
+	At       time.Time // chwila zmiany
+	From, To int       // przesunięcie przed i po, w sekundach
+	Name     string    // skrót strefy po zmianie, np. "CEST"
+	DST      bool
+}
+
+// Transitions zwraca zmiany przesunięcia strefy loc w przedziale [from, to).
+// Go nie udostępnia reguł strefy, więc szukamy zmian krokami co dobę,
+// a dokładną chwilę – bisekcją.
+func Transitions(loc *time.Location, from, to time.Time) []Transition {
+	var out []Transition
+	_, prev := from.In(loc).Zone()
+	for t := from; t.Before(to); {
+		next := t.Add(24 * time.Hour)
+		if _, off := next.In(loc).Zone(); off != prev {
+			lo, hi := t, next
+			for hi.Sub(lo) > time.Second {
+				mid := lo.Add(hi.Sub(lo) / 2)
+				if _, o := mid.In(loc).Zone(); o == prev {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			at := hi.Truncate(time.Second)
+			name, off := at.In(loc).Zone()
+			out = append(out, Transition{At: at, From: prev, To: off, Name: name, DST: at.In(loc).IsDST()})
+			prev = off
+		}
+		t = next
+	}
+	return out
+}
+
+// vtimezone opisuje strefę w przedziale [from, to): początkowy stan oraz
+// każde przejście jako osobny komponent STANDARD/DAYLIGHT z DTSTART
+// w czasie lokalnym sprzed zmiany (RFC 5545, 3.6.5).
+func (w *writer) vtimezone(loc *time.Location, from, to time.Time) {
+	w.line("BEGIN:VTIMEZONE")
+	w.line("TZID:" + loc.String())
+
+	name, off := from.In(loc).Zone()
+	w.observance(from.In(loc).IsDST(), from.Add(time.Duration(off)*time.Second), off, off, name)
+	for _, t := range Transitions(loc, from, to) {
+		w.observance(t.DST, t.At.Add(time.Duration(t.From)*time.Second), t.From, t.To, t.Name)
+	}
+	w.line("END:VTIMEZONE")
+}
+
+func (w *writer) observance(dst bool, localStart time.Time, from, to int, name string) {
+	kind := "STANDARD"
+	if dst {
+		kind = "DAYLIGHT"
+	}
+	w.line("BEGIN:" + kind)
+	w.line("DTSTART:" + localStart.UTC().Format("20060102T150405"))
+	w.line("TZOFFSETFROM:" + formatOffset(from))
+	w.line("TZOFFSETTO:" + formatOffset(to))
+	if name != "" {
+		w.line("TZNAME:" + Escape(name))
+	}
+	w.line("END:" + kind)
+}
+
+func (w *writer) vevent(e Event) {
+	w.line("BEGIN:VEVENT")
+	w.line("UID:" + e.UID)
+	w.line(fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+	w.line("DTSTAMP:" + formatUTC(e.Stamp))
+	if !e.Modified.IsZero() {
+		w.line("LAST-MODIFIED:" + formatUTC(e.Modified))
+	}
+	w.line("DTSTART" + formatDateTime(e.Start, e.Location))
+	if e.End != nil {
+		w.line("DTEND" + formatDateTime(*e.End, e.Location))
+	}
+	w.line("SUMMARY:" + Escape(e.Summary))
+	if e.Description != "" {
+		w.line("DESCRIPTION:" + Escape(e.Description))
+	}
+	if e.Place != "" {
+		w.line("LOCATION:" + Escape(e.Place))
+	}
+	if e.Geo != nil {
+		w.line(fmt.Sprintf("GEO:%.6f;%.6f", e.Geo[0], e.Geo[1]))
+	}
+	if len(e.Categories) > 0 {
+		escaped := make([]string, len(e.Categories))
+		for i, c := range e.Categories {
+			escaped[i] = Escape(c)
+		}
+		w.line("CATEGORIES:" + strings.Join(escaped, ","))
+	}
+	if e.Status != "" {
+		w.line("STATUS:" + e.Status)
+	}
+	w.line("END:VEVENT")
+}
+
+// formatDateTime zwraca parametry i wartość DTSTART/DTEND: w UTC
+// (":20300618T160000Z") albo w strefie (";TZID=Europe/Warsaw:20300618T180000").
+func formatDateTime(t time.Time, loc *time.Location) string {
+	if isUTC(loc) {
+		return ":" + formatUTC(t)
+	}
+	return ";TZID=" + loc.String() + ":" + t.In(loc).Format("20060102T150405")
+}
+
+func formatUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func formatOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+	s := fmt.Sprintf("%c%02d%02d", sign, seconds/3600, seconds/60%60)
+	if seconds%60 != 0 {
+		s += fmt.Sprintf("%02d", seconds%60)
+	}
+	return s
+}
+
+// Escape zamienia tekst na wartość typu TEXT (RFC 5545, 3.3.11).
+func Escape(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// writer zapisuje linie treści, zawijając je co najwyżej co 75 bajtów
+// (bez rozcinania znaków UTF-8); kontynuacja zaczyna się od spacji.
+type writer struct {
+	buf bytes.Buffer
+}
+
+func (w *writer) line(s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.buf.WriteString(s[:cut])
+		w.buf.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // spacja na początku kontynuacji też się liczy
+	}
+	w.buf.WriteString(s)
+	w.buf.WriteString("\r\n")
+}
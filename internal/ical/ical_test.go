@@ -0,0 +1,104 @@
+// File: internal/ical/ical_test.go
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransitions_Warsaw(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Warsaw")
+	got := Transitions(loc, time.Date(2030, 1, 1, 0, 0, 0, 0, loc), time.Date(2031, 1, 1, 0, 0, 0, 0, loc))
+	if len(got) != 2 {
+		t.Fatalf("expected 2 transitions, got %+v", got)
+	}
+	spring, autumn := got[0], got[1]
+	if !spring.At.Equal(time.Date(2030, 3, 31, 1, 0, 0, 0, time.UTC)) || spring.From != 3600 || spring.To != 7200 || !spring.DST || spring.Name != "CEST" {
+		t.Fatalf("unexpected spring transition %+v", spring)
+	}
+	if !autumn.At.Equal(time.Date(2030, 10, 27, 1, 0, 0, 0, time.UTC)) || autumn.To != 3600 || autumn.DST {
+		t.Fatalf("unexpected autumn transition %+v", autumn)
+	}
+}
+
+func TestCalendar_Bytes(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Warsaw")
+	start := time.Date(2030, 6, 18, 16, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	cal := Calendar{
+		ProdID: "-//EventHub//EventHub//PL",
+		Name:   "Moje wydarzenia",
+		Events: []Event{{
+			UID:         "event-5@eventhub",
+			Sequence:    2,
+			Stamp:       time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+			Start:       start,
+			End:         &end,
+			Location:    loc,
+			Summary:     "Koncert; jazz, na żywo",
+			Description: "Linia 1\nLinia 2",
+			Geo:         &[2]float64{52.2297, 21.0122},
+			Categories:  []string{"concert", "na-żywo"},
+			Status:      StatusCancelled,
+		}, {
+			UID:     "event-6@eventhub",
+			Stamp:   time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+			Start:   start,
+			Summary: "W UTC",
+		}},
+	}
+	out := string(cal.Bytes())
+
+	if !strings.HasSuffix(out, "END:VCALENDAR\r\n") || strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Fatalf("lines must end with CRLF:\n%s", out)
+	}
+	for _, want := range []string{
+		"TZID:Europe/Warsaw\r\n",
+		// stan początkowy i przejścia roku 2030, DTSTART w czasie sprzed zmiany
+		"BEGIN:STANDARD\r\nDTSTART:20300101T000000\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0100\r\nTZNAME:CET\r\n",
+		"BEGIN:DAYLIGHT\r\nDTSTART:20300331T020000\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\nTZNAME:CEST\r\n",
+		"BEGIN:STANDARD\r\nDTSTART:20301027T030000\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\n",
+		"UID:event-5@eventhub\r\nSEQUENCE:2\r\nDTSTAMP:20300102T030405Z\r\n",
+		"DTSTART;TZID=Europe/Warsaw:20300618T180000\r\nDTEND;TZID=Europe/Warsaw:20300618T200000\r\n",
+		`SUMMARY:Koncert\; jazz\, na żywo` + "\r\n",
+		`DESCRIPTION:Linia 1\nLinia 2` + "\r\n",
+		"GEO:52.229700;21.012200\r\n",
+		"CATEGORIES:concert,na-żywo\r\n",
+		"STATUS:CANCELLED\r\n",
+		"DTSTART:20300618T160000Z\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "BEGIN:VTIMEZONE") != 1 {
+		t.Fatalf("expected exactly one VTIMEZONE (UTC needs none):\n%s", out)
+	}
+}
+
+func TestWriter_Folding(t *testing.T) {
+	var w writer
+	long := "DESCRIPTION:" + strings.Repeat("zażółć gęślą jaźń ", 10)
+	w.line(long)
+	lines := strings.Split(strings.TrimSuffix(w.buf.String(), "\r\n"), "\r\n")
+	if len(lines) < 3 {
+		t.Fatalf("expected folded lines, got %q", lines)
+	}
+	var joined strings.Builder
+	for i, l := range lines {
+		if len(l) > 75 {
+			t.Errorf("line %d has %d octets", i, len(l))
+		}
+		if i > 0 {
+			if l[0] != ' ' {
+				t.Fatalf("continuation must start with space: %q", l)
+			}
+			l = l[1:]
+		}
+		joined.WriteString(l)
+	}
+	if joined.String() != long {
+		t.Fatalf("unfolded text differs:\n%q\n%q", joined.String(), long)
+	}
+}
@@ -0,0 +1,252 @@
+// File: internal/idempotency/idempotency.go
+//
+// Package idempotency obsługuje nagłówek Idempotency-Key w żądaniach POST:
+// pierwsza odpowiedź dla klucza jest zapisywana w bazie, a ponowienie z tym
+// samym kluczem (np. po timeoucie w aplikacji mobilnej) dostaje ją ponownie
+// zamiast wykonać operację drugi raz.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/ratelimit"
+)
+
+const (
+	// Header to nagłówek z kluczem wybranym przez klienta (np. UUID).
+	Header = "Idempotency-Key"
+	// ReplayedHeader oznacza odpowiedź odtworzoną z zapisu.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLen = 255
+	// maxBodyBytes ogranicza buforowane body (największe jest zdjęcie wydarzenia).
+	maxBodyBytes = 16 << 20
+	// staleAfter to czas, po którym niedokończone żądanie (np. po awarii
+	// serwera) przestaje blokować klucz.
+	staleAfter = time.Minute
+)
+
+// replayHeaders to nagłówki odpowiedzi zapisywane razem z body.
+var replayHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// Config określa, jak długo klucze są pamiętane; TTL <= 0 wyłącza obsługę.
+type Config struct {
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// Keys przechowuje klucze i odpowiedzi w tabeli idempotency_keys.
+type Keys struct {
+	db  *sql.DB
+	ttl time.Duration
+	ips *ratelimit.IPResolver
+}
+
+// New tworzy magazyn kluczy w bazie db; ips ustala adres klienta dla
+// kluczy żądań anonimowych.
+func New(db *sql.DB, cfg Config, ips *ratelimit.IPResolver) *Keys {
+	return &Keys{db: db, ttl: cfg.TTL, ips: ips}
+}
+
+// Middleware obsługuje Idempotency-Key w żądaniach POST. Klucz należy do
+// użytkownika z auth.FromContext (middleware działa wtedy wewnątrz
+// auth.JWTMiddleware), a w żądaniach anonimowych – do adresu IP klienta.
+// Żądania bez klucza przechodzą bez zmian. Ten sam klucz z innym żądaniem
+// (metoda, ścieżka lub body) daje 422, a klucz, którego żądanie wciąż
+// trwa – 409.
+func (k *Keys) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	if k.ttl <= 0 {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(Header)
+		if r.Method != http.MethodPost || key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxKeyLen {
+			http.Error(w, Header+" must be at most "+strconv.Itoa(maxKeyLen)+" characters", http.StatusBadRequest)
+			return
+		}
+
+		// 1) Odcisk żądania – body trzeba przeczytać i oddać handlerowi
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+		if err != nil {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fp := fingerprint(r.Method, r.URL.RequestURI(), body)
+		scope := k.scope(r)
+
+		// 2) Zajęcie klucza albo odpowiedź zapisana przy pierwszym żądaniu
+		claimed, err := k.claim(r.Context(), scope, key, fp)
+		if err != nil {
+			// Awaria magazynu nie może blokować zapisów.
+			log.Printf("idempotency: %v", err)
+			next(w, r)
+			return
+		}
+		if !claimed {
+			k.replay(w, r.Context(), scope, key, fp)
+			return
+		}
+
+		// 3) Wykonanie i zapis odpowiedzi
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+		if err := k.finish(r.Context(), scope, key, rec); err != nil {
+			log.Printf("idempotency: store response for key %q: %v", key, err)
+		}
+	}
+}
+
+// claim zapisuje klucz jako „w trakcie”. Zwraca false, gdy klucz jest już
+// zajęty; wcześniej zwalnia go, jeśli wygasł albo utknął w trakcie.
+func (k *Keys) claim(ctx context.Context, scope, key, fp string) (bool, error) {
+	now := time.Now().UTC()
+	if _, err := k.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys WHERE scope=$1 AND idem_key=$2
+		 AND (expires_at <= $3 OR (status=0 AND created_at <= $4))`,
+		scope, key, now, now.Add(-staleAfter),
+	); err != nil {
+		return false, err
+	}
+	res, err := k.db.ExecContext(ctx,
+		`INSERT INTO idempotency_keys(scope, idem_key, fingerprint, status, created_at, expires_at)
+		 VALUES($1, $2, $3, 0, $4, $5) ON CONFLICT (scope, idem_key) DO NOTHING`,
+		scope, key, fp, now, now.Add(k.ttl),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// replay odpowiada na ponowienie z zajętym kluczem.
+func (k *Keys) replay(w http.ResponseWriter, ctx context.Context, scope, key, fp string) {
+	var storedFP, headers string
+	var status int
+	var body []byte
+	if err := k.db.QueryRowContext(ctx,
+		"SELECT fingerprint, status, headers, body FROM idempotency_keys WHERE scope=$1 AND idem_key=$2",
+		scope, key,
+	).Scan(&storedFP, &status, &headers, &body); err != nil {
+		http.Error(w, "Request with this "+Header+" is in progress", http.StatusConflict)
+		return
+	}
+	if storedFP != fp {
+		http.Error(w, Header+" was already used with a different request", http.StatusUnprocessableEntity)
+		return
+	}
+	if status == 0 {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "Request with this "+Header+" is in progress", http.StatusConflict)
+		return
+	}
+
+	var saved map[string]string
+	json.Unmarshal([]byte(headers), &saved)
+	for name, value := range saved {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// finish zapisuje odpowiedź. Błędy serwera (5xx) nie są zapamiętywane –
+// klucz jest zwalniany, aby klient mógł ponowić żądanie.
+func (k *Keys) finish(ctx context.Context, scope, key string, rec *recorder) error {
+	if rec.status >= 500 {
+		_, err := k.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE scope=$1 AND idem_key=$2", scope, key)
+		return err
+	}
+	saved := map[string]string{}
+	for _, name := range replayHeaders {
+		if v := rec.Header().Get(name); v != "" {
+			saved[name] = v
+		}
+	}
+	headers, _ := json.Marshal(saved)
+	_, err := k.db.ExecContext(ctx,
+		"UPDATE idempotency_keys SET status=$1, headers=$2, body=$3 WHERE scope=$4 AND idem_key=$5",
+		rec.status, string(headers), rec.body.Bytes(), scope, key,
+	)
+	return err
+}
+
+// Purge usuwa wygasłe klucze i zwraca ich liczbę.
+func (k *Keys) Purge(ctx context.Context) (int64, error) {
+	res, err := k.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= $1", time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Run uruchamia Purge co interval, aż ctx zostanie anulowany.
+func (k *Keys) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := k.Purge(ctx); err != nil {
+			log.Printf("idempotency purge: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scope zwraca właściciela kluczy żądania: "user:<id>" dla zalogowanego
+// użytkownika, a dla żądań anonimowych "ip:<adres klienta>".
+func (k *Keys) scope(r *http.Request) string {
+	if claims, ok := auth.FromContext(r.Context()); ok {
+		if id, ok := claims["id"].(float64); ok {
+			return "user:" + strconv.Itoa(int(id))
+		}
+	}
+	return "ip:" + k.ips.ClientIP(r)
+}
+
+func fingerprint(method, uri string, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, method+" "+uri+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recorder przekazuje odpowiedź dalej i zachowuje jej kopię.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
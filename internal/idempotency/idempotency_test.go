@@ -0,0 +1,181 @@
+// File: internal/idempotency/idempotency_test.go
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/bartbaranski/eventhub/internal/storage"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func newTestKeys(t *testing.T) (*Keys, *sql.DB) {
+	t.Helper()
+	db := storage.NewTestDB()
+	if _, err := db.Exec(`CREATE TABLE idempotency_keys (
+      scope       TEXT     NOT NULL,
+      idem_key    TEXT     NOT NULL,
+      fingerprint TEXT     NOT NULL,
+      status      INTEGER  NOT NULL DEFAULT 0,
+      headers     TEXT     NOT NULL DEFAULT '{}',
+      body        BLOB,
+      created_at  DATETIME NOT NULL,
+      expires_at  DATETIME NOT NULL,
+      PRIMARY KEY (scope, idem_key)
+    );`); err != nil {
+		t.Fatal(err)
+	}
+	return New(db, Config{TTL: time.Hour}, nil), db
+}
+
+// counter to handler tworzący kolejne „rezerwacje”.
+type counter struct {
+	calls  int
+	status int
+}
+
+func (c *counter) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	c.calls++
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("/reservations/%d", c.calls))
+	w.WriteHeader(c.status)
+	fmt.Fprintf(w, `{"id":%d,"request":%s}`, c.calls, body)
+}
+
+// post wysyła żądanie jako użytkownik user (0 – anonimowo z adresu 192.0.2.1).
+func post(h http.HandlerFunc, user int, key, body string) *httptest.ResponseRecorder {
+	return postFrom(h, user, "192.0.2.1:1234", key, body)
+}
+
+func postFrom(h http.HandlerFunc, user int, remoteAddr, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/reservations", strings.NewReader(body))
+	req.RemoteAddr = remoteAddr
+	if user > 0 {
+		req = req.WithContext(auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(user)}))
+	}
+	if key != "" {
+		req.Header.Set(Header, key)
+	}
+	w := httptest.NewRecorder()
+	h(w, req)
+	return w
+}
+
+func TestMiddleware_Replay(t *testing.T) {
+	keys, _ := newTestKeys(t)
+	c := &counter{status: http.StatusCreated}
+	h := keys.Middleware(c.handle)
+
+	first := post(h, 7, "abc", `{"tickets":2}`)
+	again := post(h, 7, "abc", `{"tickets":2}`)
+	if c.calls != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", c.calls)
+	}
+	if again.Code != http.StatusCreated || again.Body.String() != first.Body.String() ||
+		again.Header().Get("Location") != "/reservations/1" || again.Header().Get(ReplayedHeader) != "true" {
+		t.Fatalf("replay: unexpected %d %v %s", again.Code, again.Header(), again.Body.String())
+	}
+	if first.Header().Get(ReplayedHeader) != "" {
+		t.Fatal("first response must not be marked as replayed")
+	}
+
+	// Ten sam klucz, inne body – 422; inny użytkownik ma własne klucze
+	if w := post(h, 7, "abc", `{"tickets":3}`); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("different body: expected 422, got %d", w.Code)
+	}
+	if w := post(h, 8, "abc", `{"tickets":2}`); w.Code != http.StatusCreated || c.calls != 2 {
+		t.Fatalf("other user: expected a new execution, got %d after %d calls", w.Code, c.calls)
+	}
+
+	// Bez klucza – zwykłe wykonanie
+	post(h, 7, "", `{"tickets":2}`)
+	if c.calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", c.calls)
+	}
+	if w := post(h, 7, strings.Repeat("k", maxKeyLen+1), `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("long key: expected 400, got %d", w.Code)
+	}
+}
+
+func TestMiddleware_AnonymousKeysAreScopedByIP(t *testing.T) {
+	keys, _ := newTestKeys(t)
+	c := &counter{status: http.StatusCreated}
+	h := keys.Middleware(c.handle)
+
+	first := postFrom(h, 0, "192.0.2.1:1234", "abc", `{"email":"ola@example.com"}`)
+	again := postFrom(h, 0, "192.0.2.1:5678", "abc", `{"email":"ola@example.com"}`)
+	if c.calls != 1 || again.Body.String() != first.Body.String() || again.Header().Get(ReplayedHeader) != "true" {
+		t.Fatalf("same client: expected a replay, got %d calls", c.calls)
+	}
+
+	// Ten sam klucz z innego adresu ani od zalogowanego użytkownika nie trafia w cudzy zapis
+	if w := postFrom(h, 0, "198.51.100.7:1234", "abc", `{"email":"ola@example.com"}`); w.Header().Get(ReplayedHeader) != "" || c.calls != 2 {
+		t.Fatalf("other client: expected a new execution, got %d calls", c.calls)
+	}
+	if w := postFrom(h, 7, "192.0.2.1:1234", "abc", `{"email":"ola@example.com"}`); w.Header().Get(ReplayedHeader) != "" || c.calls != 3 {
+		t.Fatalf("logged in user: expected a new execution, got %d calls", c.calls)
+	}
+}
+
+func TestMiddleware_ServerErrorsAreNotStored(t *testing.T) {
+	keys, _ := newTestKeys(t)
+	c := &counter{status: http.StatusInternalServerError}
+	h := keys.Middleware(c.handle)
+
+	post(h, 7, "abc", `{}`)
+	c.status = http.StatusCreated
+	if w := post(h, 7, "abc", `{}`); w.Code != http.StatusCreated || c.calls != 2 {
+		t.Fatalf("retry after 500: expected new execution, got %d after %d calls", w.Code, c.calls)
+	}
+}
+
+func TestMiddleware_InProgressAndExpiry(t *testing.T) {
+	keys, db := newTestKeys(t)
+	c := &counter{status: http.StatusCreated}
+	h := keys.Middleware(c.handle)
+	now := time.Now().UTC()
+	fp := fingerprint("POST", "/reservations", []byte(`{}`))
+
+	// Żądanie z tym kluczem wciąż trwa
+	db.Exec(`INSERT INTO idempotency_keys(scope, idem_key, fingerprint, status, created_at, expires_at)
+		VALUES('user:7', 'busy', $1, 0, $2, $3)`, fp, now, now.Add(time.Hour))
+	if w := post(h, 7, "busy", `{}`); w.Code != http.StatusConflict || c.calls != 0 {
+		t.Fatalf("in progress: expected 409, got %d", w.Code)
+	}
+
+	// Utknięte lub wygasłe klucze nie blokują nowego żądania
+	db.Exec(`INSERT INTO idempotency_keys(scope, idem_key, fingerprint, status, created_at, expires_at)
+		VALUES('user:7', 'stale', $1, 0, $2, $3)`, fp, now.Add(-2*staleAfter), now.Add(time.Hour))
+	db.Exec(`INSERT INTO idempotency_keys(scope, idem_key, fingerprint, status, created_at, expires_at)
+		VALUES('user:7', 'old', $1, 201, $2, $3)`, fp, now.Add(-2*time.Hour), now.Add(-time.Hour))
+	post(h, 7, "stale", `{}`)
+	post(h, 7, "old", `{}`)
+	if c.calls != 2 {
+		t.Fatalf("expected stale and expired keys to run again, got %d calls", c.calls)
+	}
+
+	db.Exec("UPDATE idempotency_keys SET expires_at=$1 WHERE idem_key='old'", now.Add(-time.Minute))
+	if n, err := keys.Purge(context.Background()); err != nil || n != 1 {
+		t.Fatalf("purge: expected 1 removed key, got %d (%v)", n, err)
+	}
+}
+
+func TestMiddleware_Disabled(t *testing.T) {
+	keys := New(nil, Config{}, nil)
+	c := &counter{status: http.StatusCreated}
+	h := keys.Middleware(c.handle)
+	post(h, 7, "abc", `{}`)
+	post(h, 7, "abc", `{}`)
+	if c.calls != 2 {
+		t.Fatalf("disabled: expected 2 calls, got %d", c.calls)
+	}
+}
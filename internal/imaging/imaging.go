@@ -0,0 +1,140 @@
+// File: internal/imaging/imaging.go
+//
+// Package imaging rozpoznaje, dekoduje i skaluje obrazy przesyłane przez
+// użytkowników – tylko biblioteką standardową (JPEG, PNG, GIF).
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"net/http"
+
+	_ "image/gif" // rejestracja dekoderów dla image.Decode
+	_ "image/png"
+)
+
+// Błędy Decode.
+var (
+	ErrUnsupported = errors.New("unsupported image type (use JPEG, PNG or GIF)")
+	ErrTooLarge    = errors.New("image dimensions are too large")
+	ErrInvalid     = errors.New("invalid image data")
+)
+
+// supportedTypes to typy MIME (rozpoznane z treści), które umiemy zdekodować.
+var supportedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// Sniff rozpoznaje typ MIME z pierwszych bajtów danych (nagłówek
+// Content-Type od klienta jest ignorowany – można go dowolnie podać).
+func Sniff(data []byte) string {
+	return http.DetectContentType(data)
+}
+
+// Decode rozpoznaje typ obrazu z treści i dekoduje go, odrzucając obrazy
+// mające więcej niż maxPixels pikseli (zanim zaalokujemy na nie pamięć).
+// Zwraca obraz jako RGBA spłaszczony na białe tło (JPEG nie ma przezroczystości).
+func Decode(data []byte, maxPixels int) (*image.RGBA, string, error) {
+	mime := Sniff(data)
+	if !supportedTypes[mime] {
+		return nil, mime, ErrUnsupported
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, mime, ErrInvalid
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, mime, ErrTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, mime, ErrInvalid
+	}
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst, mime, nil
+}
+
+// Fit zmniejsza obraz tak, żeby mieścił się w maxW×maxH z zachowaniem
+// proporcji. Mniejszych obrazów nie powiększa.
+func Fit(src *image.RGBA, maxW, maxH int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if sw <= maxW && sh <= maxH {
+		return src
+	}
+	w, h := maxW, sh*maxW/sw
+	if h > maxH {
+		w, h = sw*maxH/sh, maxH
+	}
+	return resize(src, src.Bounds(), max(w, 1), max(h, 1))
+}
+
+// Fill skaluje i przycina obraz (środek) do dokładnie w×h.
+func Fill(src *image.RGBA, w, h int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	crop := b
+	if sw*h > sh*w {
+		// za szeroki – obcinamy boki
+		cw := sh * w / h
+		crop.Min.X += (sw - cw) / 2
+		crop.Max.X = crop.Min.X + cw
+	} else {
+		// za wysoki – obcinamy górę i dół
+		ch := sw * h / w
+		crop.Min.Y += (sh - ch) / 2
+		crop.Max.Y = crop.Min.Y + ch
+	}
+	return resize(src, crop, w, h)
+}
+
+// resize skaluje fragment r obrazu src do w×h. Przy zmniejszaniu każdy piksel
+// wyniku jest średnią odpowiadającego mu prostokąta źródła (filtr pudełkowy),
+// przy powiększaniu – najbliższym pikselem.
+func resize(src *image.RGBA, r image.Rectangle, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	rw, rh := r.Dx(), r.Dy()
+	for y := 0; y < h; y++ {
+		y0 := r.Min.Y + y*rh/h
+		y1 := max(r.Min.Y+(y+1)*rh/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := r.Min.X + x*rw/w
+			x1 := max(r.Min.X+(x+1)*rw/w, x0+1)
+			var sr, sg, sb, sa, n int
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					p := src.Pix[i : i+4 : i+4]
+					sr += int(p[0])
+					sg += int(p[1])
+					sb += int(p[2])
+					sa += int(p[3])
+					n++
+					i += 4
+				}
+			}
+			j := dst.PixOffset(x, y)
+			dst.Pix[j] = uint8(sr / n)
+			dst.Pix[j+1] = uint8(sg / n)
+			dst.Pix[j+2] = uint8(sb / n)
+			dst.Pix[j+3] = uint8(sa / n)
+		}
+	}
+	return dst
+}
+
+// EncodeJPEG koduje obraz jako JPEG o danej jakości (1–100).
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
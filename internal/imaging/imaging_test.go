@@ -0,0 +1,84 @@
+// File: internal/imaging/imaging_test.go
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// testPNG zwraca PNG w×h: lewa połowa czerwona, prawa przezroczysta.
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w/2; x++ {
+			img.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	img, mime, err := Decode(testPNG(t, 40, 20), 1000)
+	if err != nil || mime != "image/png" {
+		t.Fatalf("decode: %v (%s)", err, mime)
+	}
+	// Przezroczystość jest spłaszczana na białe tło
+	if c := img.RGBAAt(30, 10); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Fatalf("expected white background, got %v", c)
+	}
+	if c := img.RGBAAt(5, 10); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Fatalf("expected red pixel, got %v", c)
+	}
+
+	if _, _, err := Decode(testPNG(t, 40, 30), 1000); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+	if _, mime, err := Decode([]byte("<html><body>not an image</body></html>"), 1000); !errors.Is(err, ErrUnsupported) || mime != "text/html; charset=utf-8" {
+		t.Fatalf("expected ErrUnsupported for HTML, got %v (%s)", err, mime)
+	}
+	// Poprawny nagłówek PNG, uszkodzona reszta
+	broken := testPNG(t, 10, 10)[:40]
+	if _, _, err := Decode(broken, 1000); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid, got %v", err)
+	}
+}
+
+func TestFitAndFill(t *testing.T) {
+	src, _, err := Decode(testPNG(t, 400, 200), 1_000_000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b := Fit(src, 100, 100).Bounds(); b.Dx() != 100 || b.Dy() != 50 {
+		t.Fatalf("fit: expected 100x50, got %v", b)
+	}
+	if got := Fit(src, 1000, 1000); got != src {
+		t.Fatalf("fit should not upscale")
+	}
+
+	thumb := Fill(src, 50, 50)
+	if b := thumb.Bounds(); b.Dx() != 50 || b.Dy() != 50 {
+		t.Fatalf("fill: expected 50x50, got %v", b)
+	}
+	// Środek 200x200 z 400x200: lewa połowa czerwona, prawa biała
+	if c := thumb.RGBAAt(10, 25); c.R != 255 || c.G != 0 {
+		t.Fatalf("expected red on the left, got %v", c)
+	}
+	if c := thumb.RGBAAt(40, 25); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Fatalf("expected white on the right, got %v", c)
+	}
+
+	data, err := EncodeJPEG(thumb, 85)
+	if err != nil || Sniff(data) != "image/jpeg" {
+		t.Fatalf("encode: %v (%s)", err, Sniff(data))
+	}
+}
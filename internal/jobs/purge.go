@@ -0,0 +1,129 @@
+// File: internal/jobs/purge.go
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/blobstore"
+)
+
+// purgeChildTables to tabele z kluczem obcym event_id, czyszczone przed
+// usunięciem wydarzenia (kolejność ma znaczenie dla FK).
+var purgeChildTables = []string{
+	"checkin_duplicates",
+	"checkins",
+	"reservation_transfers",
+	"reservations",
+	"event_revisions",
+	"event_tags",
+	"event_images",
+	"session_signups",
+	"event_sessions",
+	"event_tiers",
+}
+
+// PurgeDeletedEvents trwale usuwa wydarzenia miękko usunięte dawniej niż
+// retention, razem z powiązanymi rekordami, przesłanymi obrazami w store
+// i seriami, w których nie zostało już żadne wydarzenie. Zwraca liczbę
+// usuniętych wydarzeń.
+func PurgeDeletedEvents(ctx context.Context, db *sql.DB, store blobstore.Store, retention time.Duration) (int64, error) {
+	cutoff := time.Now().UTC().Add(-retention)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	expired := "SELECT id FROM events WHERE deleted_at IS NOT NULL AND deleted_at < $1"
+
+	// 1) Klucze obrazów – pliki usuwamy dopiero po zatwierdzeniu transakcji
+	keys, err := queryStrings(ctx, tx,
+		"SELECT storage_key FROM event_images WHERE event_id IN ("+expired+")", cutoff,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	// 2) Rekordy zależne i same wydarzenia
+	for _, table := range purgeChildTables {
+		if _, err := tx.ExecContext(ctx,
+			"DELETE FROM "+table+" WHERE event_id IN ("+expired+")", cutoff,
+		); err != nil {
+			return 0, err
+		}
+	}
+	series, err := queryStrings(ctx, tx,
+		"SELECT DISTINCT series_id FROM events WHERE series_id IS NOT NULL AND deleted_at IS NOT NULL AND deleted_at < $1", cutoff,
+	)
+	if err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx,
+		"DELETE FROM events WHERE deleted_at IS NOT NULL AND deleted_at < $1", cutoff,
+	)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+
+	// 3) Serie, z których usunięto ostatnie wydarzenie
+	for _, id := range series {
+		if _, err := tx.ExecContext(ctx,
+			"DELETE FROM event_series WHERE id=$1 AND NOT EXISTS (SELECT 1 FROM events WHERE series_id=$1)", id,
+		); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	// 4) Pliki obrazów; błąd zostawia tylko osierocony plik, więc nie przerywa
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			log.Printf("purge: delete image %s: %v", key, err)
+		}
+	}
+	return n, nil
+}
+
+// queryStrings zwraca pierwszą kolumnę wyników zapytania jako tekst.
+func queryStrings(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// RunPurge uruchamia PurgeDeletedEvents co interval, aż ctx zostanie anulowany.
+func RunPurge(ctx context.Context, db *sql.DB, store blobstore.Store, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := PurgeDeletedEvents(ctx, db, store, retention)
+		if err != nil {
+			log.Printf("purge: %v", err)
+		} else if n > 0 {
+			log.Printf("purge: permanently removed %d deleted events", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
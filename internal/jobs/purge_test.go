@@ -0,0 +1,83 @@
+// File: internal/jobs/purge_test.go
+package jobs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/blobstore"
+	"github.com/bartbaranski/eventhub/internal/storage"
+)
+
+func TestPurgeDeletedEvents(t *testing.T) {
+	db := storage.NewTestDB()
+	stmts := []string{
+		`CREATE TABLE event_series (id INTEGER PRIMARY KEY AUTOINCREMENT);`,
+		`CREATE TABLE events (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT, series_id INTEGER, deleted_at DATETIME);`,
+	}
+	for _, table := range purgeChildTables {
+		if table == "event_images" {
+			stmts = append(stmts, `CREATE TABLE event_images (event_id INTEGER NOT NULL, storage_key TEXT NOT NULL);`)
+			continue
+		}
+		stmts = append(stmts, `CREATE TABLE `+table+` (id INTEGER PRIMARY KEY AUTOINCREMENT, event_id INTEGER NOT NULL);`)
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now().UTC()
+	db.Exec("INSERT INTO event_series(id) VALUES(1), (2)")
+	db.Exec("INSERT INTO events(id, title, series_id, deleted_at) VALUES(1, 'expired', 1, $1)", now.Add(-40*24*time.Hour))
+	db.Exec("INSERT INTO events(id, title, series_id, deleted_at) VALUES(2, 'recent', 2, $1)", now.Add(-time.Hour))
+	db.Exec("INSERT INTO events(id, title, series_id, deleted_at) VALUES(4, 'expired too', 2, $1)", now.Add(-40*24*time.Hour))
+	db.Exec("INSERT INTO events(id, title) VALUES(3, 'live')")
+	db.Exec("INSERT INTO reservations(event_id) VALUES(1), (2), (3)")
+
+	// Obrazy wygasłego i żywego wydarzenia
+	dir := t.TempDir()
+	store, err := blobstore.NewLocal(blobstore.LocalConfig{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, key := range map[int]string{1: "events/1/a-card.jpg", 3: "events/3/b-card.jpg"} {
+		if err := store.Put(context.Background(), key, strings.NewReader("jpeg"), 4, "image/jpeg"); err != nil {
+			t.Fatal(err)
+		}
+		db.Exec("INSERT INTO event_images(event_id, storage_key) VALUES($1, $2)", id, key)
+	}
+
+	n, err := PurgeDeletedEvents(context.Background(), db, store, 30*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 purged events, got %d", n)
+	}
+
+	var events, reservations int
+	db.QueryRow("SELECT COUNT(*) FROM events").Scan(&events)
+	db.QueryRow("SELECT COUNT(*) FROM reservations WHERE event_id=1").Scan(&reservations)
+	if events != 2 || reservations != 0 {
+		t.Fatalf("expected 2 events left and no reservations of purged one, got %d/%d", events, reservations)
+	}
+
+	// Seria 1 jest pusta, seria 2 ma jeszcze wydarzenie w okresie retencji
+	var series int
+	db.QueryRow("SELECT COUNT(*) FROM event_series WHERE id=2").Scan(&series)
+	if db.QueryRow("SELECT id FROM event_series WHERE id=1").Scan(new(int)) == nil || series != 1 {
+		t.Fatalf("expected only the empty series to be removed")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "events/1/a-card.jpg")); !os.IsNotExist(err) {
+		t.Fatalf("expected purged event image to be deleted, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "events/3/b-card.jpg")); err != nil {
+		t.Fatalf("live event image must stay: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+// File: internal/mail/mail.go
+//
+// Package mail wysyła wiadomości e-mail do użytkowników (np. linki
+// potwierdzające adres) przez serwer SMTP. Sterownik "log" – tylko do pracy
+// lokalnej – zamiast wysyłać, wypisuje wiadomości do logu.
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+)
+
+// Message to wiadomość tekstowa do jednego odbiorcy.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer dostarcza wiadomości.
+type Mailer interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// Config wybiera i konfiguruje wysyłkę.
+type Config struct {
+	// Driver: "" (wysyłka wyłączona), "smtp" albo "log" (tylko do pracy
+	// lokalnej – treść wiadomości, także jednorazowe linki, trafia do logu).
+	Driver string     `yaml:"driver"`
+	From   string     `yaml:"from"`
+	SMTP   SMTPConfig `yaml:"smtp"`
+}
+
+// New tworzy mailer według konfiguracji; dla pustego sterownika zwraca nil.
+// Hasło SMTP można podać także w zmiennej SMTP_PASSWORD (zamiast w pliku).
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Driver {
+	case "":
+		return nil, nil
+	case "log":
+		log.Print("mail: log driver prints messages instead of sending them – do not use in production")
+		return logMailer{}, nil
+	case "smtp":
+		if cfg.SMTP.Password == "" {
+			cfg.SMTP.Password = os.Getenv("SMTP_PASSWORD")
+		}
+		return NewSMTP(cfg.From, cfg.SMTP)
+	default:
+		return nil, fmt.Errorf("mail: unknown driver %q", cfg.Driver)
+	}
+}
+
+// logMailer wypisuje wiadomości do logu (sterownik "log").
+type logMailer struct{}
+
+func (logMailer) Send(_ context.Context, m Message) error {
+	log.Printf("mail to %s: %s\n%s", m.To, m.Subject, m.Body)
+	return nil
+}
@@ -0,0 +1,121 @@
+// File: internal/mail/mail_test.go
+package mail
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP to minimalny serwer SMTP (bez TLS i logowania), który zwraca
+// kopertę i treść pierwszej wiadomości.
+func fakeSMTP(t *testing.T) (int, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	got := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		var session strings.Builder
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.Fields(line + " x")[0])
+			switch cmd {
+			case "EHLO", "HELO":
+				reply("250 fake")
+			case "MAIL", "RCPT":
+				session.WriteString(line)
+				reply("250 OK")
+			case "DATA":
+				reply("354 go ahead")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					session.WriteString(l)
+				}
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				got <- session.String()
+				return
+			default:
+				reply("502 unsupported")
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, got
+}
+
+func TestSMTP_Send(t *testing.T) {
+	port, got := fakeSMTP(t)
+	m, err := NewSMTP("EventHub <no-reply@example.com>", SMTPConfig{Host: "127.0.0.1", Port: port})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = m.Send(ctx, Message{
+		To:      "ola@example.com",
+		Subject: "Potwierdź adres\r\nBcc: spam@example.com",
+		Body:    "Link:\nhttps://example.com/verify-email/abc",
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	session := <-got
+	for _, want := range []string{
+		"MAIL FROM:<no-reply@example.com>",
+		"RCPT TO:<ola@example.com>",
+		"To: <ola@example.com>\r\n",
+		"Subject: =?utf-8?q?Potwierd=C5=BA_adres_Bcc:_spam@example.com?=\r\n",
+		"\r\nLink:\r\nhttps://example.com/verify-email/abc\r\n",
+	} {
+		if !strings.Contains(session, want) {
+			t.Fatalf("expected %q in session:\n%s", want, session)
+		}
+	}
+	if strings.Contains(session, "\r\nBcc:") {
+		t.Fatalf("subject must not inject headers:\n%s", session)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if m, err := New(Config{}); m != nil || err != nil {
+		t.Fatalf("no driver: expected nil mailer, got %v %v", m, err)
+	}
+	if m, err := New(Config{Driver: "log"}); err != nil || m.Send(context.Background(), Message{To: "a@b.c"}) != nil {
+		t.Fatalf("log driver: %v", err)
+	}
+	for _, cfg := range []Config{
+		{Driver: "pigeon"},
+		{Driver: "smtp", From: "no-reply@example.com"},
+		{Driver: "smtp", From: "not an address", SMTP: SMTPConfig{Host: "localhost"}},
+	} {
+		if _, err := New(cfg); err == nil {
+			t.Fatalf("%+v: expected an error", cfg)
+		}
+	}
+	m, err := New(Config{Driver: "smtp", From: "no-reply@example.com", SMTP: SMTPConfig{Host: "localhost"}})
+	if err != nil || m.(*SMTP).cfg.Port != 587 {
+		t.Fatalf("smtp: expected default port 587, got %v %v", m, err)
+	}
+}
@@ -0,0 +1,130 @@
+// File: internal/mail/smtp.go
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig to serwer SMTP, przez który idzie wysyłka.
+type SMTPConfig struct {
+	Host string `yaml:"host"`
+	// Port domyślnie 587 (STARTTLS, jeśli serwer go oferuje).
+	Port int `yaml:"port"`
+	// ImplicitTLS – połączenie od razu po TLS (zwykle port 465).
+	ImplicitTLS bool   `yaml:"implicitTLS"`
+	Username    string `yaml:"username"`
+	Password    string `yaml:"password"`
+}
+
+// SMTP wysyła wiadomości przez serwer SMTP, jedno połączenie na wiadomość.
+type SMTP struct {
+	cfg  SMTPConfig
+	from *mail.Address
+	now  func() time.Time
+}
+
+// NewSMTP tworzy mailer SMTP z nadawcą from (np. "EventHub <no-reply@example.com>").
+func NewSMTP(from string, cfg SMTPConfig) (*SMTP, error) {
+	if cfg.Host == "" || from == "" {
+		return nil, errors.New("mail: smtp needs host and from")
+	}
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("mail: invalid from %q: %w", from, err)
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &SMTP{cfg: cfg, from: addr, now: time.Now}, nil
+}
+
+// Send łączy się z serwerem i dostarcza wiadomość; ctx ogranicza czas całej rozmowy.
+func (s *SMTP) Send(ctx context.Context, m Message) error {
+	to, err := mail.ParseAddress(m.To)
+	if err != nil {
+		return fmt.Errorf("mail: invalid recipient %q: %w", m.To, err)
+	}
+
+	// 1) Połączenie (opcjonalnie od razu po TLS)
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: s.cfg.Host, MinVersion: tls.VersionTLS12}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if s.cfg.ImplicitTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	// 2) STARTTLS i logowanie
+	if ok, _ := c.Extension("STARTTLS"); ok && !s.cfg.ImplicitTLS {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	// 3) Koperta i treść
+	if err := c.Mail(s.from.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to.Address); err != nil {
+		return err
+	}
+	wc, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := wc.Write(s.format(to, m)); err != nil {
+		wc.Close()
+		return err
+	}
+	if err := wc.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// format buduje wiadomość RFC 5322 (tekst UTF-8). Temat jest kodowany, więc
+// znaki nowej linii nie mogą dopisać własnych nagłówków.
+func (s *SMTP) format(to *mail.Address, m Message) []byte {
+	var b bytes.Buffer
+	header := func(name, value string) {
+		b.WriteString(name + ": " + value + "\r\n")
+	}
+	header("From", s.from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", strings.Join(strings.Fields(m.Subject), " ")))
+	header("Date", s.now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "8bit")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(m.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return b.Bytes()
+}
@@ -13,16 +13,315 @@ type Event struct {
 	ID          int       `json:"id"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
-	Date        time.Time `json:"date"`
-	Capacity    int       `json:"capacity"`
-	OrganizerID int       `json:"organizer_id"`
-	ImageURL    string    `json:"image_url"`
+	Date        time.Time `json:"date"` // początek, w UTC
+	// EndDate to (opcjonalny) koniec, w UTC.
+	EndDate *time.Time `json:"end_date,omitempty"`
+	// Timezone to strefa IANA, w której wydarzenie się odbywa; LocalDate
+	// i LocalEndDate to początek i koniec w tej strefie (RFC 3339 z przesunięciem).
+	Timezone     string `json:"timezone"`
+	LocalDate    string `json:"local_date"`
+	LocalEndDate string `json:"local_end_date,omitempty"`
+	Capacity     int    `json:"capacity"`
+	OrganizerID  int    `json:"organizer_id"`
+	ImageURL     string `json:"image_url"`
+	Status       string `json:"status"`
+	Version      int    `json:"version"`
+	// Sequence to numer wersji terminu dla kalendarzy (SEQUENCE w iCalendar) –
+	// rośnie przy zmianie daty lub statusu. UpdatedAt to czas ostatniej zmiany.
+	Sequence  int        `json:"-"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	// TransfersEnabled mówi, czy uczestnicy mogą przekazywać rezerwacje innym osobom.
+	TransfersEnabled bool `json:"transfers_enabled"`
+	// MaxTicketsPerUser to limit miejsc jednego konta na wydarzenie (łącznie
+	// we wszystkich rezerwacjach; nil – bez limitu). RequireVerifiedEmail
+	// wymaga potwierdzonego adresu e-mail przed rezerwacją.
+	MaxTicketsPerUser    *int `json:"max_tickets_per_user,omitempty"`
+	RequireVerifiedEmail bool `json:"require_verified_email"`
+	// Category to jedna z EventCategories (pusta – bez kategorii), Tags –
+	// dowolne etykiety nadane przez organizatora (małymi literami).
+	Category string   `json:"category,omitempty"`
+	Tags     []string `json:"tags"`
+	// Pola wystąpienia serii cyklicznej: seria, termin wynikający z reguły
+	// (RECURRENCE-ID) i czy wystąpienie zmieniono osobno ("tylko to wystąpienie").
+	SeriesID     *int       `json:"series_id,omitempty"`
+	RecurrenceID *time.Time `json:"recurrence_id,omitempty"`
+	Detached     bool       `json:"detached,omitempty"`
+	// VenueID to miejsce wydarzenia; Venue jest dołączane w szczegółach wydarzenia,
+	// a DistanceKm – w wynikach wyszukiwania w pobliżu (?near=).
+	VenueID    *int     `json:"venue_id,omitempty"`
+	Venue      *Venue   `json:"venue,omitempty"`
+	DistanceKm *float64 `json:"distance_km,omitempty"`
+	// Images to warianty przesłanego plakatu; dołączane tylko, gdy image_url
+	// wskazuje na przesłany plik (a nie np. zewnętrzny adres).
+	Images *EventImages `json:"images,omitempty"`
+}
+
+// EventImage to jeden wariant obrazu wydarzenia (JPEG) w magazynie plików.
+type EventImage struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// EventImages to warianty obrazu wydarzenia: miniatura (lista, awatar),
+// karta (kafelki listy) i duży baner (szczegóły, image_url).
+type EventImages struct {
+	Thumbnail EventImage `json:"thumbnail"`
+	Card      EventImage `json:"card"`
+	Hero      EventImage `json:"hero"`
+}
+
+// Venue to miejsce, w którym odbywają się wydarzenia – wspólne dla wielu
+// wydarzeń. Capacity 0 oznacza brak limitu miejsca.
+type Venue struct {
+	ID            int                `json:"id"`
+	OrganizerID   int                `json:"organizer_id"`
+	Name          string             `json:"name"`
+	Address       string             `json:"address"`
+	Latitude      float64            `json:"latitude"`
+	Longitude     float64            `json:"longitude"`
+	Capacity      int                `json:"capacity"`
+	Accessibility VenueAccessibility `json:"accessibility"`
+}
+
+// VenueAccessibility opisuje dostępność miejsca dla osób z niepełnosprawnościami.
+type VenueAccessibility struct {
+	WheelchairAccessible bool   `json:"wheelchair_accessible"`
+	StepFreeEntrance     bool   `json:"step_free_entrance"`
+	AccessibleToilets    bool   `json:"accessible_toilets"`
+	HearingLoop          bool   `json:"hearing_loop"`
+	Notes                string `json:"notes,omitempty"`
+}
+
+// EventSeries to seria wydarzeń cyklicznych opisana regułą RRULE. Wystąpienia
+// są zwykłymi wydarzeniami (każde z własną pojemnością i rezerwacjami);
+// pola Title..Status to szablon dla nowo generowanych wystąpień.
+type EventSeries struct {
+	ID          int         `json:"id"`
+	OrganizerID int         `json:"organizer_id"`
+	RRule       string      `json:"rrule"`
+	Start       time.Time   `json:"start"`
+	ExDates     []time.Time `json:"exdates"`
+	// Timezone to strefa, w której rozwijana jest reguła (godzina wystąpień
+	// nie przesuwa się przy zmianie czasu); Duration – długość wystąpienia w sekundach.
+	Timezone    string   `json:"timezone"`
+	Duration    int      `json:"duration_seconds,omitempty"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Capacity    int      `json:"capacity"`
+	ImageURL    string   `json:"image_url"`
+	Status      string   `json:"status"`
+	VenueID     *int     `json:"venue_id,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Tags        []string `json:"tags"`
+	Occurrences []Event  `json:"occurrences,omitempty"`
+}
+
+// EventCategories to kategorie, które organizator może nadać wydarzeniu.
+var EventCategories = []string{
+	"concert", "workshop", "conference", "meetup", "sport", "theatre", "exhibition", "festival", "other",
+}
+
+// FacetCount to liczba wydarzeń z daną wartością kategorii lub etykiety.
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// EventFacets to liczności kategorii i etykiet dla bieżącego filtra listy.
+type EventFacets struct {
+	Categories []FacetCount `json:"categories"`
+	Tags       []FacetCount `json:"tags"`
+}
+
+// Statusy wydarzenia.
+const (
+	EventDraft     = "draft"
+	EventPublished = "published"
+	EventCancelled = "cancelled"
+	EventPostponed = "postponed"
+	EventCompleted = "completed"
+)
+
+// eventTransitions to dozwolone przejścia maszyny stanów wydarzenia.
+var eventTransitions = map[string][]string{
+	EventDraft:     {EventPublished, EventCancelled},
+	EventPublished: {EventCancelled, EventPostponed, EventCompleted},
+	EventPostponed: {EventPublished, EventCancelled},
+}
+
+// CanTransition mówi, czy wydarzenie może przejść ze statusu from do to.
+func CanTransition(from, to string) bool {
+	for _, s := range eventTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldChange to zmiana jednego pola wydarzenia w rewizji.
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// EventRevision to zapis jednej zmiany wydarzenia (kto, kiedy, co).
+type EventRevision struct {
+	ID         int                    `json:"id"`
+	EventID    int                    `json:"event_id"`
+	ActorID    int                    `json:"actor_id"`
+	Changes    map[string]FieldChange `json:"changes"`
+	RollbackOf *int                   `json:"rollback_of,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// EventSession to punkt agendy wydarzenia (np. wykład na konferencji) –
+// z własną salą, prelegentem i opcjonalnym limitem miejsc.
+type EventSession struct {
+	ID      int    `json:"id"`
+	EventID int    `json:"event_id"`
+	Title   string `json:"title"`
+	Speaker string `json:"speaker"`
+	Room    string `json:"room"`
+	// StartsAt i EndsAt są w UTC; Local* – w strefie wydarzenia.
+	StartsAt      time.Time `json:"starts_at"`
+	EndsAt        time.Time `json:"ends_at"`
+	LocalStartsAt string    `json:"local_starts_at"`
+	LocalEndsAt   string    `json:"local_ends_at"`
+	// Capacity nil oznacza brak osobnego limitu (obowiązuje limit wydarzenia).
+	Capacity  *int `json:"capacity,omitempty"`
+	SignedUp  int  `json:"signed_up"`
+	SeatsLeft *int `json:"seats_left,omitempty"`
+	// Joined – czy zalogowany użytkownik jest zapisany; EventTitle – tytuł wydarzenia
+	// (przydatny w osobistej agendzie, która łączy sesje wielu wydarzeń).
+	Joined     bool   `json:"joined,omitempty"`
+	EventTitle string `json:"event_title,omitempty"`
+}
+
+// EventTier to pula biletów wydarzenia (np. „Early bird”, „VIP”) z własnym
+// limitem miejsc i opcjonalnym limitem miejsc na konto.
+type EventTier struct {
+	ID      int    `json:"id"`
+	EventID int    `json:"event_id"`
+	Name    string `json:"name"`
+	// Capacity to pojemność puli; TicketsLeft – ile z niej zostało.
+	Capacity    int `json:"capacity"`
+	Booked      int `json:"booked"`
+	TicketsLeft int `json:"tickets_left"`
+	// MaxTicketsPerUser nil oznacza brak limitu na konto w tej puli.
+	MaxTicketsPerUser *int `json:"max_tickets_per_user,omitempty"`
 }
 
 type Reservation struct {
 	ID        int       `json:"id"`
 	UserID    int       `json:"user_id"`
 	EventID   int       `json:"event_id"`
+	TierID    *int      `json:"tier_id,omitempty"`
 	Tickets   int       `json:"tickets"`
 	CreatedAt time.Time `json:"created_at"`
+	// Transfer to oczekujące przekazanie rezerwacji innej osobie (jeśli jest).
+	Transfer *ReservationTransfer `json:"transfer,omitempty"`
+}
+
+// Statusy przekazania rezerwacji.
+const (
+	TransferPending   = "pending"
+	TransferAccepted  = "accepted"
+	TransferCancelled = "cancelled"
+)
+
+// ReservationTransfer to przekazanie rezerwacji (wszystkich jej biletów)
+// osobie o adresie ToEmail. Token to sekret z linku do przyjęcia – widzą go
+// tylko nadawca i adresat.
+type ReservationTransfer struct {
+	ID            int       `json:"id"`
+	ReservationID int       `json:"reservation_id"`
+	EventID       int       `json:"event_id"`
+	EventTitle    string    `json:"event_title"`
+	EventDate     time.Time `json:"event_date"`
+	Tickets       int       `json:"tickets"`
+	FromEmail     string    `json:"from_email"`
+	ToEmail       string    `json:"to_email"`
+	Token         string    `json:"token,omitempty"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// Ticket to kod jednego miejsca z rezerwacji (Seat od 1), skanowany przy wejściu.
+type Ticket struct {
+	Seat int    `json:"seat"`
+	Code string `json:"code"`
+}
+
+// Checkin to skasowanie jednego biletu (miejsca Seat rezerwacji) przy
+// wejściu; Tickets to liczba miejsc w rezerwacji, a CheckedIn – ile z nich
+// już skasowano.
+type Checkin struct {
+	ReservationID int       `json:"reservation_id"`
+	Seat          int       `json:"seat"`
+	UserID        int       `json:"user_id"`
+	Email         string    `json:"email"`
+	Tickets       int       `json:"tickets"`
+	CheckedIn     int       `json:"checked_in"`
+	CheckedInAt   time.Time `json:"checked_in_at"`
+	DeviceID      string    `json:"device_id,omitempty"`
+}
+
+// CheckinSyncResult to wynik jednego skanu przesłanego przez czytnik offline
+// (Index – pozycja w przesłanej liście). Status to "checked_in", "duplicate"
+// (miejsce skasowano wcześniej – CheckedInAt i DeviceID opisują pierwsze
+// wejście) albo "invalid" (Error mówi dlaczego).
+type CheckinSyncResult struct {
+	Index         int        `json:"index"`
+	Status        string     `json:"status"`
+	ReservationID int        `json:"reservation_id,omitempty"`
+	Seat          int        `json:"seat,omitempty"`
+	Email         string     `json:"email,omitempty"`
+	CheckedInAt   *time.Time `json:"checked_in_at,omitempty"`
+	DeviceID      string     `json:"device_id,omitempty"`
+	Error         string     `json:"error,omitempty"`
+}
+
+// CheckinDuplicate to odrzucona próba ponownego wejścia na skasowany już
+// bilet – ScannedAt i DeviceID opisują próbę, First* pierwsze wejście.
+type CheckinDuplicate struct {
+	ReservationID    int       `json:"reservation_id"`
+	Seat             int       `json:"seat"`
+	Email            string    `json:"email"`
+	DeviceID         string    `json:"device_id"`
+	ScannedAt        time.Time `json:"scanned_at"`
+	FirstCheckedInAt time.Time `json:"first_checked_in_at"`
+	FirstDeviceID    string    `json:"first_device_id"`
+}
+
+// TicketSnapshot to lista ważnych biletów wydarzenia dla czytników offline.
+// Version zmienia się tylko wtedy, gdy zmienia się lista lub stan wejść.
+type TicketSnapshot struct {
+	Format      int              `json:"format"`
+	EventID     int              `json:"event_id"`
+	Version     string           `json:"version"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Tickets     []SnapshotTicket `json:"tickets"`
+}
+
+// SnapshotTicket to jeden bilet w migawce; CheckedInAt jest ustawione, gdy
+// miejsce skasowano już przed pobraniem migawki.
+type SnapshotTicket struct {
+	Code          string     `json:"code"`
+	ReservationID int        `json:"reservation_id"`
+	Seat          int        `json:"seat"`
+	Email         string     `json:"email"`
+	CheckedInAt   *time.Time `json:"checked_in_at,omitempty"`
+}
+
+// Attendee to rezerwacja na wydarzenie widziana przez organizatora –
+// razem z adresem e-mail uczestnika.
+type Attendee struct {
+	ReservationID int       `json:"reservation_id"`
+	UserID        int       `json:"user_id"`
+	Email         string    `json:"email"`
+	Tickets       int       `json:"tickets"`
+	ReservedAt    time.Time `json:"reserved_at"`
 }
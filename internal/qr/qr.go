@@ -0,0 +1,427 @@
+// File: internal/qr/qr.go
+//
+// Package qr koduje krótkie teksty (np. kody biletów) jako kody QR (ISO/IEC
+// 18004) – tylko biblioteką standardową. Obsługuje tryb bajtowy i wersje
+// 1–10 (do 271 bajtów przy poziomie L), co z zapasem wystarcza na bilety.
+package qr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Level to poziom korekcji błędów – jaka część kodu może być nieczytelna
+// (zabrudzona, zagięta), a kod nadal da się odczytać.
+type Level int
+
+// Poziomy korekcji: ok. 7%, 15%, 25% i 30% kodu.
+const (
+	L Level = iota
+	M
+	Q
+	H
+)
+
+// MaxVersion to największa obsługiwana wersja (rozmiar 57×57 modułów).
+const MaxVersion = 10
+
+// ErrTooLong oznacza, że dane nie mieszczą się w największej obsługiwanej wersji.
+var ErrTooLong = errors.New("qr: data too long")
+
+// Code to zakodowany symbol: kwadrat Size×Size modułów (bez marginesu).
+type Code struct {
+	Size    int
+	Version int
+	modules []bool
+}
+
+// Black mówi, czy moduł w kolumnie x i wierszu y jest ciemny.
+func (c *Code) Black(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.modules[y*c.Size+x]
+}
+
+// blockSpec opisuje podział słów kodowych wersji na bloki: g1 bloków po d1
+// słowach danych i g2 bloków po d1+1 słowach, każdy z ec słowami korekcji.
+type blockSpec struct {
+	ec, g1, d1, g2 int
+}
+
+// blocks[wersja-1][poziom] – tabela 9 normy.
+var blocks = [MaxVersion][4]blockSpec{
+	{{7, 1, 19, 0}, {10, 1, 16, 0}, {13, 1, 13, 0}, {17, 1, 9, 0}},
+	{{10, 1, 34, 0}, {16, 1, 28, 0}, {22, 1, 22, 0}, {28, 1, 16, 0}},
+	{{15, 1, 55, 0}, {26, 1, 44, 0}, {18, 2, 17, 0}, {22, 2, 13, 0}},
+	{{20, 1, 80, 0}, {18, 2, 32, 0}, {26, 2, 24, 0}, {16, 4, 9, 0}},
+	{{26, 1, 108, 0}, {24, 2, 43, 0}, {18, 2, 15, 2}, {22, 2, 11, 2}},
+	{{18, 2, 68, 0}, {16, 4, 27, 0}, {24, 4, 19, 0}, {28, 4, 15, 0}},
+	{{20, 2, 78, 0}, {18, 4, 31, 0}, {18, 2, 14, 4}, {26, 4, 13, 1}},
+	{{24, 2, 97, 0}, {22, 2, 38, 2}, {22, 4, 18, 2}, {26, 4, 14, 2}},
+	{{30, 2, 116, 0}, {22, 3, 36, 2}, {20, 4, 16, 4}, {24, 4, 12, 4}},
+	{{18, 2, 68, 2}, {26, 4, 43, 1}, {24, 6, 19, 2}, {28, 6, 15, 2}},
+}
+
+// alignment[wersja-1] to współrzędne środków wzorów wyrównania.
+var alignment = [MaxVersion][]int{
+	nil,
+	{6, 18},
+	{6, 22},
+	{6, 26},
+	{6, 30},
+	{6, 34},
+	{6, 22, 38},
+	{6, 24, 42},
+	{6, 26, 46},
+	{6, 28, 50},
+}
+
+func (b blockSpec) data() int { return b.g1*b.d1 + b.g2*(b.d1+1) }
+
+// Encode koduje dane w najmniejszej wersji, która je mieści na danym poziomie
+// korekcji, wybierając maskę o najmniejszej karze (jak zaleca norma).
+func Encode(data []byte, level Level) (*Code, error) {
+	return encode(data, level, -1)
+}
+
+func encode(data []byte, level Level, mask int) (*Code, error) {
+	if level < L || level > H {
+		return nil, fmt.Errorf("qr: invalid level %d", level)
+	}
+	for v := 1; v <= MaxVersion; v++ {
+		countBits := 8
+		if v >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(data) <= 8*blocks[v-1][level].data() {
+			return build(v, level, mask, codewords(data, v, level, countBits)), nil
+		}
+	}
+	return nil, ErrTooLong
+}
+
+// codewords zwraca dane w trybie bajtowym, uzupełnione do pojemności wersji,
+// z dołączonymi słowami korekcji i przeplecione między blokami.
+func codewords(data []byte, version int, level Level, countBits int) []byte {
+	spec := blocks[version-1][level]
+	capacity := spec.data()
+
+	// 1) Tryb bajtowy (0100), długość, dane, terminator i dopełnienie do bajtu
+	var bb bitBuffer
+	bb.append(0x4, 4)
+	bb.append(len(data), countBits)
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+	if rest := capacity*8 - bb.n; rest > 0 {
+		bb.append(0, min(4, rest))
+	}
+	if bb.n%8 != 0 {
+		bb.append(0, 8-bb.n%8)
+	}
+	// 2) Bajty wypełnienia
+	for pad := 0; len(bb.bytes) < capacity; pad++ {
+		bb.bytes = append(bb.bytes, [2]byte{0xEC, 0x11}[pad%2])
+	}
+
+	// 3) Podział na bloki i korekcja Reeda–Solomona
+	var dataBlocks, ecBlocks [][]byte
+	rest := bb.bytes
+	gen := generator(spec.ec)
+	for i := 0; i < spec.g1+spec.g2; i++ {
+		n := spec.d1
+		if i >= spec.g1 {
+			n++
+		}
+		dataBlocks = append(dataBlocks, rest[:n])
+		ecBlocks = append(ecBlocks, remainder(rest[:n], gen))
+		rest = rest[n:]
+	}
+
+	// 4) Przeplot: kolejne słowa danych ze wszystkich bloków, potem korekcji
+	out := make([]byte, 0, capacity+spec.ec*len(dataBlocks))
+	for i := 0; i <= spec.d1; i++ {
+		for _, b := range dataBlocks {
+			if i < len(b) {
+				out = append(out, b[i])
+			}
+		}
+	}
+	for i := 0; i < spec.ec; i++ {
+		for _, b := range ecBlocks {
+			out = append(out, b[i])
+		}
+	}
+	return out
+}
+
+type bitBuffer struct {
+	bytes []byte
+	n     int // liczba zapisanych bitów
+}
+
+func (b *bitBuffer) append(v, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		if b.n%8 == 0 {
+			b.bytes = append(b.bytes, 0)
+		}
+		if v>>i&1 == 1 {
+			b.bytes[b.n/8] |= 0x80 >> (b.n % 8)
+		}
+		b.n++
+	}
+}
+
+// symbol to macierz w trakcie budowy; function oznacza moduły wzorów
+// stałych (nie należą do danych i nie są maskowane).
+type symbol struct {
+	size     int
+	modules  []bool
+	function []bool
+}
+
+func (s *symbol) set(x, y int, black bool) {
+	s.modules[y*s.size+x] = black
+	s.function[y*s.size+x] = true
+}
+
+func build(version int, level Level, mask int, data []byte) *Code {
+	size := 17 + 4*version
+	s := &symbol{size: size, modules: make([]bool, size*size), function: make([]bool, size*size)}
+
+	// 1) Wzory stałe: lokalizacyjne, wyrównania, synchronizacyjne, informacja
+	// o formacie i wersji (na razie rezerwujemy ich miejsce)
+	for _, p := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		s.finder(p[0], p[1])
+	}
+	pos := alignment[version-1]
+	for _, ax := range pos {
+		for _, ay := range pos {
+			if s.function[ay*size+ax] {
+				continue // nakłada się na wzór lokalizacyjny
+			}
+			s.align(ax, ay)
+		}
+	}
+	for i := 8; i < size-8; i++ {
+		s.set(6, i, i%2 == 0)
+		s.set(i, 6, i%2 == 0)
+	}
+	s.format(level, 0)
+	s.version(version)
+
+	// 2) Dane – zygzakiem od prawego dolnego rogu, parami kolumn
+	i := 0
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // pomijamy pionowy wzór synchronizacyjny
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < size; vert++ {
+			y := vert
+			if upward {
+				y = size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if s.function[y*size+x] {
+					continue
+				}
+				if i < len(data)*8 {
+					s.modules[y*size+x] = data[i/8]>>(7-i%8)&1 == 1
+					i++
+				}
+			}
+		}
+	}
+
+	// 3) Maska: wskazana albo ta o najmniejszej karze
+	if mask < 0 {
+		best := -1
+		for m := 0; m < 8; m++ {
+			s.apply(m)
+			s.format(level, m)
+			if p := s.penalty(); best < 0 || p < best {
+				best, mask = p, m
+			}
+			s.apply(m) // XOR – ponowne nałożenie zdejmuje maskę
+		}
+	}
+	s.apply(mask)
+	s.format(level, mask)
+	return &Code{Size: size, Version: version, modules: s.modules}
+}
+
+// finder rysuje wzór lokalizacyjny 7×7 o środku (cx, cy) wraz z jasną ramką.
+func (s *symbol) finder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || y < 0 || x >= s.size || y >= s.size {
+				continue
+			}
+			d := max(abs(dx), abs(dy))
+			s.set(x, y, d != 2 && d != 4)
+		}
+	}
+}
+
+// align rysuje wzór wyrównania 5×5 o środku (cx, cy).
+func (s *symbol) align(cx, cy int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			s.set(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// format zapisuje (w dwóch kopiach) poziom korekcji i numer maski,
+// zabezpieczone kodem BCH(15,5).
+func (s *symbol) format(level Level, mask int) {
+	levelBits := [4]int{1, 0, 3, 2}[level]
+	data := levelBits<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		s.set(8, i, bit(i))
+	}
+	s.set(8, 7, bit(6))
+	s.set(8, 8, bit(7))
+	s.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		s.set(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		s.set(s.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		s.set(8, s.size-15+i, bit(i))
+	}
+	s.set(8, s.size-8, true) // zawsze ciemny moduł
+}
+
+// version zapisuje numer wersji (od wersji 7), zabezpieczony kodem BCH(18,6).
+func (s *symbol) version(version int) {
+	if version < 7 {
+		return
+	}
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	bits := version<<12 | rem
+	for i := 0; i < 18; i++ {
+		black := bits>>i&1 == 1
+		a, b := s.size-11+i%3, i/3
+		s.set(a, b, black)
+		s.set(b, a, black)
+	}
+}
+
+// apply odwraca moduły danych, dla których warunek maski jest spełniony.
+func (s *symbol) apply(mask int) {
+	for y := 0; y < s.size; y++ {
+		for x := 0; x < s.size; x++ {
+			if s.function[y*s.size+x] {
+				continue
+			}
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip {
+				s.modules[y*s.size+x] = !s.modules[y*s.size+x]
+			}
+		}
+	}
+}
+
+// penalty liczy karę maski według czterech reguł normy: długie serie
+// jednego koloru, bloki 2×2, wzory podobne do lokalizacyjnych i
+// odchylenie proporcji ciemnych modułów od 50%.
+func (s *symbol) penalty() int {
+	n := s.size
+	at := func(x, y int) bool { return s.modules[y*n+x] }
+	p := 0
+
+	// 1) i 3) Serie i wzory 1:1:3:1:1 w wierszach i kolumnach
+	finderLike := [2][11]bool{
+		{true, false, true, true, true, false, true, false, false, false, false},
+		{false, false, false, false, true, false, true, true, true, false, true},
+	}
+	for _, transposed := range []bool{false, true} {
+		get := at
+		if transposed {
+			get = func(x, y int) bool { return at(y, x) }
+		}
+		for y := 0; y < n; y++ {
+			run := 1
+			for x := 1; x <= n; x++ {
+				if x < n && get(x, y) == get(x-1, y) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					p += 3 + run - 5
+				}
+				run = 1
+			}
+			for x := 0; x+11 <= n; x++ {
+				for _, pattern := range finderLike {
+					match := true
+					for k := 0; k < 11 && match; k++ {
+						match = get(x+k, y) == pattern[k]
+					}
+					if match {
+						p += 40
+					}
+				}
+			}
+		}
+	}
+
+	// 2) Bloki 2×2 jednego koloru
+	for y := 0; y < n-1; y++ {
+		for x := 0; x < n-1; x++ {
+			c := at(x, y)
+			if c == at(x+1, y) && c == at(x, y+1) && c == at(x+1, y+1) {
+				p += 3
+			}
+		}
+	}
+
+	// 4) Proporcja ciemnych modułów
+	dark := 0
+	for _, m := range s.modules {
+		if m {
+			dark++
+		}
+	}
+	p += 10 * (abs(dark*20-n*n*10) / (n * n))
+	return p
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
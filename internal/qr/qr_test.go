@@ -0,0 +1,113 @@
+// File: internal/qr/qr_test.go
+package qr
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestEncode_VersionAndLimits(t *testing.T) {
+	cases := []struct {
+		n       int
+		level   Level
+		version int
+	}{
+		{17, L, 1}, {18, L, 2}, {14, M, 1}, {26, M, 2}, {27, M, 3}, {271, L, 10}, {119, H, 10},
+	}
+	for _, c := range cases {
+		code, err := Encode(bytes.Repeat([]byte("x"), c.n), c.level)
+		if err != nil {
+			t.Fatalf("%d bytes at level %d: %v", c.n, c.level, err)
+		}
+		if code.Version != c.version || code.Size != 17+4*c.version {
+			t.Fatalf("%d bytes at level %d: expected version %d, got %d", c.n, c.level, c.version, code.Version)
+		}
+	}
+	if _, err := Encode(bytes.Repeat([]byte("x"), 272), L); err != ErrTooLong {
+		t.Fatalf("expected ErrTooLong, got %v", err)
+	}
+}
+
+func TestEncode_FunctionPatterns(t *testing.T) {
+	code, err := Encode([]byte("EH1.42.1.7.abcdefghijklmnopqrstuv"), M)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := code.Size
+	// Wzory lokalizacyjne w trzech rogach: ciemna ramka, jasny pierścień, ciemny środek
+	for _, corner := range [][2]int{{0, 0}, {n - 7, 0}, {0, n - 7}} {
+		x, y := corner[0], corner[1]
+		if !code.Black(x, y) || !code.Black(x+6, y+6) || code.Black(x+1, y+1) || !code.Black(x+3, y+3) {
+			t.Fatalf("finder pattern at %v is broken", corner)
+		}
+	}
+	if code.Black(n-7, n-7) && code.Black(n-1, n-1) && code.Black(n-7, n-1) {
+		t.Fatalf("unexpected finder pattern in the bottom-right corner")
+	}
+	for i := 8; i < n-8; i++ {
+		if code.Black(i, 6) != (i%2 == 0) || code.Black(6, i) != (i%2 == 0) {
+			t.Fatalf("timing pattern is broken at %d", i)
+		}
+	}
+
+	// Obie kopie informacji o formacie muszą być zgodne i dawać poziom M (00)
+	var first, second int
+	for i := 0; i <= 5; i++ {
+		first |= b(code.Black(8, i)) << i
+	}
+	first |= b(code.Black(8, 7))<<6 | b(code.Black(8, 8))<<7 | b(code.Black(7, 8))<<8
+	for i := 9; i < 15; i++ {
+		first |= b(code.Black(14-i, 8)) << i
+	}
+	for i := 0; i < 8; i++ {
+		second |= b(code.Black(n-1-i, 8)) << i
+	}
+	for i := 8; i < 15; i++ {
+		second |= b(code.Black(8, n-15+i)) << i
+	}
+	if first != second || (first^0x5412)>>13 != 0 {
+		t.Fatalf("format information mismatch: %015b vs %015b", first, second)
+	}
+}
+
+func b(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+func TestReedSolomon(t *testing.T) {
+	// Przykład z normy (wersja 1-M, "01234567")
+	data := []byte{0x10, 0x20, 0x0C, 0x56, 0x61, 0x80, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11}
+	want := []byte{0xA5, 0x24, 0xD4, 0xC1, 0xED, 0x36, 0xC7, 0x87, 0x2C, 0x55}
+	if got := remainder(data, generator(10)); !bytes.Equal(got, want) {
+		t.Fatalf("unexpected error correction codewords % X", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	code, _ := Encode([]byte("ticket"), M)
+	img, err := png.Decode(bytes.NewReader(code.PNG(3)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	side := (code.Size + 2*QuietZone) * 3
+	if img.Bounds().Dx() != side || img.Bounds().Dy() != side {
+		t.Fatalf("unexpected PNG size %v", img.Bounds())
+	}
+	// Lewy górny moduł wzoru lokalizacyjnego jest ciemny, margines – jasny
+	if r, _, _, _ := img.At(QuietZone*3, QuietZone*3).RGBA(); r != 0 {
+		t.Fatalf("expected a dark module at the finder corner")
+	}
+	if r, _, _, _ := img.At(1, 1).RGBA(); r == 0 {
+		t.Fatalf("expected a light quiet zone")
+	}
+
+	svg := string(code.SVG(4))
+	if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, `viewBox="0 0 29 29"`) || !strings.Contains(svg, "M4 4h7v1h-7z") {
+		t.Fatalf("unexpected SVG %s", svg)
+	}
+}
@@ -0,0 +1,151 @@
+// File: internal/ratelimit/ratelimit.go
+package ratelimit
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+)
+
+// Config zawiera polityki dla grup tras oraz listę zaufanych proxy.
+type Config struct {
+	Auth   Policy `yaml:"auth"`   // rejestracja i logowanie
+	Reads  Policy `yaml:"reads"`  // żądania GET
+	Writes Policy `yaml:"writes"` // POST/PUT/DELETE
+	// WritesPerIP obowiązuje zapisy przed sprawdzeniem tokenu, więc dławi
+	// także żądania bez tokenu lub z nieprawidłowym tokenem.
+	WritesPerIP Policy `yaml:"writesPerIP"`
+	// Checkin obowiązuje kasowanie biletów zamiast Writes: przy wejściu wszystkie
+	// czytniki organizatora skanują na jednym koncie, często zza jednego NAT-u.
+	Checkin Policy `yaml:"checkin"`
+	// TrustedProxies to adresy lub sieci CIDR, którym wierzymy w nagłówku X-Forwarded-For.
+	TrustedProxies []string `yaml:"trustedProxies"`
+}
+
+// Limiter ogranicza liczbę żądań w jednej grupie tras.
+type Limiter struct {
+	name   string
+	policy Policy
+	store  Store
+	ips    *IPResolver
+}
+
+// New tworzy limiter grupy name. Klucz kubełka to ID użytkownika z
+// auth.FromContext, a dla żądań anonimowych – adres IP klienta.
+func New(name string, p Policy, store Store, ips *IPResolver) *Limiter {
+	return &Limiter{name: name, policy: p, store: store, ips: ips}
+}
+
+// Middleware owija handler limitem; przy przekroczeniu zwraca 429.
+// Żeby kluczem było ID użytkownika, musi działać wewnątrz auth.JWTMiddleware.
+func (l *Limiter) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	if !l.policy.Enabled() {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := l.store.Take(r.Context(), l.key(r), l.policy)
+		if err != nil {
+			// Awaria magazynu nie może blokować całego API.
+			log.Printf("ratelimit %s: %v", l.name, err)
+			next(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("RateLimit-Reset", ceilSeconds(res.Reset))
+		h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", res.Limit, ceilSeconds(seconds(float64(res.Limit)/l.policy.Rate))))
+		if !res.Allowed {
+			h.Set("Retry-After", ceilSeconds(res.RetryAfter))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (l *Limiter) key(r *http.Request) string {
+	if claims, ok := auth.FromContext(r.Context()); ok {
+		if id, ok := claims["id"].(float64); ok {
+			return l.name + ":user:" + strconv.Itoa(int(id))
+		}
+	}
+	return l.name + ":ip:" + l.ips.ClientIP(r)
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// IPResolver ustala adres klienta, ufając X-Forwarded-For tylko wtedy,
+// gdy żądanie przyszło od zaufanego proxy.
+type IPResolver struct {
+	trusted []*net.IPNet
+}
+
+// NewIPResolver parsuje listę adresów/sieci CIDR zaufanych proxy.
+func NewIPResolver(proxies []string) (*IPResolver, error) {
+	res := &IPResolver{}
+	for _, p := range proxies {
+		if !strings.Contains(p, "/") {
+			if ip := net.ParseIP(p); ip != nil && ip.To4() != nil {
+				p += "/32"
+			} else {
+				p += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", p, err)
+		}
+		res.trusted = append(res.trusted, n)
+	}
+	return res, nil
+}
+
+func (res *IPResolver) isTrusted(ip net.IP) bool {
+	for _, n := range res.trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP zwraca adres klienta. X-Forwarded-For czytamy od prawej,
+// pomijając zaufane proxy – pierwszy niezaufany adres to klient.
+func (res *IPResolver) ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || res == nil || !res.isTrusted(ip) {
+		return host
+	}
+
+	var hops []string
+	for _, v := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			// Śmieci w nagłówku – nie idziemy dalej w lewo.
+			break
+		}
+		ip = hop
+		if !res.isTrusted(hop) {
+			break
+		}
+	}
+	return ip.String()
+}
@@ -0,0 +1,122 @@
+// File: internal/ratelimit/ratelimit_test.go
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bartbaranski/eventhub/internal/auth"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// fakeClock pozwala przesuwać czas w testach kubełka.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time      { return c.t }
+func (c *fakeClock) add(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestStore(c *fakeClock) *MemoryStore {
+	s := NewMemoryStore()
+	s.now = c.now
+	return s
+}
+
+func TestMemoryStore_TokenBucket(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	s := newTestStore(clock)
+	p := Policy{Rate: 1, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		if res, _ := s.Take(context.Background(), "k", p); !res.Allowed {
+			t.Fatalf("request %d should be allowed", i)
+		}
+	}
+	res, _ := s.Take(context.Background(), "k", p)
+	if res.Allowed {
+		t.Fatal("third request should be limited")
+	}
+	if res.RetryAfter <= 0 || res.RetryAfter > time.Second {
+		t.Errorf("unexpected RetryAfter %v", res.RetryAfter)
+	}
+
+	clock.add(time.Second)
+	if res, _ := s.Take(context.Background(), "k", p); !res.Allowed {
+		t.Fatal("bucket should refill after 1s")
+	}
+	if res, _ := s.Take(context.Background(), "other", p); !res.Allowed || res.Remaining != 1 {
+		t.Fatalf("separate key should have its own bucket, got %+v", res)
+	}
+}
+
+func TestMiddleware_Returns429WithHeaders(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	l := New("writes", Policy{Rate: 0.5, Burst: 1}, newTestStore(clock), nil)
+	h := l.Middleware(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	do := func(ctx context.Context, remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/reservations", nil).WithContext(ctx)
+		req.RemoteAddr = remote
+		w := httptest.NewRecorder()
+		h(w, req)
+		return w
+	}
+
+	user7 := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(7)})
+	if w := do(user7, "10.0.0.1:1234"); w.Code != http.StatusCreated || w.Header().Get("RateLimit-Limit") != "1" {
+		t.Fatalf("first request: code=%d headers=%v", w.Code, w.Header())
+	}
+	// Ten sam użytkownik z innego IP dzieli kubełek.
+	w := do(user7, "10.0.0.2:1234")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "2" || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("unexpected headers: %v", w.Header())
+	}
+	// Inny użytkownik ma własny limit.
+	user8 := auth.NewContext(context.Background(), jwt.MapClaims{"id": float64(8)})
+	if w := do(user8, "10.0.0.2:1234"); w.Code != http.StatusCreated {
+		t.Fatalf("other user should pass, got %d", w.Code)
+	}
+	// Anonimowe żądania są liczone po IP.
+	if w := do(context.Background(), "10.0.0.3:1"); w.Code != http.StatusCreated {
+		t.Fatalf("anonymous first request should pass, got %d", w.Code)
+	}
+	if w := do(context.Background(), "10.0.0.3:2"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("anonymous second request from same IP should be limited, got %d", w.Code)
+	}
+}
+
+func TestIPResolver_TrustedProxies(t *testing.T) {
+	res, err := NewIPResolver([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		remote, xff, want string
+	}{
+		// Niezaufany nadawca – nagłówek ignorowany.
+		{"203.0.113.9:80", "1.2.3.4", "203.0.113.9"},
+		// Zaufane proxy – bierzemy pierwszy niezaufany adres od prawej.
+		{"10.1.1.1:80", "6.6.6.6, 1.2.3.4, 192.168.1.1", "1.2.3.4"},
+		// Sfałszowany adres po lewej nie ma znaczenia.
+		{"192.168.1.1:80", "8.8.8.8, 5.5.5.5", "5.5.5.5"},
+		// Brak nagłówka – adres proxy.
+		{"10.1.1.1:80", "", "10.1.1.1"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = c.remote
+		if c.xff != "" {
+			req.Header.Set("X-Forwarded-For", c.xff)
+		}
+		if got := res.ClientIP(req); got != c.want {
+			t.Errorf("ClientIP(%s, %q) = %s, want %s", c.remote, c.xff, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// File: internal/ratelimit/store.go
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Policy opisuje kubełek żetonów: Rate żetonów na sekundę i pojemność Burst.
+// Polityka z Rate <= 0 wyłącza limitowanie.
+type Policy struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// Enabled mówi, czy polityka cokolwiek ogranicza.
+func (p Policy) Enabled() bool {
+	return p.Rate > 0 && p.Burst > 0
+}
+
+// Result to wynik pobrania żetonu z kubełka.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // kiedy pojawi się następny żeton (gdy !Allowed)
+	Reset      time.Duration // kiedy kubełek będzie znów pełny
+}
+
+// Store przechowuje stan kubełków. Implementacja w pamięci wystarcza dla
+// jednej instancji; przy wielu instancjach można podłączyć wspólny magazyn
+// (np. Redis) implementujący ten sam interfejs.
+type Store interface {
+	Take(ctx context.Context, key string, p Policy) (Result, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	policy Policy
+}
+
+// MemoryStore to Store trzymający kubełki w pamięci procesu.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// sweepInterval określa, jak często usuwamy pełne (nieużywane) kubełki.
+const sweepInterval = time.Minute
+
+// NewMemoryStore tworzy pusty magazyn w pamięci.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}, now: time.Now}
+}
+
+// Take pobiera jeden żeton z kubełka key, uzupełniając go proporcjonalnie
+// do czasu od ostatniego pobrania.
+func (s *MemoryStore) Take(_ context.Context, key string, p Policy) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) > sweepInterval {
+		s.sweep(now)
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(p.Burst), last: now, policy: p}
+		s.buckets[key] = b
+	}
+	b.policy = p
+	b.tokens = math.Min(float64(p.Burst), b.tokens+now.Sub(b.last).Seconds()*p.Rate)
+	b.last = now
+
+	res := Result{Limit: p.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / p.Rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((float64(p.Burst) - b.tokens) / p.Rate)
+	return res, nil
+}
+
+// sweep usuwa kubełki, które zdążyły się w pełni uzupełnić – ich stan
+// jest identyczny z nowo utworzonym kubełkiem.
+func (s *MemoryStore) sweep(now time.Time) {
+	for k, b := range s.buckets {
+		full := seconds((float64(b.policy.Burst) - b.tokens) / b.policy.Rate)
+		if now.Sub(b.last) > full {
+			delete(s.buckets, k)
+		}
+	}
+}
+
+func seconds(f float64) time.Duration {
+	return time.Duration(f * float64(time.Second))
+}
@@ -0,0 +1,441 @@
+// File: internal/recurrence/rrule.go
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency to częstotliwość reguły (FREQ).
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// WeekdayNum to element BYDAY, np. "MO" (N=0, każdy poniedziałek),
+// "2TU" (drugi wtorek) albo "-1FR" (ostatni piątek okresu).
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// Rule to podzbiór RRULE z RFC 5545: FREQ, INTERVAL, COUNT, UNTIL, BYDAY,
+// BYMONTHDAY, BYMONTH i WKST. Godzina wystąpień zawsze pochodzi z DTSTART.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	WeekStart  time.Weekday
+}
+
+// UntilLayout to format UNTIL (i dat w EXDATE) – czas UTC w formie podstawowej.
+const UntilLayout = "20060102T150405Z"
+
+// maxPeriods ogranicza liczbę przeglądanych okresów, żeby reguła, która
+// nigdy nic nie generuje (np. BYMONTH=2;BYMONTHDAY=30), nie zapętliła Expand.
+const maxPeriods = 10000
+
+// ErrTooMany zwraca Expand, gdy reguła daje więcej wystąpień niż limit.
+var ErrTooMany = errors.New("rule produces too many occurrences")
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Parse parsuje regułę w postaci "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10"
+// (opcjonalnie z prefiksem "RRULE:").
+func Parse(s string) (Rule, error) {
+	r := Rule{Interval: 1, WeekStart: time.Monday}
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return r, errors.New("empty rule")
+	}
+
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return r, fmt.Errorf("invalid rule part %q", part)
+		}
+		key, val := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		if seen[key] {
+			return r, fmt.Errorf("duplicate %s", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq = Frequency(val)
+			switch r.Freq {
+			case Daily, Weekly, Monthly, Yearly:
+			default:
+				err = fmt.Errorf("unsupported FREQ %q", val)
+			}
+		case "INTERVAL":
+			r.Interval, err = positive(key, val)
+		case "COUNT":
+			r.Count, err = positive(key, val)
+		case "UNTIL":
+			r.Until, err = parseUntil(val)
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				var wd WeekdayNum
+				if wd, err = parseWeekdayNum(d); err != nil {
+					break
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(val, ",") {
+				n, convErr := strconv.Atoi(d)
+				if convErr != nil || n == 0 || n < -31 || n > 31 {
+					err = fmt.Errorf("invalid BYMONTHDAY %q", d)
+					break
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, m := range strings.Split(val, ",") {
+				n, convErr := strconv.Atoi(m)
+				if convErr != nil || n < 1 || n > 12 {
+					err = fmt.Errorf("invalid BYMONTH %q", m)
+					break
+				}
+				r.ByMonth = append(r.ByMonth, time.Month(n))
+			}
+		case "WKST":
+			wd, ok := weekdays[val]
+			if !ok {
+				err = fmt.Errorf("invalid WKST %q", val)
+			}
+			r.WeekStart = wd
+		default:
+			err = fmt.Errorf("unsupported rule part %s", key)
+		}
+		if err != nil {
+			return r, err
+		}
+	}
+
+	if r.Freq == "" {
+		return r, errors.New("FREQ is required")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return r, errors.New("COUNT and UNTIL are mutually exclusive")
+	}
+	for _, d := range r.ByDay {
+		if d.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return r, errors.New("numbered BYDAY is only allowed with MONTHLY or YEARLY")
+		}
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return r, errors.New("BYMONTHDAY is not allowed with WEEKLY")
+	}
+	if r.Freq == Yearly && len(r.ByDay) > 0 && len(r.ByMonth) == 0 {
+		return r, errors.New("BYDAY with YEARLY requires BYMONTH")
+	}
+	return r, nil
+}
+
+func positive(key, val string) (int, error) {
+	n, err := strconv.Atoi(val)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive number", key)
+	}
+	return n, nil
+}
+
+// parseUntil przyjmuje UNTIL jako czas UTC ("20250630T180000Z") albo samą
+// datę ("20250630") – wtedy cały ten dzień mieści się w regule.
+func parseUntil(val string) (time.Time, error) {
+	if t, err := time.Parse(UntilLayout, val); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("20060102", val); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q, use YYYYMMDDTHHMMSSZ", val)
+}
+
+func parseWeekdayNum(s string) (WeekdayNum, error) {
+	if len(s) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", s)
+	}
+	day, ok := weekdays[s[len(s)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", s)
+	}
+	wd := WeekdayNum{Day: day}
+	if prefix := s[:len(s)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -53 || n > 53 {
+			return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", s)
+		}
+		wd.N = n
+	}
+	return wd, nil
+}
+
+// String zwraca regułę w postaci kanonicznej (bez prefiksu "RRULE:").
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(UntilLayout))
+	}
+	if len(r.ByMonth) > 0 {
+		ms := make([]string, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			ms[i] = strconv.Itoa(int(m))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(ms, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		ds := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			ds[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(ds, ","))
+	}
+	if len(r.ByDay) > 0 {
+		ds := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			ds[i] = weekdayNames[d.Day]
+			if d.N != 0 {
+				ds[i] = strconv.Itoa(d.N) + ds[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(ds, ","))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayNames[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+// Bounded mówi, czy reguła ma koniec (COUNT albo UNTIL).
+func (r Rule) Bounded() bool {
+	return r.Count > 0 || !r.Until.IsZero()
+}
+
+// Expand zwraca kolejne wystąpienia reguły od start (włącznie), pomijając
+// exdates. Tak jak w RFC 5545, wykluczone daty liczą się do COUNT.
+// Jeśli wystąpień jest więcej niż limit, zwraca ErrTooMany.
+func (r Rule) Expand(start time.Time, exdates []time.Time, limit int) ([]time.Time, error) {
+	excluded := map[int64]bool{}
+	for _, t := range exdates {
+		excluded[t.Unix()] = true
+	}
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	var out []time.Time
+	generated := 0
+	for k := 0; k < maxPeriods; k++ {
+		candidates, periodStart := r.period(start, k*interval)
+		if !r.Until.IsZero() && periodStart.After(r.Until) {
+			break
+		}
+		for _, t := range candidates {
+			if t.Before(start) {
+				continue
+			}
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return out, nil
+			}
+			generated++
+			if !excluded[t.Unix()] {
+				if len(out) == limit {
+					return nil, ErrTooMany
+				}
+				out = append(out, t)
+			}
+			if r.Count > 0 && generated == r.Count {
+				return out, nil
+			}
+		}
+	}
+	if !r.Bounded() && len(out) > 0 {
+		// Reguła bez końca zawsze przekroczy limit.
+		return nil, ErrTooMany
+	}
+	return out, nil
+}
+
+// period zwraca posortowane wystąpienia w okresie o numerze n (liczonym
+// w jednostkach FREQ od okresu zawierającego start) oraz początek okresu.
+func (r Rule) period(start time.Time, n int) ([]time.Time, time.Time) {
+	y, m, d := start.Date()
+	loc := start.Location()
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, start.Hour(), start.Minute(), start.Second(), 0, loc)
+	}
+
+	var out []time.Time
+	var periodStart time.Time
+	switch r.Freq {
+	case Daily:
+		t := day(y, m, d+n)
+		periodStart = t
+		if r.matchMonth(t.Month()) && r.matchMonthDay(t) && r.matchWeekday(t) {
+			out = append(out, t)
+		}
+	case Weekly:
+		offset := (int(start.Weekday()) - int(r.WeekStart) + 7) % 7
+		periodStart = day(y, m, d-offset+7*n)
+		for i := 0; i < 7; i++ {
+			t := periodStart.AddDate(0, 0, i)
+			if len(r.ByDay) == 0 && t.Weekday() != start.Weekday() {
+				continue
+			}
+			if r.matchWeekday(t) && r.matchMonth(t.Month()) {
+				out = append(out, t)
+			}
+		}
+	case Monthly:
+		periodStart = day(y, m+time.Month(n), 1)
+		if r.matchMonth(periodStart.Month()) {
+			out = r.monthDays(periodStart.Year(), periodStart.Month(), d, day)
+		}
+	case Yearly:
+		periodStart = day(y+n, time.January, 1)
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{m}
+		}
+		for _, mm := range months {
+			out = append(out, r.monthDays(y+n, mm, d, day)...)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return out, periodStart
+}
+
+// monthDays zwraca dni miesiąca pasujące do BYMONTHDAY/BYDAY; bez nich –
+// dzień miesiąca z DTSTART (o ile istnieje w tym miesiącu).
+func (r Rule) monthDays(y int, m time.Month, startDay int, day func(int, time.Month, int) time.Time) []time.Time {
+	last := time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		if startDay > last {
+			return nil
+		}
+		return []time.Time{day(y, m, startDay)}
+	}
+
+	var out []time.Time
+	for d := 1; d <= last; d++ {
+		t := day(y, m, d)
+		if len(r.ByMonthDay) > 0 && !r.matchMonthDay(t) {
+			continue
+		}
+		if len(r.ByDay) > 0 && !r.matchNthWeekday(d, last, t.Weekday()) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+func (r Rule) matchMonth(m time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, bm := range r.ByMonth {
+		if bm == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) matchMonthDay(t time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	last := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, bd := range r.ByMonthDay {
+		if bd == t.Day() || (bd < 0 && last+bd+1 == t.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) matchWeekday(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, bd := range r.ByDay {
+		if bd.Day == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchNthWeekday sprawdza BYDAY z numerem w obrębie miesiąca
+// (d – dzień miesiąca, last – liczba dni w miesiącu).
+func (r Rule) matchNthWeekday(d, last int, wd time.Weekday) bool {
+	for _, bd := range r.ByDay {
+		if bd.Day != wd {
+			continue
+		}
+		switch {
+		case bd.N == 0:
+			return true
+		case bd.N > 0 && (d-1)/7+1 == bd.N:
+			return true
+		case bd.N < 0 && (last-d)/7+1 == -bd.N:
+			return true
+		}
+	}
+	return false
+}
+
+// FormatDates zapisuje listę dat (np. EXDATE) jako "20250101T100000Z,...".
+func FormatDates(ts []time.Time) string {
+	out := make([]string, len(ts))
+	for i, t := range ts {
+		out[i] = t.UTC().Format(UntilLayout)
+	}
+	return strings.Join(out, ",")
+}
+
+// ParseDates odczytuje listę zapisaną przez FormatDates.
+func ParseDates(s string) ([]time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var out []time.Time
+	for _, p := range strings.Split(s, ",") {
+		t, err := time.Parse(UntilLayout, p)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
@@ -0,0 +1,143 @@
+// File: internal/recurrence/rrule_test.go
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02T15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func expand(t *testing.T, rule, start string, exdates ...string) []string {
+	t.Helper()
+	r, err := Parse(rule)
+	if err != nil {
+		t.Fatalf("parse %q: %v", rule, err)
+	}
+	var ex []time.Time
+	for _, e := range exdates {
+		ex = append(ex, date(e))
+	}
+	got, err := r.Expand(date(start), ex, 100)
+	if err != nil {
+		t.Fatalf("expand %q: %v", rule, err)
+	}
+	out := make([]string, len(got))
+	for i, g := range got {
+		out[i] = g.Format("2006-01-02T15:04")
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestExpand(t *testing.T) {
+	cases := []struct {
+		name, rule, start string
+		exdates           []string
+		want              []string
+	}{
+		{
+			name: "daily with interval", rule: "FREQ=DAILY;INTERVAL=2;COUNT=3", start: "2030-01-30T18:00",
+			want: []string{"2030-01-30T18:00", "2030-02-01T18:00", "2030-02-03T18:00"},
+		},
+		{
+			name: "weekly on two days", rule: "RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4", start: "2030-01-02T18:00", // środa
+			want: []string{"2030-01-02T18:00", "2030-01-07T18:00", "2030-01-09T18:00", "2030-01-14T18:00"},
+		},
+		{
+			name: "weekly until", rule: "FREQ=WEEKLY;UNTIL=20300116T180000Z", start: "2030-01-02T18:00",
+			want: []string{"2030-01-02T18:00", "2030-01-09T18:00", "2030-01-16T18:00"},
+		},
+		{
+			name: "exdates count towards COUNT", rule: "FREQ=WEEKLY;COUNT=3", start: "2030-01-02T18:00",
+			exdates: []string{"2030-01-09T18:00"},
+			want:    []string{"2030-01-02T18:00", "2030-01-16T18:00"},
+		},
+		{
+			name: "monthly skips missing days", rule: "FREQ=MONTHLY;COUNT=3", start: "2030-01-31T10:00",
+			want: []string{"2030-01-31T10:00", "2030-03-31T10:00", "2030-05-31T10:00"},
+		},
+		{
+			name: "last friday of the month", rule: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=2", start: "2030-01-01T19:00",
+			want: []string{"2030-01-25T19:00", "2030-02-22T19:00"},
+		},
+		{
+			name: "second tuesday", rule: "FREQ=MONTHLY;BYDAY=2TU;COUNT=2", start: "2030-01-01T19:00",
+			want: []string{"2030-01-08T19:00", "2030-02-12T19:00"},
+		},
+		{
+			name: "last day of the month", rule: "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=2", start: "2030-01-15T12:00",
+			want: []string{"2030-01-31T12:00", "2030-02-28T12:00"},
+		},
+		{
+			name: "yearly by month", rule: "FREQ=YEARLY;BYMONTH=6,12;COUNT=3", start: "2030-01-10T09:00",
+			want: []string{"2030-06-10T09:00", "2030-12-10T09:00", "2031-06-10T09:00"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := expand(t, c.rule, c.start, c.exdates...); !equal(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestExpand_Limit(t *testing.T) {
+	r, _ := Parse("FREQ=DAILY;COUNT=500")
+	if _, err := r.Expand(date("2030-01-01T10:00"), nil, 100); !errors.Is(err, ErrTooMany) {
+		t.Fatalf("expected ErrTooMany, got %v", err)
+	}
+	r, _ = Parse("FREQ=WEEKLY")
+	if _, err := r.Expand(date("2030-01-01T10:00"), nil, 100); !errors.Is(err, ErrTooMany) {
+		t.Fatalf("unbounded rule: expected ErrTooMany, got %v", err)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"COUNT=3",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20300101T000000Z",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=YEARLY;BYDAY=MO",
+		"FREQ=DAILY;BYHOUR=10",
+		"FREQ=DAILY;FREQ=WEEKLY",
+	} {
+		if _, err := Parse(rule); err == nil {
+			t.Errorf("Parse(%q): expected error", rule)
+		}
+	}
+}
+
+func TestString_RoundTrip(t *testing.T) {
+	in := "FREQ=MONTHLY;INTERVAL=2;UNTIL=20301231T235959Z;BYMONTHDAY=1,-1;BYDAY=-1FR;WKST=SU"
+	r, err := Parse(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.String() != in {
+		t.Fatalf("got %q, want %q", r.String(), in)
+	}
+}
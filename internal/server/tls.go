@@ -0,0 +1,157 @@
+// File: internal/server/tls.go
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TLSConfig to opcjonalna terminacja TLS bez reverse proxy.
+type TLSConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+	// MinVersion: "1.2" (domyślnie) albo "1.3".
+	MinVersion string `yaml:"minVersion"`
+	// RedirectAddress to adres listenera HTTP przekierowującego na HTTPS (np. ":80");
+	// pusty – bez przekierowania.
+	RedirectAddress string `yaml:"redirectAddress"`
+	// HSTSMaxAge w sekundach; 0 wyłącza nagłówek Strict-Transport-Security.
+	HSTSMaxAge            int  `yaml:"hstsMaxAge"`
+	HSTSIncludeSubdomains bool `yaml:"hstsIncludeSubdomains"`
+}
+
+// reloadCheckInterval określa, jak często sprawdzamy, czy pliki certyfikatu się zmieniły.
+const reloadCheckInterval = 10 * time.Second
+
+// CertReloader trzyma aktualny certyfikat i wczytuje go ponownie, gdy pliki
+// na dysku się zmienią (np. po odnowieniu przez certbota) – bez restartu.
+type CertReloader struct {
+	certFile, keyFile string
+	checkEvery        time.Duration
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	certMod   time.Time
+	keyMod    time.Time
+	lastCheck time.Time
+}
+
+// NewCertReloader wczytuje certyfikat; błąd oznacza, że pliki są niepoprawne.
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	c := &CertReloader{certFile: certFile, keyFile: keyFile, checkEvery: reloadCheckInterval}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *CertReloader) load() error {
+	certInfo, err := os.Stat(c.certFile)
+	if err != nil {
+		return err
+	}
+	keyInfo, err := os.Stat(c.keyFile)
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return err
+	}
+	c.cert = &cert
+	c.certMod = certInfo.ModTime()
+	c.keyMod = keyInfo.ModTime()
+	c.lastCheck = time.Now()
+	return nil
+}
+
+// GetCertificate implementuje tls.Config.GetCertificate.
+func (c *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.lastCheck) >= c.checkEvery {
+		c.lastCheck = time.Now()
+		certInfo, err1 := os.Stat(c.certFile)
+		keyInfo, err2 := os.Stat(c.keyFile)
+		if err1 == nil && err2 == nil &&
+			(!certInfo.ModTime().Equal(c.certMod) || !keyInfo.ModTime().Equal(c.keyMod)) {
+			// Przy nieudanym wczytaniu (np. w połowie zapisu) zostajemy przy starym certyfikacie.
+			if err := c.load(); err != nil {
+				log.Printf("tls: reloading certificate: %v", err)
+			} else {
+				log.Printf("tls: certificate reloaded from %s", c.certFile)
+			}
+		}
+	}
+	return c.cert, nil
+}
+
+// NewTLSConfig buduje tls.Config z hot reloadem certyfikatu i obsługą HTTP/2.
+func NewTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	minVersion := uint16(tls.VersionTLS12)
+	switch cfg.MinVersion {
+	case "", "1.2":
+	case "1.3":
+		minVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported TLS minVersion %q", cfg.MinVersion)
+	}
+	reloader, err := NewCertReloader(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:     minVersion,
+		GetCertificate: reloader.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}, nil
+}
+
+// HSTS dodaje nagłówek Strict-Transport-Security do odpowiedzi po TLS.
+func HSTS(cfg TLSConfig, next http.Handler) http.Handler {
+	if cfg.HSTSMaxAge <= 0 {
+		return next
+	}
+	value := "max-age=" + strconv.Itoa(cfg.HSTSMaxAge)
+	if cfg.HSTSIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil {
+			w.Header().Set("Strict-Transport-Security", value)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RedirectHandler przekierowuje żądania HTTP na HTTPS pod adres httpsAddr
+// (port z httpsAddr jest pomijany, jeśli to 443).
+func RedirectHandler(httpsAddr string) http.Handler {
+	_, port, _ := net.SplitHostPort(httpsAddr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		target := "https://" + host + r.URL.RequestURI()
+
+		// 308 zachowuje metodę i body dla POST/PUT.
+		code := http.StatusMovedPermanently
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			code = http.StatusPermanentRedirect
+		}
+		http.Redirect(w, r, target, code)
+	})
+}
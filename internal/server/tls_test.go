@@ -0,0 +1,191 @@
+// File: internal/server/tls_test.go
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSelfSigned generuje samopodpisany certyfikat dla 127.0.0.1 i zapisuje
+// go (razem z kluczem) do plików PEM; zwraca certyfikat do puli zaufanych CA.
+func writeSelfSigned(t *testing.T, certFile, keyFile string, serial int64) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "eventhub-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// startTLS uruchamia serwer jak main.go: tls.Config + HSTS + ServeTLS.
+func startTLS(t *testing.T, cfg TLSConfig, tlsCfg *tls.Config) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{
+		Handler: HSTS(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Proto))
+		})),
+		TLSConfig: tlsCfg,
+	}
+	go srv.ServeTLS(ln, "", "")
+	t.Cleanup(func() { srv.Close() })
+	return "https://" + ln.Addr().String()
+}
+
+func client(roots ...*x509.Certificate) *http.Client {
+	pool := x509.NewCertPool()
+	for _, c := range roots {
+		pool.AddCert(c)
+	}
+	return &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: pool},
+		ForceAttemptHTTP2: true,
+		DisableKeepAlives: true,
+	}}
+}
+
+func TestTLS_HTTP2AndHSTS(t *testing.T) {
+	dir := t.TempDir()
+	cfg := TLSConfig{
+		Enabled:               true,
+		CertFile:              filepath.Join(dir, "cert.pem"),
+		KeyFile:               filepath.Join(dir, "key.pem"),
+		MinVersion:            "1.2",
+		HSTSMaxAge:            31536000,
+		HSTSIncludeSubdomains: true,
+	}
+	ca := writeSelfSigned(t, cfg.CertFile, cfg.KeyFile, 1)
+	tlsCfg, err := NewTLSConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := startTLS(t, cfg, tlsCfg)
+
+	resp, err := client(ca).Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("expected HTTP/2, got %s", resp.Proto)
+	}
+	if got := resp.Header.Get("Strict-Transport-Security"); got != "max-age=31536000; includeSubDomains" {
+		t.Errorf("unexpected HSTS header %q", got)
+	}
+}
+
+func TestTLS_MinVersion(t *testing.T) {
+	dir := t.TempDir()
+	cfg := TLSConfig{CertFile: filepath.Join(dir, "c.pem"), KeyFile: filepath.Join(dir, "k.pem"), MinVersion: "1.3"}
+	ca := writeSelfSigned(t, cfg.CertFile, cfg.KeyFile, 1)
+	tlsCfg, err := NewTLSConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := startTLS(t, cfg, tlsCfg)
+
+	c := client(ca)
+	c.Transport.(*http.Transport).TLSClientConfig.MaxVersion = tls.VersionTLS12
+	if _, err := c.Get(url); err == nil {
+		t.Fatal("expected handshake failure for TLS 1.2 client")
+	}
+	if _, err := NewTLSConfig(TLSConfig{MinVersion: "1.0"}); err == nil {
+		t.Error("expected error for unsupported minVersion")
+	}
+}
+
+func TestCertReloader_HotReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	oldCA := writeSelfSigned(t, certFile, keyFile, 1)
+
+	reloader, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloader.checkEvery = 0
+	url := startTLS(t, TLSConfig{}, &tls.Config{GetCertificate: reloader.GetCertificate})
+
+	serial := func(ca *x509.Certificate) int64 {
+		resp, err := client(ca).Get(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.TLS.PeerCertificates[0].SerialNumber.Int64()
+	}
+	if got := serial(oldCA); got != 1 {
+		t.Fatalf("expected serial 1, got %d", got)
+	}
+
+	newCA := writeSelfSigned(t, certFile, keyFile, 2)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+	os.Chtimes(keyFile, future, future)
+	if got := serial(newCA); got != 2 {
+		t.Fatalf("expected reloaded certificate serial 2, got %d", got)
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	cases := []struct {
+		addr, method, target, want string
+		code                       int
+	}{
+		{":443", "GET", "http://example.com/events?x=1", "https://example.com/events?x=1", http.StatusMovedPermanently},
+		{":8443", "GET", "http://example.com:8080/", "https://example.com:8443/", http.StatusMovedPermanently},
+		{":443", "POST", "http://example.com/api/v1/reservations", "https://example.com/api/v1/reservations", http.StatusPermanentRedirect},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		RedirectHandler(c.addr).ServeHTTP(w, httptest.NewRequest(c.method, c.target, nil))
+		if w.Code != c.code || w.Header().Get("Location") != c.want {
+			t.Errorf("%s %s: got %d %q, want %d %q", c.method, c.target, w.Code, w.Header().Get("Location"), c.code, c.want)
+		}
+	}
+}
@@ -13,6 +13,8 @@ func NewTestDB() *sql.DB {
 	if err != nil {
 		panic(err)
 	}
+	// Każde połączenie do ":memory:" to osobna, pusta baza – trzymamy jedno.
+	db.SetMaxOpenConns(1)
 	schema := `
     CREATE TABLE users (
       id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -0,0 +1,101 @@
+// File: internal/ticket/ticket.go
+//
+// Package ticket podpisuje i weryfikuje kody biletów drukowane w kodach QR.
+// Kod ma postać "EH1.<rezerwacja>.<wydarzenie>.<miejsce>[.<generacja>].<podpis>",
+// gdzie podpis to skrócony HMAC-SHA256 pozostałej części – bilet da się
+// sprawdzić bez zapytania do bazy, ale nie da się go podrobić bez klucza
+// serwera. Generacja rośnie przy przekazaniu rezerwacji innej osobie, co
+// unieważnia wcześniejsze kody (dla generacji 0 segment jest pomijany).
+package ticket
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Prefix to wersja formatu kodu.
+const Prefix = "EH1"
+
+// sigLen to długość podpisu w bajtach (128 bitów wystarcza, a kod QR jest mniejszy).
+const sigLen = 16
+
+// ErrInvalid oznacza kod w złym formacie albo z niepoprawnym podpisem.
+var ErrInvalid = errors.New("invalid ticket code")
+
+// Ticket to jedno miejsce z rezerwacji – każde ma własny kod, aby przy
+// wejściu można je było skasować osobno. Seat liczy się od 1.
+type Ticket struct {
+	Reservation int
+	Event       int
+	Seat        int
+	Generation  int
+}
+
+// Key wyprowadza klucz podpisu biletów z sekretu serwera (tego samego co
+// dla JWT), tak by podpisy biletów i tokenów nie były wymienne.
+func Key(secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("eventhub ticket " + Prefix))
+	return mac.Sum(nil)
+}
+
+// SnapshotKey wyprowadza z sekretu serwera klucz Ed25519 do podpisywania
+// migawek ważnych biletów dla czytników offline. Czytnik zna tylko klucz
+// publiczny, więc może sprawdzić migawkę, ale nie może podpisać własnej.
+func SnapshotKey(secret []byte) ed25519.PrivateKey {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("eventhub snapshot " + Prefix))
+	return ed25519.NewKeyFromSeed(mac.Sum(nil))
+}
+
+// Sign zwraca podpisany kod biletu.
+func Sign(key []byte, t Ticket) string {
+	parts := []string{
+		Prefix,
+		strconv.Itoa(t.Reservation),
+		strconv.Itoa(t.Event),
+		strconv.Itoa(t.Seat),
+	}
+	if t.Generation > 0 {
+		parts = append(parts, strconv.Itoa(t.Generation))
+	}
+	payload := strings.Join(parts, ".")
+	return payload + "." + signature(key, payload)
+}
+
+// Verify sprawdza podpis kodu i zwraca zapisany w nim bilet.
+func Verify(key []byte, code string) (Ticket, error) {
+	i := strings.LastIndexByte(code, '.')
+	if i < 0 {
+		return Ticket{}, ErrInvalid
+	}
+	payload, sig := code[:i], code[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(signature(key, payload))) {
+		return Ticket{}, ErrInvalid
+	}
+
+	parts := strings.Split(payload, ".")
+	if (len(parts) != 4 && len(parts) != 5) || parts[0] != Prefix {
+		return Ticket{}, ErrInvalid
+	}
+	var nums [4]int
+	for k, s := range parts[1:] {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || strconv.Itoa(n) != s {
+			return Ticket{}, ErrInvalid
+		}
+		nums[k] = n
+	}
+	return Ticket{Reservation: nums[0], Event: nums[1], Seat: nums[2], Generation: nums[3]}, nil
+}
+
+func signature(key []byte, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:sigLen])
+}
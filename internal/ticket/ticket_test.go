@@ -0,0 +1,57 @@
+// File: internal/ticket/ticket_test.go
+package ticket
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	key := Key([]byte("secret"))
+	want := Ticket{Reservation: 42, Event: 7, Seat: 3}
+	code := Sign(key, want)
+	if !strings.HasPrefix(code, "EH1.42.7.3.") || len(code) != len("EH1.42.7.3.")+22 {
+		t.Fatalf("unexpected code %q", code)
+	}
+	got, err := Verify(key, code)
+	if err != nil || got != want {
+		t.Fatalf("expected %+v, got %+v (%v)", want, got, err)
+	}
+
+	sig := code[strings.LastIndexByte(code, '.'):]
+	invalid := []string{
+		"",
+		"EH1.42.7.3",
+		"EH1.42.7.4" + sig,  // inne miejsce z podpisem miejsca 3
+		"EH1.42.7.03" + sig, // niekanoniczna liczba
+		Sign(Key([]byte("other")), want),
+		code[:len(code)-1] + "A",
+	}
+	for _, c := range invalid {
+		if _, err := Verify(key, c); err != ErrInvalid {
+			t.Fatalf("%q: expected ErrInvalid, got %v", c, err)
+		}
+	}
+
+	// Po przekazaniu rezerwacji kod zawiera generację i różni się od poprzedniego
+	transferred := Ticket{Reservation: 42, Event: 7, Seat: 3, Generation: 2}
+	code2 := Sign(key, transferred)
+	if !strings.HasPrefix(code2, "EH1.42.7.3.2.") || code2 == code {
+		t.Fatalf("unexpected code %q", code2)
+	}
+	if got, err := Verify(key, code2); err != nil || got != transferred {
+		t.Fatalf("expected %+v, got %+v (%v)", transferred, got, err)
+	}
+}
+
+func TestSnapshotKey(t *testing.T) {
+	key := SnapshotKey([]byte("secret"))
+	if !key.Equal(SnapshotKey([]byte("secret"))) || key.Equal(SnapshotKey([]byte("other"))) {
+		t.Fatalf("snapshot key must be derived deterministically from the secret")
+	}
+	sig := ed25519.Sign(key, []byte("snapshot"))
+	if !ed25519.Verify(key.Public().(ed25519.PublicKey), []byte("snapshot"), sig) {
+		t.Fatalf("signature does not verify")
+	}
+}
@@ -0,0 +1,295 @@
+// File: internal/validate/validate.go
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DateTimeLayout to format daty+godziny bez strefy przyjmowany w żądaniach
+// ("YYYY-MM-DDTHH:MM"); obok niego przyjmujemy RFC 3339 (patrz ParseDateTime).
+const DateTimeLayout = "2006-01-02T15:04"
+
+// ParseDateTime parsuje datę z żądania: RFC 3339 (ze strefą lub przesunięciem)
+// albo DateTimeLayout – wtedy jako czas lokalny w loc. Zwraca czas w UTC.
+func ParseDateTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.ParseInLocation(DateTimeLayout, s, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
+
+// FieldError opisuje błąd pojedynczego pola żądania.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors to lista wszystkich błędów walidacji znalezionych w strukturze.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// now pozwala testom podmienić zegar.
+var now = time.Now
+
+// Struct sprawdza pola struktury według tagów `validate` i zwraca wszystkie
+// błędy naraz (jako Errors) albo nil.
+//
+// Obsługiwane reguły (oddzielone przecinkami):
+//
+//	required         – wartość nie może być pusta
+//	notblank         – jak required, ale nil (pole pominięte) jest poprawny
+//	min=N, max=N     – długość napisu (w znakach) lub wartość liczby
+//	oneof=a|b        – wartość musi być jedną z wymienionych
+//	email            – poprawny adres e-mail
+//	password         – polityka haseł (patrz checkPassword)
+//	datetime         – data RFC 3339 albo w formacie DateTimeLayout
+//	future           – data (jak w datetime; bez strefy – UTC) w przyszłości
+//	timezone         – nazwa strefy czasowej IANA, np. "Europe/Warsaw"
+//	url=http|https   – ścieżka absolutna ("/...") albo URL z jednym z podanych schematów
+//
+// Puste, niewymagane napisy są pomijane przez pozostałe reguły.
+func Struct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs Errors
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get("validate")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		name := fieldName(sf)
+		fv := rv.Field(i)
+		for _, msg := range checkField(fv, strings.Split(tag, ",")) {
+			errs = append(errs, FieldError{Field: name, Message: msg})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// fieldName zwraca nazwę pola z tagu json, tak jak widzi ją klient.
+func fieldName(sf reflect.StructField) string {
+	if tag := sf.Tag.Get("json"); tag != "" {
+		if name := strings.Split(tag, ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+func checkField(fv reflect.Value, rules []string) []string {
+	// Wskaźniki oznaczają pola opcjonalne – nil jest zawsze poprawny,
+	// chyba że pole jest wymagane.
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			for _, r := range rules {
+				if r == "required" {
+					return []string{"is required"}
+				}
+			}
+			return nil
+		}
+		fv = fv.Elem()
+		// Podana liczba spełnia required także jako zero (np. szerokość 0°).
+		if fv.Kind() != reflect.String {
+			rules = withoutRequired(rules)
+		}
+	}
+
+	required := false
+	for _, r := range rules {
+		if r == "required" || r == "notblank" {
+			required = true
+		}
+	}
+	if fv.IsZero() && fv.Kind() == reflect.String {
+		if required {
+			return []string{"is required"}
+		}
+		return nil
+	}
+
+	var msgs []string
+	for _, rule := range rules {
+		name, arg := rule, ""
+		if i := strings.IndexByte(rule, '='); i >= 0 {
+			name, arg = rule[:i], rule[i+1:]
+		}
+		if msg := applyRule(fv, name, arg); msg != "" {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+func withoutRequired(rules []string) []string {
+	var out []string
+	for _, r := range rules {
+		if r != "required" && r != "notblank" {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func applyRule(fv reflect.Value, name, arg string) string {
+	switch name {
+	case "required", "notblank":
+		if fv.IsZero() {
+			return "is required"
+		}
+	case "min", "max":
+		n, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			panic(fmt.Sprintf("validate: bad %s argument %q", name, arg))
+		}
+		return checkBound(fv, name, n)
+	case "oneof":
+		s := fmt.Sprint(fv.Interface())
+		for _, opt := range strings.Split(arg, "|") {
+			if s == opt {
+				return ""
+			}
+		}
+		return "must be one of: " + strings.ReplaceAll(arg, "|", ", ")
+	case "email":
+		if !isEmail(fv.String()) {
+			return "must be a valid email address"
+		}
+	case "password":
+		return checkPassword(fv.String())
+	case "datetime":
+		if _, err := ParseDateTime(fv.String(), time.UTC); err != nil {
+			return "must be an RFC 3339 date-time or use format YYYY-MM-DDTHH:MM"
+		}
+	case "future":
+		t, err := ParseDateTime(fv.String(), time.UTC)
+		if err == nil && !t.After(now()) {
+			return "must be in the future"
+		}
+	case "timezone":
+		// "Local" zależy od maszyny serwera – nie jest strefą wydarzenia.
+		if _, err := time.LoadLocation(fv.String()); err != nil || fv.String() == "Local" {
+			return "must be an IANA time zone, e.g. Europe/Warsaw"
+		}
+	case "url":
+		return checkURL(fv.String(), strings.Split(arg, "|"))
+	default:
+		panic(fmt.Sprintf("validate: unknown rule %q", name))
+	}
+	return ""
+}
+
+func checkBound(fv reflect.Value, name string, n float64) string {
+	var got float64
+	var unit string
+	switch fv.Kind() {
+	case reflect.String:
+		got = float64(utf8.RuneCountInString(fv.String()))
+		unit = " characters"
+	case reflect.Slice, reflect.Map:
+		got = float64(fv.Len())
+		unit = " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		got = float64(fv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		got = float64(fv.Uint())
+	case reflect.Float32, reflect.Float64:
+		got = fv.Float()
+	default:
+		return ""
+	}
+	limit := strconv.FormatFloat(n, 'f', -1, 64)
+	if name == "min" && got < n {
+		if unit != "" {
+			return "must have at least " + limit + unit
+		}
+		return "must be at least " + limit
+	}
+	if name == "max" && got > n {
+		if unit != "" {
+			return "must have at most " + limit + unit
+		}
+		return "must be at most " + limit
+	}
+	return ""
+}
+
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s {
+		return false
+	}
+	at := strings.LastIndexByte(s, '@')
+	domain := s[at+1:]
+	return strings.Contains(domain, ".") && !strings.HasSuffix(domain, ".")
+}
+
+// checkPassword wymusza politykę haseł: 8–72 bajty (limit bcrypt),
+// co najmniej jedna litera i jedna cyfra.
+func checkPassword(s string) string {
+	if len(s) < 8 {
+		return "must have at least 8 characters"
+	}
+	if len(s) > 72 {
+		return "must have at most 72 bytes"
+	}
+	var letter, digit bool
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	if !letter || !digit {
+		return "must contain at least one letter and one digit"
+	}
+	return ""
+}
+
+func checkURL(s string, schemes []string) string {
+	// Ścieżki względem serwera (np. "/images/event1.jpg") są dozwolone.
+	if strings.HasPrefix(s, "/") && !strings.HasPrefix(s, "//") {
+		return ""
+	}
+	u, err := url.Parse(s)
+	if err == nil && u.Host != "" {
+		for _, sc := range schemes {
+			if strings.EqualFold(u.Scheme, sc) {
+				return ""
+			}
+		}
+	}
+	return "must be a path or an URL with scheme " + strings.Join(schemes, " or ")
+}
@@ -0,0 +1,155 @@
+// File: internal/validate/validate_test.go
+package validate
+
+import (
+	"testing"
+	"time"
+)
+
+type sample struct {
+	Title    string `json:"title" validate:"required,max=5"`
+	Capacity int    `json:"capacity" validate:"min=1,max=10"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,password"`
+	Role     string `json:"role" validate:"oneof=organizer|participant"`
+	When     string `json:"when" validate:"required,datetime,future"`
+	Image    string `json:"image" validate:"url=http|https"`
+	Note     *int   `json:"note" validate:"min=0"`
+}
+
+func valid() sample {
+	return sample{
+		Title:    "Gig",
+		Capacity: 5,
+		Email:    "a@example.com",
+		Password: "Secret123",
+		Role:     "organizer",
+		When:     time.Now().Add(48 * time.Hour).Format(DateTimeLayout),
+		Image:    "/images/a.jpg",
+	}
+}
+
+func fields(err error) map[string]bool {
+	out := map[string]bool{}
+	if err == nil {
+		return out
+	}
+	for _, fe := range err.(Errors) {
+		out[fe.Field] = true
+	}
+	return out
+}
+
+func TestStruct_Valid(t *testing.T) {
+	s := valid()
+	if err := Struct(&s); err != nil {
+		t.Fatalf("expected no errors, got %v", err)
+	}
+	s.Image = "https://cdn.example.com/a.jpg"
+	if err := Struct(s); err != nil {
+		t.Fatalf("expected https url to pass, got %v", err)
+	}
+}
+
+func TestStruct_ReportsAllErrors(t *testing.T) {
+	neg := -1
+	s := sample{
+		Title:    "too long title",
+		Capacity: -3,
+		Email:    "not-an-email",
+		Password: "a",
+		Role:     "admin",
+		When:     "2001-01-01T10:00",
+		Image:    "javascript:alert(1)",
+		Note:     &neg,
+	}
+	got := fields(Struct(s))
+	for _, f := range []string{"title", "capacity", "email", "password", "role", "when", "image", "note"} {
+		if !got[f] {
+			t.Errorf("expected error for %q, got %v", f, got)
+		}
+	}
+}
+
+func TestStruct_RequiredAndFormat(t *testing.T) {
+	s := valid()
+	s.Title = ""
+	s.When = "jutro"
+	got := fields(Struct(s))
+	if !got["title"] || !got["when"] || len(got) != 2 {
+		t.Fatalf("unexpected errors: %v", got)
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	cases := map[string]bool{
+		"Pass123!":    true,
+		"short1":      false,
+		"onlyletters": false,
+		"1234567890":  false,
+		"Zażółć12345": true,
+	}
+	for pw, ok := range cases {
+		if got := checkPassword(pw) == ""; got != ok {
+			t.Errorf("checkPassword(%q) ok=%v, want %v", pw, got, ok)
+		}
+	}
+}
+
+func TestNotBlankPointer(t *testing.T) {
+	type patch struct {
+		Title *string `json:"title" validate:"notblank,max=5"`
+	}
+	empty, ok := "", "ok"
+	if err := Struct(patch{}); err != nil {
+		t.Fatalf("omitted field should pass, got %v", err)
+	}
+	if err := Struct(patch{Title: &ok}); err != nil {
+		t.Fatalf("non-empty field should pass, got %v", err)
+	}
+	if got := fields(Struct(patch{Title: &empty})); !got["title"] {
+		t.Fatalf("empty field should fail, got %v", got)
+	}
+}
+
+func TestDateTimeAndTimezone(t *testing.T) {
+	type event struct {
+		When string `json:"when" validate:"datetime"`
+		Zone string `json:"zone" validate:"timezone"`
+	}
+	for _, when := range []string{"2030-06-18T18:00", "2030-06-18T18:00:00+02:00", "2030-06-18T16:00:00Z"} {
+		if err := Struct(event{When: when, Zone: "Europe/Warsaw"}); err != nil {
+			t.Errorf("%q: expected no errors, got %v", when, err)
+		}
+	}
+	got := fields(Struct(event{When: "18.06.2030 18:00", Zone: "Mars/Olympus"}))
+	if !got["when"] || !got["zone"] {
+		t.Fatalf("expected errors for when and zone, got %v", got)
+	}
+	if got := fields(Struct(event{Zone: "Local"})); !got["zone"] {
+		t.Fatalf("expected Local to be rejected, got %v", got)
+	}
+
+	warsaw, _ := time.LoadLocation("Europe/Warsaw")
+	local, _ := ParseDateTime("2030-06-18T18:00", warsaw)
+	offset, _ := ParseDateTime("2030-06-18T18:00:00+02:00", time.UTC)
+	if !local.Equal(offset) || local.Location() != time.UTC || local.Hour() != 16 {
+		t.Fatalf("unexpected parse results: %v, %v", local, offset)
+	}
+}
+
+func TestRequiredPointerAllowsZero(t *testing.T) {
+	type point struct {
+		Lat *float64 `json:"lat" validate:"required,min=-90,max=90"`
+	}
+	zero, tooBig := 0.0, 91.0
+	if err := Struct(point{Lat: &zero}); err != nil {
+		t.Fatalf("zero should pass, got %v", err)
+	}
+	if got := fields(Struct(point{})); !got["lat"] {
+		t.Fatalf("missing field should fail, got %v", got)
+	}
+	if got := fields(Struct(point{Lat: &tooBig})); !got["lat"] {
+		t.Fatalf("out of range value should fail, got %v", got)
+	}
+}
@@ -0,0 +1,93 @@
+// File: internal/web/spa.go
+package web
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Config określa, skąd serwer bierze zbudowany frontend.
+type Config struct {
+	// Mode: "" – frontend nie jest serwowany, "embed" – pliki wbudowane
+	// w binarkę (build z -tags embedfrontend), "dir" – pliki z katalogu Dir.
+	Mode string `yaml:"mode"`
+	Dir  string `yaml:"dir"`
+}
+
+// hashedName rozpoznaje pliki z hashem treści w nazwie (np. main.3f2a9c1b.js).
+var hashedName = regexp.MustCompile(`\.[0-9a-f]{8,}\.`)
+
+// SPA serwuje aplikację jednostronicową z fsys. Ścieżki bez odpowiadającego
+// pliku (trasy klienta, np. /events/5) dostają index.html; zasoby z hashem
+// w nazwie są cache'owane "na zawsze", a index.html – nigdy.
+// Ścieżki /api/ nigdy nie trafiają do fallbacku, żeby literówka w adresie
+// API dawała 404 zamiast strony HTML.
+func SPA(fsys fs.FS) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		p := path.Clean("/" + r.URL.Path)
+		if p == "/api" || strings.HasPrefix(p, "/api/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		name := strings.TrimPrefix(p, "/")
+		if name != "" && name != "index.html" {
+			if info, err := fs.Stat(fsys, name); err == nil && !info.IsDir() {
+				if isHashedAsset(name) {
+					w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+				} else {
+					w.Header().Set("Cache-Control", "public, max-age=3600")
+				}
+				serveFile(w, r, fsys, name)
+				return
+			}
+			// Brakujący plik z rozszerzeniem to prawdziwe 404, nie trasa klienta.
+			if path.Ext(name) != "" {
+				http.NotFound(w, r)
+				return
+			}
+		}
+
+		w.Header().Set("Cache-Control", "no-cache")
+		serveFile(w, r, fsys, "index.html")
+	})
+}
+
+func isHashedAsset(name string) bool {
+	return strings.HasPrefix(name, "static/") || hashedName.MatchString(path.Base(name))
+}
+
+// serveFile wysyła plik przez http.ServeContent (Range, If-Modified-Since),
+// bez przekierowań, które robi http.FileServer dla index.html.
+func serveFile(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rs, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rs = strings.NewReader(string(data))
+	}
+	http.ServeContent(w, r, name, info.ModTime(), rs)
+}
@@ -0,0 +1,69 @@
+// File: internal/web/spa_test.go
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"index.html":                   {Data: []byte("<html>app</html>")},
+		"favicon.ico":                  {Data: []byte("ico")},
+		"static/js/main.3f2a9c1b.js":   {Data: []byte("console.log(1)")},
+		"images/event1.jpg":            {Data: []byte("jpg")},
+		"asset-manifest.json":          {Data: []byte("{}")},
+		"static/css/main.0a1b2c3d.css": {Data: []byte("body{}")},
+	}
+}
+
+func get(h http.Handler, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	return w
+}
+
+func TestSPA_FallbackToIndex(t *testing.T) {
+	h := SPA(testFS())
+	for _, p := range []string{"/", "/events/12", "/reservations", "/index.html"} {
+		w := get(h, "GET", p)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "app") {
+			t.Errorf("%s: expected index.html, got %d %q", p, w.Code, w.Body.String())
+		}
+		if w.Header().Get("Cache-Control") != "no-cache" {
+			t.Errorf("%s: index must not be cached, got %q", p, w.Header().Get("Cache-Control"))
+		}
+	}
+}
+
+func TestSPA_Assets(t *testing.T) {
+	h := SPA(testFS())
+
+	w := get(h, "GET", "/static/js/main.3f2a9c1b.js")
+	if w.Code != http.StatusOK || w.Body.String() != "console.log(1)" {
+		t.Fatalf("unexpected asset response %d %q", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("hashed asset should be cached long, got %q", w.Header().Get("Cache-Control"))
+	}
+
+	w = get(h, "GET", "/images/event1.jpg")
+	if w.Code != http.StatusOK || strings.Contains(w.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("unhashed asset: code=%d cache=%q", w.Code, w.Header().Get("Cache-Control"))
+	}
+}
+
+func TestSPA_NotFound(t *testing.T) {
+	h := SPA(testFS())
+	for _, p := range []string{"/static/js/missing.js", "/api/v1/nope", "/api"} {
+		if w := get(h, "GET", p); w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", p, w.Code)
+		}
+	}
+	if w := get(h, "POST", "/events"); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: expected 405, got %d", w.Code)
+	}
+}